@@ -0,0 +1,48 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ChassisRegistration compares the nodes of the role with the chassis
+// registered in the OVN southbound database
+type ChassisRegistration struct {
+	// Registered is the number of nodes of the role with a matching chassis
+	Registered int32 `json:"registered"`
+	// Unregistered are the nodes of the role without a matching chassis
+	Unregistered []UnregisteredChassis `json:"unregistered,omitempty"`
+	// Stale are the chassis of the naming scheme whose node does not exist
+	// anymore
+	Stale []string `json:"stale,omitempty"`
+	// LastCheckTime is when the southbound database was last queried
+	LastCheckTime metav1.Time `json:"lastCheckTime,omitempty"`
+}
+
+// UnregisteredChassis is a node of the role without a matching chassis
+type UnregisteredChassis struct {
+	// Node name
+	Node string `json:"node"`
+	// Chassis name expected for the node
+	Chassis string `json:"chassis"`
+	// Reason the chassis does not match: Missing, HostnameMismatch or
+	// EncapMismatch
+	Reason string `json:"reason"`
+	// Message with the details
+	Message string `json:"message,omitempty"`
+}
@@ -0,0 +1,36 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Condition defines an observation of the state of an instance
+type Condition struct {
+	// Type of the condition, e.g. Paused
+	Type string `json:"type"`
+	// Status of the condition, one of True, False, Unknown
+	Status corev1.ConditionStatus `json:"status"`
+	// Reason of the last transition in CamelCase
+	Reason string `json:"reason,omitempty"`
+	// Message with details of the last transition
+	Message string `json:"message,omitempty"`
+	// Time of the last status change
+	LastTransitionTime metav1.Time `json:"lastTransitionTime"`
+}
@@ -0,0 +1,50 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	corev1 "k8s.io/api/core/v1"
+)
+
+// NodeCoverage compares the nodes of the role with the nodes running a ready
+// daemon pod
+type NodeCoverage struct {
+	// Expected is the number of nodes of the role
+	Expected int32 `json:"expected"`
+	// Ready is the number of nodes of the role with a ready daemon pod
+	Ready int32 `json:"ready"`
+	// Unhealthy are the nodes of the role without a ready daemon pod
+	Unhealthy []UnhealthyNode `json:"unhealthy,omitempty"`
+}
+
+// UnhealthyNode is a node of the role without a ready daemon pod
+type UnhealthyNode struct {
+	// Node name
+	Node string `json:"node"`
+	// Reason the pod is not ready: NoPod, Tainted, NodeNotReady,
+	// Unschedulable or PodNotReady
+	Reason string `json:"reason"`
+	// Message with the details
+	Message string `json:"message,omitempty"`
+	// PodPhase of the daemon pod on the node
+	PodPhase corev1.PodPhase `json:"podPhase,omitempty"`
+	// Restarts of the containers of the pod
+	Restarts int32 `json:"restarts,omitempty"`
+	// LastRestartReason is why a container of the pod last terminated, like
+	// Error or OOMKilled
+	LastRestartReason string `json:"lastRestartReason,omitempty"`
+}
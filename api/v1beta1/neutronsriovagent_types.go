@@ -30,6 +30,9 @@ type NeutronSriovAgentSpec struct {
 	RabbitTransportURL string `json:"rabbitTransportURL"`
 	// Debug
 	Debug string `json:"debug,omitempty"`
+	// Name of the ConfigMap with the OSP /etc/hosts entries added to the
+	// pods, defaults to common-config
+	CommonConfigMap string `json:"commonConfigMap,omitempty"`
 }
 
 // NeutronSriovAgentStatus defines the observed state of NeutronSriovAgent
@@ -38,6 +41,8 @@ type NeutronSriovAgentStatus struct {
 	Count int32 `json:"count"`
 	// Daemonset hash used to detect changes
 	DaemonsetHash string `json:"daemonsetHash"`
+	// Conditions of the instance
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
@@ -0,0 +1,44 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NodeStatus defines the observed state of the daemon on a node, as reported
+// by its pod
+type NodeStatus struct {
+	// Node name
+	Node string `json:"node"`
+	// Chassis name of the node in the SB DB
+	Chassis string `json:"chassis,omitempty"`
+	// SystemID of the Open vSwitch of the node
+	SystemID string `json:"systemID,omitempty"`
+	// EncapIP is the tunnel endpoint IP of the chassis
+	EncapIP string `json:"encapIP,omitempty"`
+	// Gateway is true if the node acts as gateway chassis
+	Gateway bool `json:"gateway,omitempty"`
+	// OVSVersion is the Open vSwitch version running on the node
+	OVSVersion string `json:"ovsVersion,omitempty"`
+	// OVNVersion is the ovn-controller version running on the node
+	OVNVersion string `json:"ovnVersion,omitempty"`
+	// Ready is true if the daemon pod of the node is ready
+	Ready bool `json:"ready"`
+	// LastTransitionTime is when the pod last changed its ready state
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+}
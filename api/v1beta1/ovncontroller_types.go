@@ -30,14 +30,91 @@ type OVNControllerSpec struct {
 	RoleName string `json:"roleName"`
 	// log level
 	OvnLogLevel string `json:"ovnLogLevel"`
+	// Integration bridge used by ovn-controller, defaults to br-int-osp.
+	// Must match between the OVSNodeOsp and OVNController of a role
+	IntegrationBridge string `json:"integrationBridge,omitempty"`
+	// Chassis name pattern, {hostname} gets replaced with the Kubernetes
+	// node name, which can differ from the kernel hostname, e.g. not be the
+	// FQDN. Defaults to {hostname}-osp. Must match between the OVSNodeOsp
+	// and OVNController of a role
+	// +kubebuilder:validation:Pattern=`\{hostname\}`
+	ChassisNamePattern string `json:"chassisNamePattern,omitempty"`
+	// Upgrade strategy of the daemon pods, the default rolling update restarts
+	// one node at a time without waiting for it to be healthy
+	UpgradeStrategy *UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	// Name of the ConfigMap with the OSP /etc/hosts entries added to the
+	// pods, defaults to common-config. The pods get no entries while it does
+	// not exist.
+	CommonConfigMap string `json:"commonConfigMap,omitempty"`
+	// TunnelCheck enables periodic pings from the encap IP of each node to
+	// the encap IPs of the other nodes of the role, the results are shown in
+	// status.tunnels. The Geneve port itself is not probed as it does not
+	// answer, so a firewall blocking the UDP port 6081 but not ICMP is not
+	// seen by the pings. The BFD state of the Geneve tunnels is reported as
+	// well, it covers that case but only for the tunnels to the gateway
+	// chassis, where ovn-controller enables BFD.
+	TunnelCheck *TunnelCheck `json:"tunnelCheck,omitempty"`
+	// Probes thresholds of the ovn-controller container
+	Probes *OVNControllerProbes `json:"probes,omitempty"`
+}
+
+// OVNControllerProbes configures the probes of the ovn-controller container.
+// Readiness needs the connection to the SB DB and the integration bridge,
+// liveness and startup only ovn-controller to answer, so a restart of the OVS
+// pod does not get ovn-controller killed.
+type OVNControllerProbes struct {
+	// Readiness probe, defaults to a period of 10s, timeout of 5s and 3 failures
+	Readiness *ProbeThresholds `json:"readiness,omitempty"`
+	// Liveness probe, defaults to a period of 30s, timeout of 10s and 5 failures
+	Liveness *ProbeThresholds `json:"liveness,omitempty"`
+	// Startup probe, the other probes only start once it succeeded. Defaults
+	// to a period of 10s, timeout of 10s and 60 failures, leaving 10 minutes
+	// for the initial flow programming
+	Startup *ProbeThresholds `json:"startup,omitempty"`
+}
+
+// ProbeThresholds of a probe, unset fields take the default of the probe
+type ProbeThresholds struct {
+	// PeriodSeconds between the probes
+	// +kubebuilder:validation:Minimum=1
+	PeriodSeconds int32 `json:"periodSeconds,omitempty"`
+	// TimeoutSeconds of a probe
+	// +kubebuilder:validation:Minimum=1
+	TimeoutSeconds int32 `json:"timeoutSeconds,omitempty"`
+	// FailureThreshold is the number of failed probes in a row before the
+	// probe fails
+	// +kubebuilder:validation:Minimum=1
+	FailureThreshold int32 `json:"failureThreshold,omitempty"`
 }
 
 // OVNControllerStatus defines the observed state of OVNController
 type OVNControllerStatus struct {
 	// Count is the number of nodes the daemon is deployed to
 	Count int32 `json:"count"`
+	// Coverage lists the nodes of the role without a ready daemon pod
+	Coverage NodeCoverage `json:"coverage,omitempty"`
+	// Nodes is the state of the daemon on each node running its pod
+	Nodes []NodeStatus `json:"nodes,omitempty"`
+	// Chassis lists the nodes of the role without a matching chassis in the
+	// southbound database, and the chassis left behind by deleted nodes. The
+	// database is read over the tcp and unix remotes of the ovn-connection
+	// ConfigMap only, with ssl remotes the ChassisRegistered condition has
+	// the reason UnsupportedSBConnection.
+	Chassis ChassisRegistration `json:"chassis,omitempty"`
+	// Tunnels is the reachability between the nodes, set if the tunnel check
+	// is enabled
+	Tunnels *TunnelStatus `json:"tunnels,omitempty"`
 	// Daemonset hash used to detect changes
 	DaemonsetHash string `json:"daemonsetHash"`
+	// ManagedNodes are the nodes with a chassis of the daemon, nodes which
+	// left the role stay until their chassis got removed
+	ManagedNodes []string `json:"managedNodes,omitempty"`
+	// Upgrade is the progress of the current upgrade
+	Upgrade UpgradeStatus `json:"upgrade,omitempty"`
+	// Revisions are the last pod templates rolled out, newest first
+	Revisions []DaemonsetRevision `json:"revisions,omitempty"`
+	// Conditions of the instance
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
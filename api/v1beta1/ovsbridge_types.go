@@ -0,0 +1,98 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// OVSBridgeSpec defines the desired state of OVSBridge
+type OVSBridgeSpec struct {
+	// Name of the worker role created for OSP computes, the bridge gets
+	// created by the OVSNodeOsp pods of this role
+	RoleName string `json:"roleName"`
+	// Name of the bridge. Only the oldest OVSBridge of a role declaring a
+	// bridge is applied, the others report it in the BridgeUnique condition
+	BridgeName string `json:"bridgeName"`
+	// Fail mode of the bridge
+	// +kubebuilder:validation:Enum=standalone;secure
+	FailMode string `json:"failMode,omitempty"`
+	// Ports of the bridge. Ports removed from the spec get deleted from the
+	// nodes, ports added out of band are reported as drift but kept
+	Ports []OVSBridgePort `json:"ports,omitempty"`
+}
+
+// OVSBridgePort defines a port of the bridge
+type OVSBridgePort struct {
+	// Name of the port
+	Name string `json:"name"`
+	// Type of the port, system adds an existing interface of the node
+	// +kubebuilder:validation:Enum=system;internal;patch
+	Type string `json:"type,omitempty"`
+	// VLAN tag of an access port
+	// +kubebuilder:validation:Minimum=1
+	// +kubebuilder:validation:Maximum=4094
+	VLANTag *int32 `json:"vlanTag,omitempty"`
+	// Peer port of a patch port
+	Peer string `json:"peer,omitempty"`
+	// IP addresses in CIDR notation of an internal port
+	Addresses []string `json:"addresses,omitempty"`
+}
+
+// OVSBridgeNodeStatus defines the observed state of the bridge on a node
+type OVSBridgeNodeStatus struct {
+	// Node the bridge is on
+	Node string `json:"node"`
+	// InSync is false when the bridge was changed out of band
+	InSync bool `json:"inSync"`
+	// Drift describes the out of band changes found on the node, missing or
+	// changed ports get corrected, undeclared ports are kept
+	Drift string `json:"drift,omitempty"`
+}
+
+// OVSBridgeStatus defines the observed state of OVSBridge
+type OVSBridgeStatus struct {
+	// Nodes is the state of the bridge reported by each node
+	Nodes []OVSBridgeNodeStatus `json:"nodes,omitempty"`
+	// Conditions of the instance
+	Conditions []Condition `json:"conditions,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+// +kubebuilder:subresource:status
+
+// OVSBridge is the Schema for the ovsbridges API
+type OVSBridge struct {
+	metav1.TypeMeta   `json:",inline"`
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+
+	Spec   OVSBridgeSpec   `json:"spec,omitempty"`
+	Status OVSBridgeStatus `json:"status,omitempty"`
+}
+
+// +kubebuilder:object:root=true
+
+// OVSBridgeList contains a list of OVSBridge
+type OVSBridgeList struct {
+	metav1.TypeMeta `json:",inline"`
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []OVSBridge `json:"items"`
+}
+
+func init() {
+	SchemeBuilder.Register(&OVSBridge{}, &OVSBridgeList{})
+}
@@ -17,6 +17,7 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/api/resource"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -37,16 +38,182 @@ type OVSNodeOspSpec struct {
 	Nic string `json:"nic"`
 	// Make the nodes a Network Gateways Node
 	Gateway bool `json:"gateway,omitempty"`
+	// Only the nodes matching the selector become gateway nodes, requires gateway
+	GatewayNodeSelector *metav1.LabelSelector `json:"gatewayNodeSelector,omitempty"`
+	// Priority of the gateway nodes, added to ovn-cms-options as gateway-priority
+	// +kubebuilder:validation:Minimum=0
+	GatewayPriority *int32 `json:"gatewayPriority,omitempty"`
+	// Availability zones of the nodes, added to ovn-cms-options
+	AvailabilityZones []string `json:"availabilityZones,omitempty"`
 	// Bridge Mappings
 	BridgeMappings string `json:"bridgeMappings,omitempty"`
+	// Bonds of the provider bridges, bonds removed from the spec get deleted
+	// from the nodes
+	Bonds []OVSBondSpec `json:"bonds,omitempty"`
+	// Source of the OVS system-id (chassis name). random keeps the previous
+	// behaviour, machine-id and node-uid derive a stable id from the node
+	// +kubebuilder:validation:Enum=random;machine-id;node-uid
+	SystemIDSource string `json:"systemIDSource,omitempty"`
+	// Integration bridge used by ovn-controller, defaults to br-int-osp.
+	// Must match between the OVSNodeOsp and OVNController of a role
+	IntegrationBridge string `json:"integrationBridge,omitempty"`
+	// Chassis name pattern, {hostname} gets replaced with the Kubernetes
+	// node name, which can differ from the kernel hostname, e.g. not be the
+	// FQDN. Defaults to {hostname}-osp. Must match between the OVSNodeOsp
+	// and OVNController of a role
+	// +kubebuilder:validation:Pattern=`\{hostname\}`
+	ChassisNamePattern string `json:"chassisNamePattern,omitempty"`
+	// Run OVS with the DPDK userspace datapath
+	DPDK *OVSDPDKSpec `json:"dpdk,omitempty"`
+	// Enable OVS hardware offload on SmartNIC nodes
+	HWOffload *OVSHWOffloadSpec `json:"hwOffload,omitempty"`
+	// external_ids:ovn-openflow-probe-interval in seconds
+	// +kubebuilder:validation:Minimum=0
+	OpenflowProbeInterval *int32 `json:"openflowProbeInterval,omitempty"`
+	// external_ids:ovn-remote-probe-interval in milliseconds
+	// +kubebuilder:validation:Minimum=0
+	RemoteProbeInterval *int32 `json:"remoteProbeInterval,omitempty"`
+	// external_ids:ovn-monitor-all
+	MonitorAll *bool `json:"monitorAll,omitempty"`
+	// external_ids:ovn-encap-csum
+	EncapCsum *bool `json:"encapCsum,omitempty"`
+	// other_config:max-idle in milliseconds
+	// +kubebuilder:validation:Minimum=0
+	MaxIdle *int32 `json:"maxIdle,omitempty"`
+	// other_config:flow-limit
+	// +kubebuilder:validation:Minimum=0
+	FlowLimit *int32 `json:"flowLimit,omitempty"`
+	// other_config:n-revalidator-threads
+	// +kubebuilder:validation:Minimum=1
+	RevalidatorThreads *int32 `json:"revalidatorThreads,omitempty"`
+	// Additional Open_vSwitch external_ids, the chassis name gets appended
+	// to the keys. Keys managed by the operator can not be set
+	ExternalIDs map[string]string `json:"externalIDs,omitempty"`
+	// Additional Open_vSwitch other_config. Keys managed by the operator
+	// can not be set
+	OtherConfig map[string]string `json:"otherConfig,omitempty"`
+	// Upgrade strategy of the daemon pods, the default rolling update restarts
+	// one node at a time without waiting for it to be healthy
+	UpgradeStrategy *UpgradeStrategy `json:"upgradeStrategy,omitempty"`
+	// Name of the ConfigMap with the OSP /etc/hosts entries added to the
+	// pods, defaults to common-config. The pods get no entries while it does
+	// not exist.
+	CommonConfigMap string `json:"commonConfigMap,omitempty"`
+}
+
+// OVSDPDKSpec defines the OVS-DPDK configuration of the nodes
+type OVSDPDKSpec struct {
+	// other_config:dpdk-socket-mem, e.g. 1024,1024
+	SocketMem string `json:"socketMem,omitempty"`
+	// other_config:pmd-cpu-mask
+	PMDCPUMask string `json:"pmdCPUMask,omitempty"`
+	// other_config:dpdk-lcore-mask
+	LcoreMask string `json:"lcoreMask,omitempty"`
+	// other_config:vhost-sock-dir, relative to the OVS run directory
+	VhostSockDir string `json:"vhostSockDir,omitempty"`
+	// Size of the hugepages used by DPDK
+	// +kubebuilder:validation:Enum="1Gi";"2Mi"
+	HugepageSize string `json:"hugepageSize"`
+	// Amount of hugepages memory requested for the ovs pod, e.g. 4Gi
+	Hugepages resource.Quantity `json:"hugepages"`
+	// Memory requested for the ovs pod, defaults to 1Gi
+	Memory *resource.Quantity `json:"memory,omitempty"`
+	// DPDK ports, bound by PCI address and added to netdev bridges
+	Ports []OVSDPDKPort `json:"ports,omitempty"`
+}
+
+// OVSDPDKPort defines a DPDK port of a netdev bridge
+type OVSDPDKPort struct {
+	// Name of the port
+	Name string `json:"name"`
+	// Bridge the port gets added to, created with datapath_type=netdev
+	Bridge string `json:"bridge"`
+	// PCI address of the NIC, e.g. 0000:03:00.0
+	// +kubebuilder:validation:Pattern=`^[0-9a-fA-F]{4}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`
+	PCIAddress string `json:"pciAddress"`
+}
+
+// OVSBondSpec defines an OVS bond of a provider bridge
+type OVSBondSpec struct {
+	// Name of the bond port
+	Name string `json:"name"`
+	// Bridge the bond gets added to, has to be a bridge of the bridge
+	// mappings, e.g. br-ex. A bond with the NIC as member has to be on the
+	// gateway bridge, the first one of the mappings
+	Bridge string `json:"bridge"`
+	// Member interfaces of the bond
+	// +kubebuilder:validation:MinItems=2
+	Interfaces []string `json:"interfaces"`
+	// Bond mode, balance-tcp requires LACP
+	// +kubebuilder:validation:Enum=active-backup;balance-slb;balance-tcp
+	Mode string `json:"mode"`
+	// LACP mode, defaults to off
+	// +kubebuilder:validation:Enum=off;active;passive
+	LACP string `json:"lacp,omitempty"`
+}
+
+// OVSBondStatus defines the observed state of a bond on a node
+type OVSBondStatus struct {
+	// Node the bond is on
+	Node string `json:"node"`
+	// Name of the bond port
+	Name string `json:"name"`
+	// Link state of each member interface
+	Members map[string]string `json:"members,omitempty"`
+}
+
+// OVSHWOffloadSpec defines the OVS hardware offload configuration of the nodes
+type OVSHWOffloadSpec struct {
+	// Physical functions to switch to switchdev mode, their VF representors
+	// get added to the integration bridge
+	PhysicalFunctions []string `json:"physicalFunctions"`
+}
+
+// SystemIDChange is a change of the system-id of a node
+type SystemIDChange struct {
+	// Node name
+	Node string `json:"node"`
+	// Previous system-id of the node
+	Previous string `json:"previous"`
+	// SystemID the node uses now
+	SystemID string `json:"systemID"`
+	// Time the change was found
+	Time metav1.Time `json:"time"`
 }
 
 // OVSNodeOspStatus defines the observed state of OVSNodeOsp
 type OVSNodeOspStatus struct {
 	// Count is the number of nodes the daemon is deployed to
 	Count int32 `json:"count"`
+	// Coverage lists the nodes of the role without a ready daemon pod
+	Coverage NodeCoverage `json:"coverage,omitempty"`
+	// Nodes is the state of the daemon on each node running its pod
+	Nodes []NodeStatus `json:"nodes,omitempty"`
 	// Daemonset hash used to detect changes
 	DaemonsetHash string `json:"daemonsetHash"`
+	// SystemIDs is the system-id assigned to each node, keyed by node name
+	SystemIDs map[string]string `json:"systemIDs,omitempty"`
+	// SystemIDChanges is the last system-id change of each node, found by the
+	// operator or by the node. The chassis of the previous id is stale.
+	SystemIDChanges []SystemIDChange `json:"systemIDChanges,omitempty"`
+	// Nodes without enough allocatable hugepages for DPDK, they get no pod
+	// while the other nodes get rolled out
+	NodesWithoutHugepages []string `json:"nodesWithoutHugepages,omitempty"`
+	// GatewayNodes are the nodes acting as gateway chassis
+	GatewayNodes []string `json:"gatewayNodes,omitempty"`
+	// Bonds is the member link state of the bonds reported by each node
+	Bonds []OVSBondStatus `json:"bonds,omitempty"`
+	// HWOffload is the hardware offload state reported by each node
+	HWOffload map[string]string `json:"hwOffload,omitempty"`
+	// ManagedNodes are the nodes set up by the daemon, nodes which left the
+	// role stay until they got cleaned up
+	ManagedNodes []string `json:"managedNodes,omitempty"`
+	// Upgrade is the progress of the current upgrade
+	Upgrade UpgradeStatus `json:"upgrade,omitempty"`
+	// Revisions are the last pod templates rolled out, newest first
+	Revisions []DaemonsetRevision `json:"revisions,omitempty"`
+	// Conditions of the instance
+	Conditions []Condition `json:"conditions,omitempty"`
 }
 
 // +kubebuilder:object:root=true
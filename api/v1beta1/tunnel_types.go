@@ -0,0 +1,57 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+// TunnelCheck configures the probes of the tunnel endpoints between the nodes
+type TunnelCheck struct {
+	// IntervalSeconds between the probes of a node, defaults to 60
+	// +kubebuilder:validation:Minimum=10
+	IntervalSeconds int32 `json:"intervalSeconds,omitempty"`
+}
+
+// TunnelStatus is the reachability of the encap IPs between the nodes, as
+// probed by the pods
+type TunnelStatus struct {
+	// Matrix has a row for each node which probed its peers
+	Matrix []TunnelReachability `json:"matrix,omitempty"`
+	// Partitions are the groups of nodes reaching each other, only set if
+	// the nodes do not all reach each other
+	Partitions []TunnelPartition `json:"partitions,omitempty"`
+}
+
+// TunnelReachability is the result of the probes of a node
+type TunnelReachability struct {
+	// Node name
+	Node string `json:"node"`
+	// Peers is the number of nodes probed
+	Peers int32 `json:"peers"`
+	// Reachable is the number of nodes which answered
+	Reachable int32 `json:"reachable"`
+	// Unreachable are the nodes which did not answer
+	Unreachable []string `json:"unreachable,omitempty"`
+	// BFDDown are the nodes whose Geneve tunnel has BFD down. Unlike the
+	// ping, BFD runs through the tunnel and sees a block of the Geneve UDP
+	// port 6081, but ovn-controller only enables it on the tunnels to the
+	// gateway chassis, other tunnels are never reported here.
+	BFDDown []string `json:"bfdDown,omitempty"`
+}
+
+// TunnelPartition is a group of nodes reaching each other
+type TunnelPartition struct {
+	// Nodes of the partition
+	Nodes []string `json:"nodes"`
+}
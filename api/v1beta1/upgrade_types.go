@@ -0,0 +1,100 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1beta1
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// UpgradeStrategy defines how the daemon pods get replaced after a spec change
+type UpgradeStrategy struct {
+	// Type of the upgrade, RollingUpdate leaves the pod replacement to the
+	// daemonset controller, OnDelete lets the operator restart the nodes one
+	// at a time. Defaults to RollingUpdate
+	// +kubebuilder:validation:Enum=RollingUpdate;OnDelete
+	Type string `json:"type,omitempty"`
+	// MaxUnavailable pods during a RollingUpdate, defaults to 1
+	MaxUnavailable *intstr.IntOrString `json:"maxUnavailable,omitempty"`
+	// MinReadySeconds a restarted pod has to be ready for before it counts as
+	// healthy. OnDelete only restarts the next node once all pods are healthy
+	// +kubebuilder:validation:Minimum=0
+	MinReadySeconds int32 `json:"minReadySeconds,omitempty"`
+	// Canary phase of image changes, the new image only rolls out to the
+	// other nodes once it ran fine on the canary nodes
+	Canary *CanarySpec `json:"canary,omitempty"`
+	// MaintenanceWindows outside of which no pods get restarted for a
+	// rollout. Rollouts also skip the nodes annotated with
+	// neutron.openstack.org/upgrade-hold=true
+	MaintenanceWindows []MaintenanceWindow `json:"maintenanceWindows,omitempty"`
+}
+
+// MaintenanceWindow defines when rollouts may restart the daemon pods
+type MaintenanceWindow struct {
+	// Schedule of the window starts in cron format, in UTC, e.g. "0 2 * * 6"
+	// or "0 2 * * SAT"
+	Schedule string `json:"schedule"`
+	// Duration of the window, up to 168h
+	Duration metav1.Duration `json:"duration"`
+}
+
+// CanarySpec defines the canary phase of image changes
+type CanarySpec struct {
+	// NodeSelector of the canary nodes, on top of the role
+	NodeSelector metav1.LabelSelector `json:"nodeSelector"`
+	// Count of canary nodes, defaults to 1
+	// +kubebuilder:validation:Minimum=1
+	Count int32 `json:"count,omitempty"`
+	// SoakSeconds the canary pods have to run without restarts and be ready
+	// at the end of, defaults to 300. Otherwise the previous image gets
+	// rolled back
+	// +kubebuilder:validation:Minimum=0
+	SoakSeconds *int32 `json:"soakSeconds,omitempty"`
+}
+
+// DaemonsetRevision defines a pod template rolled out to the daemon pods
+type DaemonsetRevision struct {
+	// Image of the daemon
+	Image string `json:"image"`
+	// TemplateHash of the pod template, the template is kept in the
+	// ControllerRevision <name>-<templateHash> to roll back to it
+	TemplateHash string `json:"templateHash,omitempty"`
+	// Phase of the rollout
+	// +kubebuilder:validation:Enum=Canary;RollingOut;Complete;RolledBack
+	Phase string `json:"phase"`
+	// Time the rollout started
+	Time metav1.Time `json:"time"`
+}
+
+// UpgradeStatus defines the progress of an upgrade
+type UpgradeStatus struct {
+	// Nodes still running the pod of a previous spec
+	PendingNodes []string `json:"pendingNodes,omitempty"`
+	// Node restarted last by an OnDelete upgrade
+	CurrentNode string `json:"currentNode,omitempty"`
+	// Nodes whose pod did not pass the health gate yet, no further node gets
+	// restarted while the upgrade would exceed the allowed unavailable pods
+	UnhealthyNodes []string `json:"unhealthyNodes,omitempty"`
+	// Nodes of the canary phase
+	CanaryNodes []string `json:"canaryNodes,omitempty"`
+	// Time all the canary nodes ran the new image, the soak time starts
+	CanaryStartTime *metav1.Time `json:"canaryStartTime,omitempty"`
+	// Nodes whose upgrades are on hold
+	HeldNodes []string `json:"heldNodes,omitempty"`
+	// Start of the next maintenance window, set while rollouts wait for it
+	NextMaintenanceWindow *metav1.Time `json:"nextMaintenanceWindow,omitempty"`
+}
@@ -21,16 +21,113 @@ limitations under the License.
 package v1beta1
 
 import (
+	"k8s.io/apimachinery/pkg/apis/meta/v1"
 	runtime "k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/util/intstr"
 )
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *CanarySpec) DeepCopyInto(out *CanarySpec) {
+	*out = *in
+	in.NodeSelector.DeepCopyInto(&out.NodeSelector)
+	if in.SoakSeconds != nil {
+		in, out := &in.SoakSeconds, &out.SoakSeconds
+		*out = new(int32)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new CanarySpec.
+func (in *CanarySpec) DeepCopy() *CanarySpec {
+	if in == nil {
+		return nil
+	}
+	out := new(CanarySpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ChassisRegistration) DeepCopyInto(out *ChassisRegistration) {
+	*out = *in
+	if in.Unregistered != nil {
+		in, out := &in.Unregistered, &out.Unregistered
+		*out = make([]UnregisteredChassis, len(*in))
+		copy(*out, *in)
+	}
+	if in.Stale != nil {
+		in, out := &in.Stale, &out.Stale
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.LastCheckTime.DeepCopyInto(&out.LastCheckTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ChassisRegistration.
+func (in *ChassisRegistration) DeepCopy() *ChassisRegistration {
+	if in == nil {
+		return nil
+	}
+	out := new(ChassisRegistration)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *Condition) DeepCopyInto(out *Condition) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new Condition.
+func (in *Condition) DeepCopy() *Condition {
+	if in == nil {
+		return nil
+	}
+	out := new(Condition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *DaemonsetRevision) DeepCopyInto(out *DaemonsetRevision) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new DaemonsetRevision.
+func (in *DaemonsetRevision) DeepCopy() *DaemonsetRevision {
+	if in == nil {
+		return nil
+	}
+	out := new(DaemonsetRevision)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *MaintenanceWindow) DeepCopyInto(out *MaintenanceWindow) {
+	*out = *in
+	out.Duration = in.Duration
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new MaintenanceWindow.
+func (in *MaintenanceWindow) DeepCopy() *MaintenanceWindow {
+	if in == nil {
+		return nil
+	}
+	out := new(MaintenanceWindow)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NeutronSriovAgent) DeepCopyInto(out *NeutronSriovAgent) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NeutronSriovAgent.
@@ -101,6 +198,13 @@ func (in *NeutronSriovAgentSpec) DeepCopy() *NeutronSriovAgentSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *NeutronSriovAgentStatus) DeepCopyInto(out *NeutronSriovAgentStatus) {
 	*out = *in
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NeutronSriovAgentStatus.
@@ -113,13 +217,49 @@ func (in *NeutronSriovAgentStatus) DeepCopy() *NeutronSriovAgentStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeCoverage) DeepCopyInto(out *NodeCoverage) {
+	*out = *in
+	if in.Unhealthy != nil {
+		in, out := &in.Unhealthy, &out.Unhealthy
+		*out = make([]UnhealthyNode, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeCoverage.
+func (in *NodeCoverage) DeepCopy() *NodeCoverage {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeCoverage)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *NodeStatus) DeepCopyInto(out *NodeStatus) {
+	*out = *in
+	in.LastTransitionTime.DeepCopyInto(&out.LastTransitionTime)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new NodeStatus.
+func (in *NodeStatus) DeepCopy() *NodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(NodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNController) DeepCopyInto(out *OVNController) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNController.
@@ -172,9 +312,54 @@ func (in *OVNControllerList) DeepCopyObject() runtime.Object {
 	return nil
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVNControllerProbes) DeepCopyInto(out *OVNControllerProbes) {
+	*out = *in
+	if in.Readiness != nil {
+		in, out := &in.Readiness, &out.Readiness
+		*out = new(ProbeThresholds)
+		**out = **in
+	}
+	if in.Liveness != nil {
+		in, out := &in.Liveness, &out.Liveness
+		*out = new(ProbeThresholds)
+		**out = **in
+	}
+	if in.Startup != nil {
+		in, out := &in.Startup, &out.Startup
+		*out = new(ProbeThresholds)
+		**out = **in
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerProbes.
+func (in *OVNControllerProbes) DeepCopy() *OVNControllerProbes {
+	if in == nil {
+		return nil
+	}
+	out := new(OVNControllerProbes)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNControllerSpec) DeepCopyInto(out *OVNControllerSpec) {
 	*out = *in
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(UpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.TunnelCheck != nil {
+		in, out := &in.TunnelCheck, &out.TunnelCheck
+		*out = new(TunnelCheck)
+		**out = **in
+	}
+	if in.Probes != nil {
+		in, out := &in.Probes, &out.Probes
+		*out = new(OVNControllerProbes)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerSpec.
@@ -190,6 +375,40 @@ func (in *OVNControllerSpec) DeepCopy() *OVNControllerSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVNControllerStatus) DeepCopyInto(out *OVNControllerStatus) {
 	*out = *in
+	in.Coverage.DeepCopyInto(&out.Coverage)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	in.Chassis.DeepCopyInto(&out.Chassis)
+	if in.Tunnels != nil {
+		in, out := &in.Tunnels, &out.Tunnels
+		*out = new(TunnelStatus)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.ManagedNodes != nil {
+		in, out := &in.ManagedNodes, &out.ManagedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Upgrade.DeepCopyInto(&out.Upgrade)
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]DaemonsetRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVNControllerStatus.
@@ -202,13 +421,264 @@ func (in *OVNControllerStatus) DeepCopy() *OVNControllerStatus {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBondSpec) DeepCopyInto(out *OVSBondSpec) {
+	*out = *in
+	if in.Interfaces != nil {
+		in, out := &in.Interfaces, &out.Interfaces
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBondSpec.
+func (in *OVSBondSpec) DeepCopy() *OVSBondSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBondSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBondStatus) DeepCopyInto(out *OVSBondStatus) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBondStatus.
+func (in *OVSBondStatus) DeepCopy() *OVSBondStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBondStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridge) DeepCopyInto(out *OVSBridge) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridge.
+func (in *OVSBridge) DeepCopy() *OVSBridge {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridge)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OVSBridge) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridgeList) DeepCopyInto(out *OVSBridgeList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]OVSBridge, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridgeList.
+func (in *OVSBridgeList) DeepCopy() *OVSBridgeList {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridgeList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *OVSBridgeList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridgeNodeStatus) DeepCopyInto(out *OVSBridgeNodeStatus) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridgeNodeStatus.
+func (in *OVSBridgeNodeStatus) DeepCopy() *OVSBridgeNodeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridgeNodeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridgePort) DeepCopyInto(out *OVSBridgePort) {
+	*out = *in
+	if in.VLANTag != nil {
+		in, out := &in.VLANTag, &out.VLANTag
+		*out = new(int32)
+		**out = **in
+	}
+	if in.Addresses != nil {
+		in, out := &in.Addresses, &out.Addresses
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridgePort.
+func (in *OVSBridgePort) DeepCopy() *OVSBridgePort {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridgePort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridgeSpec) DeepCopyInto(out *OVSBridgeSpec) {
+	*out = *in
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]OVSBridgePort, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridgeSpec.
+func (in *OVSBridgeSpec) DeepCopy() *OVSBridgeSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridgeSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSBridgeStatus) DeepCopyInto(out *OVSBridgeStatus) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]OVSBridgeNodeStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSBridgeStatus.
+func (in *OVSBridgeStatus) DeepCopy() *OVSBridgeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSBridgeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSDPDKPort) DeepCopyInto(out *OVSDPDKPort) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSDPDKPort.
+func (in *OVSDPDKPort) DeepCopy() *OVSDPDKPort {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSDPDKPort)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSDPDKSpec) DeepCopyInto(out *OVSDPDKSpec) {
+	*out = *in
+	out.Hugepages = in.Hugepages.DeepCopy()
+	if in.Memory != nil {
+		in, out := &in.Memory, &out.Memory
+		x := (*in).DeepCopy()
+		*out = &x
+	}
+	if in.Ports != nil {
+		in, out := &in.Ports, &out.Ports
+		*out = make([]OVSDPDKPort, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSDPDKSpec.
+func (in *OVSDPDKSpec) DeepCopy() *OVSDPDKSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSDPDKSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *OVSHWOffloadSpec) DeepCopyInto(out *OVSHWOffloadSpec) {
+	*out = *in
+	if in.PhysicalFunctions != nil {
+		in, out := &in.PhysicalFunctions, &out.PhysicalFunctions
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSHWOffloadSpec.
+func (in *OVSHWOffloadSpec) DeepCopy() *OVSHWOffloadSpec {
+	if in == nil {
+		return nil
+	}
+	out := new(OVSHWOffloadSpec)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVSNodeOsp) DeepCopyInto(out *OVSNodeOsp) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
-	out.Spec = in.Spec
-	out.Status = in.Status
+	in.Spec.DeepCopyInto(&out.Spec)
+	in.Status.DeepCopyInto(&out.Status)
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSNodeOsp.
@@ -264,6 +734,92 @@ func (in *OVSNodeOspList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVSNodeOspSpec) DeepCopyInto(out *OVSNodeOspSpec) {
 	*out = *in
+	if in.GatewayNodeSelector != nil {
+		in, out := &in.GatewayNodeSelector, &out.GatewayNodeSelector
+		*out = new(v1.LabelSelector)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GatewayPriority != nil {
+		in, out := &in.GatewayPriority, &out.GatewayPriority
+		*out = new(int32)
+		**out = **in
+	}
+	if in.AvailabilityZones != nil {
+		in, out := &in.AvailabilityZones, &out.AvailabilityZones
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bonds != nil {
+		in, out := &in.Bonds, &out.Bonds
+		*out = make([]OVSBondSpec, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.DPDK != nil {
+		in, out := &in.DPDK, &out.DPDK
+		*out = new(OVSDPDKSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.HWOffload != nil {
+		in, out := &in.HWOffload, &out.HWOffload
+		*out = new(OVSHWOffloadSpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.OpenflowProbeInterval != nil {
+		in, out := &in.OpenflowProbeInterval, &out.OpenflowProbeInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RemoteProbeInterval != nil {
+		in, out := &in.RemoteProbeInterval, &out.RemoteProbeInterval
+		*out = new(int32)
+		**out = **in
+	}
+	if in.MonitorAll != nil {
+		in, out := &in.MonitorAll, &out.MonitorAll
+		*out = new(bool)
+		**out = **in
+	}
+	if in.EncapCsum != nil {
+		in, out := &in.EncapCsum, &out.EncapCsum
+		*out = new(bool)
+		**out = **in
+	}
+	if in.MaxIdle != nil {
+		in, out := &in.MaxIdle, &out.MaxIdle
+		*out = new(int32)
+		**out = **in
+	}
+	if in.FlowLimit != nil {
+		in, out := &in.FlowLimit, &out.FlowLimit
+		*out = new(int32)
+		**out = **in
+	}
+	if in.RevalidatorThreads != nil {
+		in, out := &in.RevalidatorThreads, &out.RevalidatorThreads
+		*out = new(int32)
+		**out = **in
+	}
+	if in.ExternalIDs != nil {
+		in, out := &in.ExternalIDs, &out.ExternalIDs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.OtherConfig != nil {
+		in, out := &in.OtherConfig, &out.OtherConfig
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.UpgradeStrategy != nil {
+		in, out := &in.UpgradeStrategy, &out.UpgradeStrategy
+		*out = new(UpgradeStrategy)
+		(*in).DeepCopyInto(*out)
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSNodeOspSpec.
@@ -279,6 +835,72 @@ func (in *OVSNodeOspSpec) DeepCopy() *OVSNodeOspSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *OVSNodeOspStatus) DeepCopyInto(out *OVSNodeOspStatus) {
 	*out = *in
+	in.Coverage.DeepCopyInto(&out.Coverage)
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]NodeStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.SystemIDs != nil {
+		in, out := &in.SystemIDs, &out.SystemIDs
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.SystemIDChanges != nil {
+		in, out := &in.SystemIDChanges, &out.SystemIDChanges
+		*out = make([]SystemIDChange, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.NodesWithoutHugepages != nil {
+		in, out := &in.NodesWithoutHugepages, &out.NodesWithoutHugepages
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.GatewayNodes != nil {
+		in, out := &in.GatewayNodes, &out.GatewayNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.Bonds != nil {
+		in, out := &in.Bonds, &out.Bonds
+		*out = make([]OVSBondStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.HWOffload != nil {
+		in, out := &in.HWOffload, &out.HWOffload
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	if in.ManagedNodes != nil {
+		in, out := &in.ManagedNodes, &out.ManagedNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	in.Upgrade.DeepCopyInto(&out.Upgrade)
+	if in.Revisions != nil {
+		in, out := &in.Revisions, &out.Revisions
+		*out = make([]DaemonsetRevision, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Conditions != nil {
+		in, out := &in.Conditions, &out.Conditions
+		*out = make([]Condition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
 }
 
 // DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new OVSNodeOspStatus.
@@ -290,3 +912,226 @@ func (in *OVSNodeOspStatus) DeepCopy() *OVSNodeOspStatus {
 	in.DeepCopyInto(out)
 	return out
 }
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ProbeThresholds) DeepCopyInto(out *ProbeThresholds) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ProbeThresholds.
+func (in *ProbeThresholds) DeepCopy() *ProbeThresholds {
+	if in == nil {
+		return nil
+	}
+	out := new(ProbeThresholds)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *SystemIDChange) DeepCopyInto(out *SystemIDChange) {
+	*out = *in
+	in.Time.DeepCopyInto(&out.Time)
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new SystemIDChange.
+func (in *SystemIDChange) DeepCopy() *SystemIDChange {
+	if in == nil {
+		return nil
+	}
+	out := new(SystemIDChange)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelCheck) DeepCopyInto(out *TunnelCheck) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelCheck.
+func (in *TunnelCheck) DeepCopy() *TunnelCheck {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelCheck)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelPartition) DeepCopyInto(out *TunnelPartition) {
+	*out = *in
+	if in.Nodes != nil {
+		in, out := &in.Nodes, &out.Nodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelPartition.
+func (in *TunnelPartition) DeepCopy() *TunnelPartition {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelPartition)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelReachability) DeepCopyInto(out *TunnelReachability) {
+	*out = *in
+	if in.Unreachable != nil {
+		in, out := &in.Unreachable, &out.Unreachable
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.BFDDown != nil {
+		in, out := &in.BFDDown, &out.BFDDown
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelReachability.
+func (in *TunnelReachability) DeepCopy() *TunnelReachability {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelReachability)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TunnelStatus) DeepCopyInto(out *TunnelStatus) {
+	*out = *in
+	if in.Matrix != nil {
+		in, out := &in.Matrix, &out.Matrix
+		*out = make([]TunnelReachability, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.Partitions != nil {
+		in, out := &in.Partitions, &out.Partitions
+		*out = make([]TunnelPartition, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TunnelStatus.
+func (in *TunnelStatus) DeepCopy() *TunnelStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TunnelStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnhealthyNode) DeepCopyInto(out *UnhealthyNode) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnhealthyNode.
+func (in *UnhealthyNode) DeepCopy() *UnhealthyNode {
+	if in == nil {
+		return nil
+	}
+	out := new(UnhealthyNode)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UnregisteredChassis) DeepCopyInto(out *UnregisteredChassis) {
+	*out = *in
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UnregisteredChassis.
+func (in *UnregisteredChassis) DeepCopy() *UnregisteredChassis {
+	if in == nil {
+		return nil
+	}
+	out := new(UnregisteredChassis)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStatus) DeepCopyInto(out *UpgradeStatus) {
+	*out = *in
+	if in.PendingNodes != nil {
+		in, out := &in.PendingNodes, &out.PendingNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.UnhealthyNodes != nil {
+		in, out := &in.UnhealthyNodes, &out.UnhealthyNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CanaryNodes != nil {
+		in, out := &in.CanaryNodes, &out.CanaryNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.CanaryStartTime != nil {
+		in, out := &in.CanaryStartTime, &out.CanaryStartTime
+		*out = (*in).DeepCopy()
+	}
+	if in.HeldNodes != nil {
+		in, out := &in.HeldNodes, &out.HeldNodes
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.NextMaintenanceWindow != nil {
+		in, out := &in.NextMaintenanceWindow, &out.NextMaintenanceWindow
+		*out = (*in).DeepCopy()
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStatus.
+func (in *UpgradeStatus) DeepCopy() *UpgradeStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *UpgradeStrategy) DeepCopyInto(out *UpgradeStrategy) {
+	*out = *in
+	if in.MaxUnavailable != nil {
+		in, out := &in.MaxUnavailable, &out.MaxUnavailable
+		*out = new(intstr.IntOrString)
+		**out = **in
+	}
+	if in.Canary != nil {
+		in, out := &in.Canary, &out.Canary
+		*out = new(CanarySpec)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.MaintenanceWindows != nil {
+		in, out := &in.MaintenanceWindows, &out.MaintenanceWindows
+		*out = make([]MaintenanceWindow, len(*in))
+		copy(*out, *in)
+	}
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new UpgradeStrategy.
+func (in *UpgradeStrategy) DeepCopy() *UpgradeStrategy {
+	if in == nil {
+		return nil
+	}
+	out := new(UpgradeStrategy)
+	in.DeepCopyInto(out)
+	return out
+}
@@ -0,0 +1,186 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// cleanupNodes deletes the daemonset of the owner and, once its pods are gone,
+// runs the cleanup job on every node of the role. It returns true when all the
+// cleanup jobs completed and the finalizer can be removed.
+func cleanupNodes(c client.Client, log logr.Logger, scheme *runtime.Scheme, owner operand.Object, roleName string,
+	scriptsConfigMap *corev1.ConfigMap, newJob func(nodeName string) *batchv1.Job) (bool, error) {
+
+	// the daemonset would restore what the cleanup removes
+	ds := &appsv1.DaemonSet{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: owner.GetName(), Namespace: owner.GetNamespace()}, ds)
+	if err != nil && !errors.IsNotFound(err) {
+		return false, err
+	} else if err == nil && ds.DeletionTimestamp.IsZero() {
+		log.Info("Deleting Daemonset before the node cleanup", "Ds.Namespace", ds.Namespace, "Ds.Name", ds.Name)
+		if err := c.Delete(context.TODO(), ds); err != nil && !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	pods := &corev1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{"daemonset": owner.GetName() + "-daemonset"}); err != nil {
+		return false, err
+	}
+	if len(pods.Items) > 0 {
+		log.Info("Waiting for the daemonset pods to terminate", "Pods", len(pods.Items))
+		return false, nil
+	}
+
+	// the scripts of a previous version might not have the cleanup script
+	if err := operand.EnsureConfigMap(c, log, scheme, owner, scriptsConfigMap); err != nil {
+		return false, err
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(roleName))); err != nil {
+		return false, err
+	}
+	completed := true
+	for _, node := range nodes.Items {
+		job := newJob(node.Name)
+		if err := controllerutil.SetControllerReference(owner, job, scheme); err != nil {
+			return false, err
+		}
+		foundJob := &batchv1.Job{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, foundJob)
+		if err != nil && errors.IsNotFound(err) {
+			log.Info("Creating cleanup Job", "Job.Namespace", job.Namespace, "Job.Name", job.Name, "Node", node.Name)
+			if err := c.Create(context.TODO(), job); err != nil {
+				return false, err
+			}
+			completed = false
+		} else if err != nil {
+			return false, err
+		} else if common.IsJobFailed(foundJob) {
+			// removing the finalizer by hand skips the cleanup of the node
+			return false, fmt.Errorf("cleanup job %s failed on node %s, delete the job to retry", foundJob.Name, node.Name)
+		} else if !common.IsJobComplete(foundJob) {
+			completed = false
+		}
+	}
+	return completed, nil
+}
+
+// cleanupRemovedNodes runs the cleanup job of the managed nodes which left the
+// role, either as their role label got removed or as they got deleted. newJob
+// returns the job and a description of what it cleans up, or a nil job if
+// there is nothing to do for the node. It returns the nodes still managed: the
+// nodes of the role and the removed nodes whose cleanup did not finish yet.
+func cleanupRemovedNodes(c client.Client, log logr.Logger, recorder record.EventRecorder, scheme *runtime.Scheme,
+	owner operand.Object, roleName string, managedNodes []string,
+	newJob func(nodeName string, nodeExists bool) (*batchv1.Job, string)) ([]string, error) {
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(roleName))); err != nil {
+		return nil, err
+	}
+	roleNodes := map[string]bool{}
+	for _, node := range nodes.Items {
+		roleNodes[node.Name] = true
+	}
+
+	pods := &corev1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{"daemonset": owner.GetName() + "-daemonset"}); err != nil {
+		return nil, err
+	}
+	podNodes := map[string]bool{}
+	for _, pod := range pods.Items {
+		podNodes[pod.Spec.NodeName] = true
+	}
+
+	stillManaged := []string{}
+	for _, nodeName := range managedNodes {
+		if roleNodes[nodeName] {
+			continue
+		}
+
+		node := &corev1.Node{}
+		err := c.Get(context.TODO(), types.NamespacedName{Name: nodeName}, node)
+		if err != nil && !errors.IsNotFound(err) {
+			return nil, err
+		}
+		nodeExists := err == nil
+
+		job, description := newJob(nodeName, nodeExists)
+		if job == nil {
+			recorder.Eventf(owner, corev1.EventTypeNormal, "NodeRemoved", "Node %s left the role: %s", nodeName, description)
+			continue
+		}
+		// the pod would restore what the cleanup removes
+		if nodeExists && podNodes[nodeName] {
+			log.Info("Waiting for the daemonset pod to leave the node", "Node", nodeName)
+			stillManaged = append(stillManaged, nodeName)
+			continue
+		}
+
+		if err := controllerutil.SetControllerReference(owner, job, scheme); err != nil {
+			return nil, err
+		}
+		foundJob := &batchv1.Job{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: job.Name, Namespace: job.Namespace}, foundJob)
+		if err != nil && errors.IsNotFound(err) {
+			log.Info("Creating cleanup Job", "Job.Namespace", job.Namespace, "Job.Name", job.Name, "Node", nodeName)
+			if err := c.Create(context.TODO(), job); err != nil {
+				return nil, err
+			}
+			stillManaged = append(stillManaged, nodeName)
+		} else if err != nil {
+			return nil, err
+		} else if common.IsJobComplete(foundJob) {
+			recorder.Eventf(owner, corev1.EventTypeNormal, "NodeCleanedUp", "Node %s left the role: %s", nodeName, description)
+			if err := c.Delete(context.TODO(), foundJob, client.PropagationPolicy(metav1.DeletePropagationBackground)); err != nil && !errors.IsNotFound(err) {
+				return nil, err
+			}
+		} else {
+			if common.IsJobFailed(foundJob) {
+				recorder.Eventf(owner, corev1.EventTypeWarning, "NodeCleanupFailed",
+					"Cleanup job %s of node %s failed, delete the job to retry", foundJob.Name, nodeName)
+			}
+			stillManaged = append(stillManaged, nodeName)
+		}
+	}
+
+	for nodeName := range roleNodes {
+		stillManaged = append(stillManaged, nodeName)
+	}
+	sort.Strings(stillManaged)
+	return stillManaged, nil
+}
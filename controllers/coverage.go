@@ -0,0 +1,69 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateCoverage compares the nodes of the role with the nodes running a
+// ready daemon pod, and writes the nodes without one and the number of nodes
+// the daemon is deployed to into the status of the instance
+func updateCoverage(c client.Client, log logr.Logger, owner operand.Object, roleName string,
+	count *int32, coverage *neutronv1beta1.NodeCoverage) error {
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(roleName))); err != nil {
+		return err
+	}
+	pods := &corev1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(owner.GetNamespace()),
+		client.MatchingLabels{"daemonset": owner.GetName() + operand.PodLabelSuffix}); err != nil {
+		return err
+	}
+
+	newCoverage := common.GetNodeCoverage(nodes.Items, pods.Items, common.GetComputeWorkerTolerations(roleName))
+	roleNodes := map[string]bool{}
+	for _, node := range nodes.Items {
+		roleNodes[node.Name] = true
+	}
+	deployed := map[string]bool{}
+	for _, pod := range pods.Items {
+		if roleNodes[pod.Spec.NodeName] {
+			deployed[pod.Spec.NodeName] = true
+		}
+	}
+
+	if *count == int32(len(deployed)) && reflect.DeepEqual(*coverage, newCoverage) {
+		return nil
+	}
+	if !reflect.DeepEqual(coverage.Unhealthy, newCoverage.Unhealthy) {
+		log.Info("Nodes without a ready daemon pod changed", "Expected", newCoverage.Expected, "Ready", newCoverage.Ready,
+			"Unhealthy", newCoverage.Unhealthy)
+	}
+	*count = int32(len(deployed))
+	*coverage = newCoverage
+	return c.Status().Update(context.TODO(), owner)
+}
@@ -0,0 +1,69 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// commonConfigMapField - index of the instances by the name of their common config map
+const commonConfigMapField string = ".spec.commonConfigMap"
+
+// maxReportedHostsLines - invalid hosts lines listed in the event, the log has all
+const maxReportedHostsLines = 10
+
+// getHostAliases returns the host aliases created from the hosts entries of
+// the common config map, and false if the ConfigMap does not exist. Invalid
+// hosts entries are skipped, they get logged and reported with a warning event
+// on the owner.
+func getHostAliases(c client.Client, log logr.Logger, recorder record.EventRecorder, owner operand.Object, name string) ([]corev1.HostAlias, bool, error) {
+	commonConfigMap := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: owner.GetNamespace()}, commonConfigMap)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, false, nil
+		}
+		return nil, false, err
+	}
+
+	hostAliases, invalidLines, err := util.CreateOspHostsEntries(commonConfigMap)
+	if err != nil {
+		return nil, true, err
+	}
+	if len(invalidLines) > 0 {
+		log.Info("Skipped invalid hosts entries", "ConfigMap", name, "Lines", invalidLines)
+		reported := invalidLines
+		if len(reported) > maxReportedHostsLines {
+			reported = append(reported[:maxReportedHostsLines:maxReportedHostsLines],
+				fmt.Sprintf("%d more", len(invalidLines)-maxReportedHostsLines))
+		}
+		recorder.Eventf(owner, corev1.EventTypeWarning, "InvalidHostsEntries", "Skipped invalid entries of the hosts file in ConfigMap %s: %s",
+			name, strings.Join(reported, "; "))
+	}
+	return hostAliases, true, nil
+}
@@ -0,0 +1,58 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+func TestGetHostAliasesReportsInvalidLines(t *testing.T) {
+	commonConfig := &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{Name: "common-config", Namespace: testNamespace},
+		Data: map[string]string{
+			"hosts": "192.168.24.10 controller-0\n192.168.24.11 compute_0\n",
+		},
+	}
+	instance := testOVSNodeOsp()
+	r := newTestOVSNodeOspReconciler(t, instance, commonConfig)
+	recorder := record.NewFakeRecorder(10)
+
+	hostAliases, found, err := getHostAliases(r.Client, r.Log, recorder, instance, "common-config")
+	if err != nil {
+		t.Fatalf("invalid line failed the hosts file: %v", err)
+	}
+	if !found || len(hostAliases) != 1 || hostAliases[0].IP != "192.168.24.10" {
+		t.Errorf("got %+v, found %t, want the valid entry", hostAliases, found)
+	}
+	select {
+	case event := <-recorder.Events:
+		if !strings.Contains(event, "InvalidHostsEntries") || !strings.Contains(event, `line 2: invalid hostname "compute_0"`) {
+			t.Errorf("unexpected event %q", event)
+		}
+	default:
+		t.Error("no event for the invalid line")
+	}
+
+	if _, found, err := getHostAliases(r.Client, r.Log, recorder, instance, "missing"); err != nil || found {
+		t.Errorf("missing ConfigMap: found %t, err %v", found, err)
+	}
+}
@@ -0,0 +1,41 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// updateNamingCondition sets the NamingConsistent condition from the result
+// of the naming scheme validation against the other daemon of the role, so a
+// mismatch shows up on the instance and not only as a reconcile error
+func updateNamingCondition(c client.Client, owner operand.Object, conditions *[]neutronv1beta1.Condition, mismatch error) error {
+	status, reason, message := corev1.ConditionTrue, "Consistent", "integration bridge and chassis name pattern match the other daemon of the role"
+	if mismatch != nil {
+		status, reason, message = corev1.ConditionFalse, "Mismatch", mismatch.Error()
+	}
+	if !common.SetCondition(conditions, common.ConditionNamingConsistent, status, reason, message) {
+		return nil
+	}
+	return c.Status().Update(context.TODO(), owner)
+}
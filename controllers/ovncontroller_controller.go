@@ -20,38 +20,63 @@ import (
 	"context"
 	"fmt"
 	"github.com/go-logr/logr"
-	util "github.com/openstack-k8s-operators/lib-common/pkg/util"
 	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
 	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovncontroller"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
 	"k8s.io/apimachinery/pkg/runtime"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"reflect"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"strings"
 	"time"
 
 	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
 	corev1 "k8s.io/api/core/v1"
 )
 
+const (
+	// chassisCheckInterval - how often the chassis get compared with the SB DB
+	chassisCheckInterval = time.Minute
+	// sbTimeout - total time of reading the chassis from the SB DB, the
+	// connection and the requests included
+	sbTimeout = time.Second * 10
+)
+
 // OVNControllerReconciler reconciles a OVNController object
 type OVNControllerReconciler struct {
-	Client client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Client   client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=neutron.openstack.org,resources=ovncontrollers,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=neutron.openstack.org,resources=ovncontrollers/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;create;update;delete;
-// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete;
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;create;update;delete;
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;delete;
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;delete;
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;delete;
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
 
 // Reconcile reconcile keystone API requests
 func (r *OVNControllerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -73,104 +98,333 @@ func (r *OVNControllerReconciler) Reconcile(req ctrl.Request) (ctrl.Result, erro
 		return ctrl.Result{}, err
 	}
 
-	// ScriptsConfigMap
-	scriptsConfigMap := ovncontroller.ScriptsConfigMap(instance, instance.Name+"-scripts")
-	if err := controllerutil.SetControllerReference(instance, scriptsConfigMap, r.Scheme); err != nil {
+	// Remove the chassis of the nodes before the instance goes away, even
+	// while paused as the finalizer would block the deletion otherwise
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(instance)
+	}
+
+	operands := &operand.Reconciler{Client: r.Client, Log: r.Log, Scheme: r.Scheme, Recorder: r.Recorder}
+	status := operand.Status{Conditions: &instance.Status.Conditions, DaemonsetHash: &instance.Status.DaemonsetHash}
+
+	// Paused instances only get their status updated
+	paused, err := operands.Paused(instance, status)
+	if err != nil {
 		return ctrl.Result{}, err
 	}
-	// Check if this ScriptsConfigMap already exists
-	foundScriptsConfigMap := &corev1.ConfigMap{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: scriptsConfigMap.Name, Namespace: scriptsConfigMap.Namespace}, foundScriptsConfigMap)
-	if err != nil && errors.IsNotFound(err) {
-		r.Log.Info("Creating a new ScriptsConfigMap", "ScriptsConfigMap.Namespace", scriptsConfigMap.Namespace, "Job.Name", scriptsConfigMap.Name)
-		err = r.Client.Create(context.TODO(), scriptsConfigMap)
-		if err != nil {
+	if paused {
+		r.Log.Info("Reconcile paused", "Annotation", common.PausedAnnotation)
+		return r.updatePausedStatus(instance)
+	}
+
+	if !common.HasFinalizer(instance, common.ChassisCleanupFinalizer) {
+		controllerutil.AddFinalizer(instance, common.ChassisCleanupFinalizer)
+		if err := r.Client.Update(context.TODO(), instance); err != nil {
 			return ctrl.Result{}, err
 		}
-	} else if !reflect.DeepEqual(scriptsConfigMap.Data, foundScriptsConfigMap.Data) {
-		r.Log.Info("Updating ScriptsConfigMap")
-		scriptsConfigMap.Data = foundScriptsConfigMap.Data
 	}
 
-	scriptsConfigMapHash, err := util.ObjectHash(scriptsConfigMap.Data)
-	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error calculating configuration hash: %v", err)
+	// The OVSNodeOsps of the same role have to use the same naming scheme,
+	// they also know the gateway nodes
+	ovsNodes := &neutronv1beta1.OVSNodeOspList{}
+	if err := r.Client.List(context.TODO(), ovsNodes, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	var gatewayNodes []string
+	var namingErr error
+	for _, ovsNode := range ovsNodes.Items {
+		if ovsNode.Spec.RoleName != instance.Spec.RoleName {
+			continue
+		}
+		gatewayNodes = append(gatewayNodes, ovsNode.Status.GatewayNodes...)
+		if err := common.ValidateNaming(ovsNode.Spec.IntegrationBridge, ovsNode.Spec.ChassisNamePattern,
+			instance.Spec.IntegrationBridge, instance.Spec.ChassisNamePattern); err != nil {
+			r.Log.Error(err, "Naming scheme does not match OVSNodeOsp", "OVSNodeOsp.Name", ovsNode.Name)
+			namingErr = fmt.Errorf("OVSNodeOsp %s: %v", ovsNode.Name, err)
+			break
+		}
+	}
+	if err := updateNamingCondition(r.Client, instance, &instance.Status.Conditions, namingErr); err != nil {
+		return ctrl.Result{}, err
+	}
+	if namingErr != nil {
+		return ctrl.Result{}, namingErr
 	}
-	r.Log.Info("ScriptsConfigMapHash: ", "Data Hash:", scriptsConfigMapHash)
 
-	// TemplatesConfigMap
-	templatesConfigMap := ovncontroller.TemplatesConfigMap(instance, instance.Name+"-templates")
-	if err := controllerutil.SetControllerReference(instance, templatesConfigMap, r.Scheme); err != nil {
+	// Nodes which left the role
+	if err := r.reconcileRemovedNodes(instance); err != nil {
 		return ctrl.Result{}, err
 	}
-	// Check if this TemplatesConfigMap already exists
-	foundTemplatesConfigMap := &corev1.ConfigMap{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: templatesConfigMap.Name, Namespace: templatesConfigMap.Namespace}, foundTemplatesConfigMap)
-	if err != nil && errors.IsNotFound(err) {
-		r.Log.Info("Creating a new TemplatesConfigMap", "TemplatesConfigMap.Namespace", templatesConfigMap.Namespace, "Job.Name", templatesConfigMap.Name)
-		err = r.Client.Create(context.TODO(), templatesConfigMap)
-		if err != nil {
-			return ctrl.Result{}, err
-		}
-	} else if !reflect.DeepEqual(templatesConfigMap.Data, foundTemplatesConfigMap.Data) {
-		r.Log.Info("Updating TemplatesConfigMap")
-		templatesConfigMap.Data = foundTemplatesConfigMap.Data
+
+	// Allow the pods to report node information
+	if err := reconcileReportRBAC(r.Client, r.Log, r.Scheme, instance, instance.Spec.ServiceAccount); err != nil {
+		return ctrl.Result{}, err
 	}
 
-	templatesConfigMapHash, err := util.ObjectHash(templatesConfigMap.Data)
+	// Additional host entries added to the /etc/hosts file of the containers
+	hostAliases, commonConfigFound, err := getHostAliases(r.Client, r.Log, r.Recorder, instance, common.GetCommonConfigMap(instance.Spec.CommonConfigMap))
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error calculating configuration hash: %v", err)
+		r.Log.Error(err, "Failed to create host entries", "ConfigMap", common.GetCommonConfigMap(instance.Spec.CommonConfigMap))
+		return ctrl.Result{}, err
+	}
+	if !commonConfigFound {
+		r.Log.Info("No common config map, not adding host entries", "ConfigMap", common.GetCommonConfigMap(instance.Spec.CommonConfigMap))
 	}
-	r.Log.Info("TemplatesConfigMapHash: ", "Data Hash:", templatesConfigMapHash)
 
-	// Define a new Daemonset object
-	ds := newDaemonsetOVNController(instance, instance.Name, templatesConfigMapHash, scriptsConfigMapHash)
-	dsHash, err := util.ObjectHash(ds)
+	// Define a new Daemonset object, with the last complete image if the
+	// canary of the spec image failed
+	rollout, err := newRollout(r.Client, r.Log, r.Recorder, r.Scheme, instance, instance.Spec.UpgradeStrategy, instance.Spec.RoleName,
+		instance.Spec.OvnControllerImage, chassisCanaryCheck(instance), &instance.Status.Revisions, &instance.Status.Upgrade)
 	if err != nil {
-		return ctrl.Result{}, fmt.Errorf("error calculating configuration hash: %v", err)
+		return ctrl.Result{}, err
+	}
+	dsInstance := instance.DeepCopy()
+	dsInstance.Spec.OvnControllerImage = rollout.image
+	ds := ovncontroller.DaemonSet(dsInstance, instance.Name, hostAliases)
+	if err := rollout.prepare(ds); err != nil {
+		return ctrl.Result{}, err
 	}
-	r.Log.Info("DaemonsetHash: ", "Daemonset Hash:", dsHash)
 
-	// Set OVNController instance as the owner and controller
-	if err := controllerutil.SetControllerReference(instance, ds, r.Scheme); err != nil {
+	// Encap IPs probed by the tunnel check, the pods pick up changes without a restart
+	result, err := operands.Reconcile(instance, status,
+		ovncontroller.ScriptsConfigMap(instance, instance.Name+"-scripts"),
+		ovncontroller.TemplatesConfigMap(instance, instance.Name+"-templates"),
+		operand.InPlace(ovncontroller.TunnelPeersConfigMap(instance, instance.Name+"-tunnel-peers")),
+		ds)
+	if err != nil || result.DaemonSet == nil {
+		return result.Result, err
+	}
+	if err := updateCoverage(r.Client, r.Log, instance, instance.Spec.RoleName, &instance.Status.Count, &instance.Status.Coverage); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.updateNodeReports(instance, gatewayNodes); err != nil {
 		return ctrl.Result{}, err
 	}
+	if err := r.reconcileChassis(instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	if result.Changed {
+		return ctrl.Result{RequeueAfter: time.Second}, nil
+	}
 
-	// Check if this Daemonset already exists
-	found := &appsv1.DaemonSet{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: ds.Name, Namespace: ds.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
-		r.Log.Info("Creating a new Daemonset", "Ds.Namespace", ds.Namespace, "Ds.Name", ds.Name)
-		err = r.Client.Create(context.TODO(), ds)
-		if err != nil {
-			return ctrl.Result{}, err
+	requeue, err := rollout.reconcile(result.DaemonSet, result.TemplateHash)
+	if err != nil || requeue.RequeueAfter > 0 {
+		return requeue, err
+	}
+	// only requeue to check the chassis again
+	return ctrl.Result{RequeueAfter: chassisCheckInterval}, nil
+}
+
+// updatePausedStatus keeps collecting the coverage, the node reports and the
+// chassis registration of a paused instance, none of which changes objects
+func (r *OVNControllerReconciler) updatePausedStatus(instance *neutronv1beta1.OVNController) (ctrl.Result, error) {
+	ovsNodes := &neutronv1beta1.OVSNodeOspList{}
+	if err := r.Client.List(context.TODO(), ovsNodes, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	var gatewayNodes []string
+	for _, ovsNode := range ovsNodes.Items {
+		if ovsNode.Spec.RoleName == instance.Spec.RoleName {
+			gatewayNodes = append(gatewayNodes, ovsNode.Status.GatewayNodes...)
 		}
+	}
 
-		// Daemonset created successfully - don't requeue
-		return ctrl.Result{}, nil
-	} else if err != nil {
+	if err := updateCoverage(r.Client, r.Log, instance, instance.Spec.RoleName, &instance.Status.Count, &instance.Status.Coverage); err != nil {
 		return ctrl.Result{}, err
-	} else {
-		if instance.Status.DaemonsetHash != dsHash {
-			r.Log.Info("Daemonset Updated")
-			found.Spec = ds.Spec
-			err = r.Client.Update(context.TODO(), found)
-			if err != nil {
-				return ctrl.Result{}, err
+	}
+	if err := r.updateNodeReports(instance, gatewayNodes); err != nil {
+		return ctrl.Result{}, err
+	}
+	if err := r.reconcileChassis(instance); err != nil {
+		return ctrl.Result{}, err
+	}
+	return ctrl.Result{RequeueAfter: chassisCheckInterval}, nil
+}
+
+// reconcileChassis compares the nodes of the role with the chassis registered
+// in the SB DB, at most once per chassisCheckInterval
+func (r *OVNControllerReconciler) reconcileChassis(instance *neutronv1beta1.OVNController) error {
+	lastCheck := instance.Status.Chassis.LastCheckTime
+	if !lastCheck.IsZero() && time.Since(lastCheck.Time) < chassisCheckInterval {
+		return nil
+	}
+
+	registration := instance.Status.Chassis
+	status, reason, message, err := r.checkChassis(instance, &registration)
+	if err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(instance.Status.Chassis.Unregistered, registration.Unregistered) ||
+		!reflect.DeepEqual(instance.Status.Chassis.Stale, registration.Stale) {
+		r.Log.Info("Chassis registration changed", "Registered", registration.Registered,
+			"Unregistered", registration.Unregistered, "Stale", registration.Stale)
+	}
+	registration.LastCheckTime = metav1.Now()
+	instance.Status.Chassis = registration
+	common.SetCondition(&instance.Status.Conditions, common.ConditionChassisRegistered, status, reason, message)
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// chassisCanaryCheck fails the canary nodes without a matching chassis in the
+// SB DB, it waits for a chassis check after the soak started. Without a SB DB
+// to read, e.g. with ssl remotes, only the pod readiness counts.
+func chassisCanaryCheck(instance *neutronv1beta1.OVNController) canaryCheck {
+	return func(nodeName string, since time.Time) (string, bool) {
+		condition := common.GetCondition(instance.Status.Conditions, common.ConditionChassisRegistered)
+		if condition == nil || condition.Reason == "UnsupportedSBConnection" || condition.Reason == "NoSBConnection" {
+			return "", false
+		}
+		if condition.Status == corev1.ConditionUnknown || instance.Status.Chassis.LastCheckTime.Time.Before(since) {
+			return "", true
+		}
+		for _, unregistered := range instance.Status.Chassis.Unregistered {
+			if unregistered.Node == nodeName {
+				return fmt.Sprintf("chassis %s %s", unregistered.Chassis, unregistered.Reason), false
 			}
-			r.setDaemonsetHash(instance, dsHash)
-			return ctrl.Result{RequeueAfter: time.Second}, err
+		}
+		return "", false
+	}
+}
+
+// checkChassis queries the SB DB and updates the registration, the previous
+// registration is kept if the SB DB can not be queried
+func (r *OVNControllerReconciler) checkChassis(instance *neutronv1beta1.OVNController,
+	registration *neutronv1beta1.ChassisRegistration) (corev1.ConditionStatus, string, string, error) {
+
+	connection := &corev1.ConfigMap{}
+	err := r.Client.Get(context.TODO(), types.NamespacedName{Name: "ovn-connection", Namespace: instance.Namespace}, connection)
+	if err != nil && !errors.IsNotFound(err) {
+		return "", "", "", err
+	}
+	if errors.IsNotFound(err) || connection.Data["SBConnection"] == "" {
+		return corev1.ConditionUnknown, "NoSBConnection", "no SBConnection in the ovn-connection ConfigMap", nil
+	}
+	chassis, err := ovncontroller.GetChassis(connection.Data["SBConnection"], sbTimeout)
+	if _, ok := err.(*ovsdb.UnsupportedRemoteError); ok {
+		return corev1.ConditionUnknown, "UnsupportedSBConnection", err.Error(), nil
+	}
+	if err != nil {
+		r.Log.Error(err, "Unable to read the chassis from the SB DB")
+		return corev1.ConditionUnknown, "SBUnreachable", err.Error(), nil
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes); err != nil {
+		return "", "", "", err
+	}
+	roleSelector := labels.SelectorFromSet(common.GetComputeWorkerNodeSelector(instance.Spec.RoleName))
+	var roleNodes []string
+	existingNodes := map[string]bool{}
+	for _, node := range nodes.Items {
+		existingNodes[node.Name] = true
+		if roleSelector.Matches(labels.Set(node.Labels)) {
+			roleNodes = append(roleNodes, node.Name)
+		}
+	}
+	encapIPs := map[string]string{}
+	for _, node := range instance.Status.Nodes {
+		encapIPs[node.Node] = node.EncapIP
+	}
+
+	*registration = ovncontroller.GetChassisRegistration(instance, roleNodes, encapIPs, existingNodes, chassis)
+	switch {
+	case len(registration.Unregistered) > 0:
+		return corev1.ConditionFalse, "Unregistered",
+			fmt.Sprintf("%d of %d nodes without a matching chassis", len(registration.Unregistered), len(roleNodes)), nil
+	case len(registration.Stale) > 0:
+		return corev1.ConditionFalse, "Stale", fmt.Sprintf("stale chassis %v", registration.Stale), nil
+	}
+	return corev1.ConditionTrue, "Registered", fmt.Sprintf("%d nodes registered", registration.Registered), nil
+}
+
+// updateNodeReports collects the reports written by the pods into the status
+func (r *OVNControllerReconciler) updateNodeReports(instance *neutronv1beta1.OVNController, gatewayNodes []string) error {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), pods, client.InNamespace(instance.Namespace),
+		client.MatchingLabels(map[string]string{"daemonset": instance.Name + "-daemonset"})); err != nil {
+		return err
+	}
+
+	reports, err := getNodeReports(r.Client, r.Log, instance, pods.Items)
+	if err != nil {
+		return err
+	}
+	nodes := common.GetNodeStatus(pods.Items, reports, gatewayNodes)
+	var tunnels *neutronv1beta1.TunnelStatus
+	conditionChanged := false
+	if ovncontroller.GetTunnelCheckInterval(instance) > 0 {
+		tunnels = ovncontroller.GetTunnelStatus(nodes, reports)
+		status, reason, message := getTunnelCondition(tunnels)
+		conditionChanged = common.SetCondition(&instance.Status.Conditions, common.ConditionTunnelDegraded, status, reason, message)
+		if conditionChanged && status == corev1.ConditionTrue {
+			r.Log.Info("Tunnels degraded", "Reason", reason, "Message", message)
+		}
+	} else {
+		conditionChanged = common.RemoveCondition(&instance.Status.Conditions, common.ConditionTunnelDegraded)
+	}
+
+	if !reflect.DeepEqual(instance.Status.Nodes, nodes) || !reflect.DeepEqual(instance.Status.Tunnels, tunnels) || conditionChanged {
+		instance.Status.Nodes = nodes
+		instance.Status.Tunnels = tunnels
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return err
 		}
 	}
+	return nil
+}
 
-	// Daemonset already exists - don't requeue
-	r.Log.Info("Skip reconcile: Daemonset already exists", "Ds.Namespace", found.Namespace, "Ds.Name", found.Name)
-	return ctrl.Result{}, nil
+// getTunnelCondition returns the TunnelDegraded condition of the reachability
+// matrix, degraded if any node can not reach another one by ping or has BFD
+// down on the tunnel to it
+func getTunnelCondition(tunnels *neutronv1beta1.TunnelStatus) (corev1.ConditionStatus, string, string) {
+	if len(tunnels.Matrix) == 0 {
+		return corev1.ConditionUnknown, "NoResults", "no node reported tunnel probes yet"
+	}
+	if len(tunnels.Partitions) > 0 {
+		groups := []string{}
+		for _, partition := range tunnels.Partitions {
+			groups = append(groups, "["+strings.Join(partition.Nodes, " ")+"]")
+		}
+		return corev1.ConditionTrue, "Partitioned", "nodes partitioned into " + strings.Join(groups, " ")
+	}
+	unreachable := 0
+	bfdDown := 0
+	for _, row := range tunnels.Matrix {
+		unreachable += len(row.Unreachable)
+		bfdDown += len(row.BFDDown)
+	}
+	if unreachable > 0 {
+		return corev1.ConditionTrue, "PeersUnreachable", fmt.Sprintf("%d probes of %d nodes failed", unreachable, len(tunnels.Matrix))
+	}
+	if bfdDown > 0 {
+		return corev1.ConditionTrue, "BFDDown", fmt.Sprintf("%d tunnels of %d nodes have BFD down although the pings pass, "+
+			"check the Geneve UDP port 6081 is not blocked", bfdDown, len(tunnels.Matrix))
+	}
+	return corev1.ConditionFalse, "Reachable", fmt.Sprintf("%d nodes reach each other by ping, a block of the Geneve UDP port 6081 "+
+		"is only seen on the tunnels with BFD", len(tunnels.Matrix))
 }
 
-func (r *OVNControllerReconciler) setDaemonsetHash(instance *neutronv1beta1.OVNController, hashStr string) error {
-	if hashStr != instance.Status.DaemonsetHash {
-		instance.Status.DaemonsetHash = hashStr
+// reconcileRemovedNodes removes the chassis of the nodes which left the role
+// or got deleted from the SB DB
+func (r *OVNControllerReconciler) reconcileRemovedNodes(instance *neutronv1beta1.OVNController) error {
+	cmName := instance.Name
+	managedNodes, err := cleanupRemovedNodes(r.Client, r.Log, r.Recorder, r.Scheme, instance, instance.Spec.RoleName,
+		instance.Status.ManagedNodes,
+		func(nodeName string, nodeExists bool) (*batchv1.Job, string) {
+			chassisName := common.GetChassisName(instance.Spec.ChassisNamePattern, nodeName)
+			if !nodeExists {
+				return ovncontroller.CleanupJob(instance, cmName, nodeName, false),
+					fmt.Sprintf("node deleted, removed chassis %s", chassisName)
+			}
+			return ovncontroller.CleanupJob(instance, cmName, nodeName, true),
+				fmt.Sprintf("removed chassis %s and the integration bridge", chassisName)
+		})
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(instance.Status.ManagedNodes, managedNodes) {
+		instance.Status.ManagedNodes = managedNodes
 		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
 			return err
 		}
@@ -178,111 +432,125 @@ func (r *OVNControllerReconciler) setDaemonsetHash(instance *neutronv1beta1.OVNC
 	return nil
 }
 
-func newDaemonsetOVNController(cr *neutronv1beta1.OVNController, cmName string, templatesConfigHash string, scriptsConfigHash string) *appsv1.DaemonSet {
-	var trueVar = true
-
-	daemonSet := appsv1.DaemonSet{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "DaemonSet",
-			APIVersion: "apps/v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cmName,
-			Namespace: cr.Namespace,
-		},
-		Spec: appsv1.DaemonSetSpec{
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"daemonset": cr.Name + "-daemonset"},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"daemonset": cr.Name + "-daemonset"},
-				},
-				Spec: corev1.PodSpec{
-					NodeSelector:       common.GetComputeWorkerNodeSelector(cr.Spec.RoleName),
-					HostNetwork:        true,
-					HostPID:            true,
-					DNSPolicy:          "ClusterFirstWithHostNet",
-					Containers:         []corev1.Container{},
-					Tolerations:        []corev1.Toleration{},
-					ServiceAccountName: cr.Spec.ServiceAccount,
-					PriorityClassName:  "system-node-critical",
-				},
-			},
-		},
-	}
-
-	// add compute worker nodes tolerations
-	for _, toleration := range common.GetComputeWorkerTolerations(cr.Spec.RoleName) {
-		daemonSet.Spec.Template.Spec.Tolerations = append(daemonSet.Spec.Template.Spec.Tolerations, toleration)
-	}
-
-	containerSpec := corev1.Container{
-		Name:  "ovn-controller",
-		Image: cr.Spec.OvnControllerImage,
-		Command: []string{
-			"bash", "-c", "/usr/local/sbin/ovn.sh",
-		},
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: &trueVar,
-		},
-		Env: []corev1.EnvVar{
-			{
-				Name:  "TEMPLATES_CONFIG_HASH",
-				Value: templatesConfigHash,
-			},
-			{
-				Name:  "OVN_LOG_LEVEL",
-				Value: cr.Spec.OvnLogLevel,
-			},
-			{
-				Name:  "SCRIPTS_CONFIG_HASH",
-				Value: scriptsConfigHash,
-			},
-			{
-				Name:  "K8S_NODE",
-				Value: cmName,
-			},
-			{
-				Name: "HOSTNAME",
-				ValueFrom: &corev1.EnvVarSource{
-					FieldRef: &corev1.ObjectFieldSelector{
-						FieldPath: "spec.nodeName",
-					},
-				},
-			},
-		},
-		VolumeMounts: []corev1.VolumeMount{},
-	}
-	// add common VolumeMounts
-	for _, volMount := range common.GetVolumeMounts() {
-		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
-	}
-	// add ovncontroller specific VolumeMounts
-	for _, volMount := range ovncontroller.GetVolumeMounts(cmName) {
-		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
-	}
-
-	daemonSet.Spec.Template.Spec.Containers = append(daemonSet.Spec.Template.Spec.Containers, containerSpec)
-
-	// Volume config
-	// add common Volumes
-	for _, volConfig := range common.GetVolumes(cmName) {
-		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
-	}
-	// add ovncontroller Volumes
-	for _, volConfig := range ovncontroller.GetVolumes(cmName) {
-		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
-	}
-
-	return &daemonSet
+// nodeToOVNController maps a Node to the OVNController instances of its compute role
+func (r *OVNControllerReconciler) nodeToOVNController(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	instances := &neutronv1beta1.OVNControllerList{}
+	if err := r.Client.List(context.TODO(), instances); err != nil {
+		r.Log.Error(err, "Unable to list OVNController instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		for label := range common.GetComputeWorkerNodeSelector(instance.Spec.RoleName) {
+			if _, ok := o.Meta.GetLabels()[label]; ok {
+				result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+			}
+		}
+	}
+	return result
+}
+
+// ovsNodeToOVNController maps an OVSNodeOsp to the OVNController instances of
+// its role, which show its gateway nodes
+func (r *OVNControllerReconciler) ovsNodeToOVNController(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	ovsNode, ok := o.Object.(*neutronv1beta1.OVSNodeOsp)
+	if !ok {
+		return result
+	}
+	instances := &neutronv1beta1.OVNControllerList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list OVNController instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		if instance.Spec.RoleName == ovsNode.Spec.RoleName {
+			result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+		}
+	}
+	return result
+}
+
+// reconcileDelete removes the chassis of the nodes from the SB DB, then
+// releases the instance
+func (r *OVNControllerReconciler) reconcileDelete(instance *neutronv1beta1.OVNController) (ctrl.Result, error) {
+	if !common.HasFinalizer(instance, common.ChassisCleanupFinalizer) {
+		return ctrl.Result{}, nil
+	}
+
+	cmName := instance.Name
+	completed, err := cleanupNodes(r.Client, r.Log, r.Scheme, instance, instance.Spec.RoleName,
+		ovncontroller.ScriptsConfigMap(instance, cmName+"-scripts"),
+		func(nodeName string) *batchv1.Job {
+			return ovncontroller.CleanupJob(instance, cmName, nodeName, true)
+		})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !completed {
+		return ctrl.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
+	r.Log.Info("Chassis removed, removing finalizer")
+	controllerutil.RemoveFinalizer(instance, common.ChassisCleanupFinalizer)
+	return ctrl.Result{}, r.Client.Update(context.TODO(), instance)
+}
+
+// commonConfigToOVNController maps a ConfigMap to the OVNController instances using it as
+// common config map
+func (r *OVNControllerReconciler) commonConfigToOVNController(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	instances := &neutronv1beta1.OVNControllerList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(o.Meta.GetNamespace()),
+		client.MatchingFields{commonConfigMapField: o.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "Unable to list OVNController instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+	}
+	return result
 }
 
 // SetupWithManager x
 func (r *OVNControllerReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &neutronv1beta1.OVNController{}, commonConfigMapField, func(o runtime.Object) []string {
+		return []string{common.GetCommonConfigMap(o.(*neutronv1beta1.OVNController).Spec.CommonConfigMap)}
+	}); err != nil {
+		return err
+	}
+
+	if err := operand.IndexReferences(mgr.GetFieldIndexer(), "OVNController"); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&neutronv1beta1.OVNController{}).
 		Owns(&appsv1.DaemonSet{}).
 		Owns(&corev1.ConfigMap{}).
+		Owns(&batchv1.Job{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Watches(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.nodeToOVNController),
+		}, builder.WithPredicates(operand.NodeChanged)).
+		Watches(&source.Kind{Type: &neutronv1beta1.OVSNodeOsp{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.ovsNodeToOVNController),
+		}).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(operand.PodToOwner),
+		}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: operand.ReferenceToOwner(mgr.GetClient(), "OVNController"),
+		}, builder.WithPredicates(operand.DataChanged)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.commonConfigToOVNController),
+		}, builder.WithPredicates(operand.DataChanged)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: operand.ReferenceToOwner(mgr.GetClient(), "OVNController"),
+		}, builder.WithPredicates(operand.DataChanged)).
 		Complete(r)
 }
@@ -0,0 +1,100 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"strings"
+	"testing"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestGetTunnelCondition(t *testing.T) {
+	tests := []struct {
+		name    string
+		tunnels *neutronv1beta1.TunnelStatus
+		status  corev1.ConditionStatus
+		reason  string
+		message string
+	}{
+		{
+			name:    "no results",
+			tunnels: &neutronv1beta1.TunnelStatus{},
+			status:  corev1.ConditionUnknown,
+			reason:  "NoResults",
+		},
+		{
+			name: "reachable",
+			tunnels: &neutronv1beta1.TunnelStatus{Matrix: []neutronv1beta1.TunnelReachability{
+				{Node: "worker-0", Peers: 1, Reachable: 1},
+				{Node: "worker-1", Peers: 1, Reachable: 1},
+			}},
+			status: corev1.ConditionFalse,
+			reason: "Reachable",
+			// the pings do not prove the Geneve port passes
+			message: "UDP port 6081",
+		},
+		{
+			name: "unreachable",
+			tunnels: &neutronv1beta1.TunnelStatus{Matrix: []neutronv1beta1.TunnelReachability{
+				{Node: "worker-0", Peers: 1, Unreachable: []string{"worker-1"}},
+				{Node: "worker-1", Peers: 1, Reachable: 1, BFDDown: []string{"worker-0"}},
+			}},
+			status:  corev1.ConditionTrue,
+			reason:  "PeersUnreachable",
+			message: "1 probes of 2 nodes failed",
+		},
+		{
+			name: "bfd down",
+			tunnels: &neutronv1beta1.TunnelStatus{Matrix: []neutronv1beta1.TunnelReachability{
+				{Node: "worker-0", Peers: 1, Reachable: 1, BFDDown: []string{"worker-1"}},
+				{Node: "worker-1", Peers: 1, Reachable: 1},
+			}},
+			status:  corev1.ConditionTrue,
+			reason:  "BFDDown",
+			message: "UDP port 6081",
+		},
+		{
+			name: "partitioned",
+			tunnels: &neutronv1beta1.TunnelStatus{
+				Matrix: []neutronv1beta1.TunnelReachability{
+					{Node: "worker-0", Peers: 1, Reachable: 1, BFDDown: []string{"worker-1"}},
+					{Node: "worker-1", Peers: 1, Reachable: 1},
+				},
+				Partitions: []neutronv1beta1.TunnelPartition{
+					{Nodes: []string{"worker-0"}},
+					{Nodes: []string{"worker-1"}},
+				},
+			},
+			status:  corev1.ConditionTrue,
+			reason:  "Partitioned",
+			message: "[worker-0] [worker-1]",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status, reason, message := getTunnelCondition(tt.tunnels)
+			if status != tt.status || reason != tt.reason {
+				t.Errorf("got %s/%s, want %s/%s", status, reason, tt.status, tt.reason)
+			}
+			if !strings.Contains(message, tt.message) {
+				t.Errorf("message %q does not contain %q", message, tt.message)
+			}
+		})
+	}
+}
@@ -0,0 +1,359 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsbridge"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+)
+
+// OVSBridgeReconciler reconciles a OVSBridge object
+type OVSBridgeReconciler struct {
+	Client   client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// +kubebuilder:rbac:groups=neutron.openstack.org,resources=ovsbridges,verbs=get;list;watch;create;update;patch;delete
+// +kubebuilder:rbac:groups=neutron.openstack.org,resources=ovsbridges/status,verbs=get;update;patch
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
+
+// Reconcile publishes the bridge to the OVSNodeOsp pods of its role, which
+// create it on every node, and collects the state they report
+func (r *OVSBridgeReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
+	_ = context.Background()
+	_ = r.Log.WithValues("ovsbridge", req.NamespacedName)
+	r.Log.Info("Reconciling OVSBridge")
+
+	// Fetch the OVSBridge instance
+	instance := &neutronv1beta1.OVSBridge{}
+	err := r.Client.Get(context.TODO(), req.NamespacedName, instance)
+	if err != nil {
+		if errors.IsNotFound(err) {
+			// Request object not found, could have been deleted after reconcile request.
+			// Remove it from the bridges ConfigMap of its role, the pods
+			// remove the bridge from the nodes.
+			return ctrl.Result{}, r.pruneConfigMaps(req.Namespace)
+		}
+		// Error reading the object - requeue the request.
+		return ctrl.Result{}, err
+	}
+
+	// An invalid spec does not get better by retrying, it is reported in
+	// the SpecValid condition until the spec changes
+	validationErr := ovsbridge.Validate(instance)
+	status, reason, message := corev1.ConditionTrue, "Valid", "the ports of the bridge are valid"
+	if validationErr != nil {
+		r.Log.Error(validationErr, "Invalid OVSBridge", "Instance.Namespace", instance.Namespace, "Instance.Name", instance.Name)
+		status, reason, message = corev1.ConditionFalse, "Invalid", validationErr.Error()
+	}
+	if common.SetCondition(&instance.Status.Conditions, common.ConditionSpecValid, status, reason, message) {
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if validationErr != nil {
+		return ctrl.Result{}, nil
+	}
+
+	// Paused instances only get their status updated
+	resumed := operand.Resumed(instance, instance.Status.Conditions)
+	operands := &operand.Reconciler{Client: r.Client, Log: r.Log, Scheme: r.Scheme, Recorder: r.Recorder}
+	paused, err := operands.Paused(instance, operand.Status{Conditions: &instance.Status.Conditions})
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Only the oldest OVSBridge of the role declaring the bridge gets rendered
+	instances := &neutronv1beta1.OVSBridgeList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	duplicates, keeps := ovsbridge.GetDuplicates(instance, instances.Items)
+	if err := r.updateUniqueCondition(instance, duplicates, keeps); err != nil {
+		return ctrl.Result{}, err
+	}
+
+	// Add the bridge to the bridges ConfigMap of its role
+	configMap := ovsbridge.ConfigMap(instance.Namespace, instance.Spec.RoleName)
+	foundConfigMap := &corev1.ConfigMap{}
+	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, foundConfigMap)
+	if err != nil && !errors.IsNotFound(err) {
+		return ctrl.Result{}, err
+	}
+	if resumed {
+		// only the entry of this bridge belongs to the instance
+		drifted := []string{}
+		if rendered, ok := foundConfigMap.Data[instance.Name]; keeps && (!ok || rendered != ovsbridge.Render(instance)) {
+			drifted = append(drifted, "ConfigMap "+configMap.Name)
+		}
+		operand.ReportResume(r.Recorder, instance, &instance.Status.Conditions, drifted)
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	if paused {
+		r.Log.Info("Reconcile paused, not updating the bridges ConfigMap", "Annotation", common.PausedAnnotation)
+	} else if !keeps {
+		r.Log.Info("Bridge declared by an older OVSBridge, not rendering it", "Bridge", instance.Spec.BridgeName, "Duplicates", duplicates)
+	} else if err != nil && errors.IsNotFound(err) {
+		configMap.Data[instance.Name] = ovsbridge.Render(instance)
+		if err := controllerutil.SetOwnerReference(instance, configMap, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		r.Log.Info("Creating a new ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		if err := r.Client.Create(context.TODO(), configMap); err != nil {
+			return ctrl.Result{}, err
+		}
+	} else {
+		updated := foundConfigMap.DeepCopy()
+		if updated.Data == nil {
+			updated.Data = map[string]string{}
+		}
+		updated.Data[instance.Name] = ovsbridge.Render(instance)
+		// the ConfigMap is shared, every OVSBridge of the role is an owner
+		if err := controllerutil.SetOwnerReference(instance, updated, r.Scheme); err != nil {
+			return ctrl.Result{}, err
+		}
+		if !reflect.DeepEqual(updated, foundConfigMap) {
+			// the merge patch only sets the entry of this bridge. It replaces
+			// the owners as a whole though, so a change of them is made
+			// conditional on the resource version.
+			original := foundConfigMap.DeepCopy()
+			if !reflect.DeepEqual(updated.OwnerReferences, foundConfigMap.OwnerReferences) {
+				original.ResourceVersion = ""
+			}
+			r.Log.Info("Updating ConfigMap", "ConfigMap.Namespace", updated.Namespace, "ConfigMap.Name", updated.Name)
+			if err := r.Client.Patch(context.TODO(), updated, client.MergeFrom(original)); err != nil {
+				return ctrl.Result{}, err
+			}
+		}
+	}
+
+	// The role of the bridge might have changed
+	if !paused {
+		if err := r.pruneConfigMaps(instance.Namespace); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	// Collect the state reported by the OVSNodeOsp pods of the role
+	ovsNodes := &neutronv1beta1.OVSNodeOspList{}
+	if err := r.Client.List(context.TODO(), ovsNodes, client.InNamespace(instance.Namespace)); err != nil {
+		return ctrl.Result{}, err
+	}
+	reports := map[string]map[string]string{}
+	for _, ovsNode := range ovsNodes.Items {
+		if ovsNode.Spec.RoleName != instance.Spec.RoleName {
+			continue
+		}
+		pods := &corev1.PodList{}
+		if err := r.Client.List(context.TODO(), pods, client.InNamespace(instance.Namespace),
+			client.MatchingLabels(map[string]string{"daemonset": ovsNode.Name + operand.PodLabelSuffix})); err != nil {
+			return ctrl.Result{}, err
+		}
+		// the OVSNodeOsp controller owns the report ConfigMap and prunes it
+		reportConfigMap := &corev1.ConfigMap{}
+		err := r.Client.Get(context.TODO(), types.NamespacedName{Name: common.ReportConfigMapName(ovsNode.Name), Namespace: ovsNode.Namespace}, reportConfigMap)
+		if err != nil && !errors.IsNotFound(err) {
+			return ctrl.Result{}, err
+		}
+		for node, report := range common.GetNodeReports(pods.Items, reportConfigMap) {
+			reports[node] = report
+		}
+	}
+
+	nodes := ovsbridge.GetNodeStatus(instance, reports)
+	for _, node := range nodes {
+		if !node.InSync {
+			r.Log.Info("Bridge changed out of band", "Node", node.Node, "Bridge", instance.Spec.BridgeName, "Drift", node.Drift)
+		}
+	}
+	if !reflect.DeepEqual(instance.Status.Nodes, nodes) {
+		instance.Status.Nodes = nodes
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+
+	return ctrl.Result{}, nil
+}
+
+// updateUniqueCondition reports the other OVSBridges of the role declaring
+// the same bridge in the BridgeUnique condition
+func (r *OVSBridgeReconciler) updateUniqueCondition(instance *neutronv1beta1.OVSBridge, duplicates []string, keeps bool) error {
+	status, reason := corev1.ConditionTrue, "Unique"
+	message := fmt.Sprintf("no other OVSBridge of role %s declares bridge %s", instance.Spec.RoleName, instance.Spec.BridgeName)
+	if len(duplicates) > 0 {
+		status, reason = corev1.ConditionFalse, "Duplicate"
+		message = fmt.Sprintf("bridge %s is also declared by OVSBridge %s, ", instance.Spec.BridgeName, strings.Join(duplicates, ", "))
+		if keeps {
+			message += "only this one is applied as the oldest"
+		} else {
+			message += "this one is not applied, the oldest one is"
+		}
+	}
+	if !common.SetCondition(&instance.Status.Conditions, common.ConditionBridgeUnique, status, reason, message) {
+		return nil
+	}
+	if len(duplicates) > 0 {
+		r.Recorder.Event(instance, corev1.EventTypeWarning, "DuplicateBridge", message)
+	}
+	return r.Client.Status().Update(context.TODO(), instance)
+}
+
+// pruneConfigMaps removes the bridges of deleted OVSBridges, OVSBridges
+// which moved to another role, and duplicates of an older OVSBridge from the
+// bridges ConfigMaps of the namespace
+func (r *OVSBridgeReconciler) pruneConfigMaps(namespace string) error {
+	configMaps := &corev1.ConfigMapList{}
+	if err := r.Client.List(context.TODO(), configMaps, client.InNamespace(namespace), client.HasLabels{ovsbridge.RoleLabel}); err != nil {
+		return err
+	}
+	instances := &neutronv1beta1.OVSBridgeList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(namespace)); err != nil {
+		return err
+	}
+	roles := map[string]string{}
+	for i := range instances.Items {
+		instance := &instances.Items[i]
+		if _, keeps := ovsbridge.GetDuplicates(instance, instances.Items); keeps {
+			roles[instance.Name] = instance.Spec.RoleName
+		}
+	}
+
+	for i := range configMaps.Items {
+		configMap := &configMaps.Items[i]
+		original := configMap.DeepCopy()
+		for name := range configMap.Data {
+			if roles[name] != configMap.Labels[ovsbridge.RoleLabel] {
+				delete(configMap.Data, name)
+			}
+		}
+		if reflect.DeepEqual(original.Data, configMap.Data) {
+			continue
+		}
+		r.Log.Info("Pruning ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		// the merge patch only removes the pruned entries, the other
+		// OVSBridges of the role keep writing theirs
+		if err := r.Client.Patch(context.TODO(), configMap, client.MergeFrom(original)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// podToOVSBridges maps a pod of an OVSNodeOsp daemonset to the OVSBridges of its role
+func (r *OVSBridgeReconciler) podToOVSBridges(o handler.MapObject) []reconcile.Request {
+	daemonset, ok := o.Meta.GetLabels()["daemonset"]
+	if !ok || !strings.HasSuffix(daemonset, operand.PodLabelSuffix) {
+		return []reconcile.Request{}
+	}
+	return r.ovsNodeToOVSBridges(o.Meta.GetNamespace(), strings.TrimSuffix(daemonset, operand.PodLabelSuffix))
+}
+
+// duplicatesOfOVSBridge maps an OVSBridge to the other OVSBridges of its role
+// declaring the same bridge, whose BridgeUnique condition depends on it
+func (r *OVSBridgeReconciler) duplicatesOfOVSBridge(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	bridge, ok := o.Object.(*neutronv1beta1.OVSBridge)
+	if !ok {
+		return result
+	}
+	instances := &neutronv1beta1.OVSBridgeList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list OVSBridge instances")
+		return result
+	}
+	duplicates, _ := ovsbridge.GetDuplicates(bridge, instances.Items)
+	for _, name := range duplicates {
+		result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: o.Meta.GetNamespace()}})
+	}
+	return result
+}
+
+// reportsToOVSBridges maps the report ConfigMap of an OVSNodeOsp to the OVSBridges of its role
+func (r *OVSBridgeReconciler) reportsToOVSBridges(o handler.MapObject) []reconcile.Request {
+	name := strings.TrimSuffix(o.Meta.GetName(), common.ReportConfigMapName(""))
+	if name == o.Meta.GetName() {
+		return []reconcile.Request{}
+	}
+	return r.ovsNodeToOVSBridges(o.Meta.GetNamespace(), name)
+}
+
+// ovsNodeToOVSBridges returns the OVSBridges of the role of an OVSNodeOsp
+func (r *OVSBridgeReconciler) ovsNodeToOVSBridges(namespace string, name string) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	ovsNode := &neutronv1beta1.OVSNodeOsp{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, ovsNode); err != nil {
+		return result
+	}
+
+	instances := &neutronv1beta1.OVSBridgeList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(namespace)); err != nil {
+		r.Log.Error(err, "Unable to list OVSBridge instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		if instance.Spec.RoleName == ovsNode.Spec.RoleName {
+			result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+		}
+	}
+	return result
+}
+
+// SetupWithManager x
+func (r *OVSBridgeReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	return ctrl.NewControllerManagedBy(mgr).
+		For(&neutronv1beta1.OVSBridge{}).
+		Watches(&source.Kind{Type: &neutronv1beta1.OVSBridge{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.duplicatesOfOVSBridge),
+		}).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.podToOVSBridges),
+		}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.reportsToOVSBridges),
+		}, builder.WithPredicates(operand.DataChanged)).
+		Complete(r)
+}
@@ -0,0 +1,147 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsbridge"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+func testOVSBridge(name string, bridgeName string, created time.Time) *neutronv1beta1.OVSBridge {
+	return &neutronv1beta1.OVSBridge{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: neutronv1beta1.GroupVersion.String(),
+			Kind:       "OVSBridge",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:              name,
+			Namespace:         testNamespace,
+			CreationTimestamp: metav1.NewTime(created),
+		},
+		Spec: neutronv1beta1.OVSBridgeSpec{
+			RoleName:   "worker-osp",
+			BridgeName: bridgeName,
+		},
+	}
+}
+
+func newTestOVSBridgeReconciler(t *testing.T, objs ...runtime.Object) *OVSBridgeReconciler {
+	t.Helper()
+	if err := neutronv1beta1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &OVSBridgeReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		Log:      ctrl.Log.WithName("controllers").WithName("OVSBridge"),
+		Scheme:   scheme.Scheme,
+		Recorder: record.NewFakeRecorder(100),
+	}
+}
+
+func reconcileOVSBridge(t *testing.T, r *OVSBridgeReconciler, name string) {
+	t.Helper()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: testNamespace}}
+	if _, err := r.Reconcile(req); err != nil {
+		t.Fatalf("reconcile of %s failed: %v", name, err)
+	}
+}
+
+func getBridges(t *testing.T, r *OVSBridgeReconciler) map[string]string {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: ovsbridge.ConfigMapName("worker-osp"), Namespace: testNamespace}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	return configMap.Data
+}
+
+func getUniqueCondition(t *testing.T, r *OVSBridgeReconciler, name string) corev1.ConditionStatus {
+	t.Helper()
+	instance := &neutronv1beta1.OVSBridge{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, instance); err != nil {
+		t.Fatal(err)
+	}
+	condition := common.GetCondition(instance.Status.Conditions, common.ConditionBridgeUnique)
+	if condition == nil {
+		return corev1.ConditionUnknown
+	}
+	return condition.Status
+}
+
+func TestOVSBridgeDuplicate(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	older := testOVSBridge("ex", "br-ex", now.Add(-time.Hour))
+	newer := testOVSBridge("ex-copy", "br-ex", now)
+	tenant := testOVSBridge("tenant", "br-tenant", now)
+	r := newTestOVSBridgeReconciler(t, older, newer, tenant)
+	for _, name := range []string{"ex", "ex-copy", "tenant"} {
+		reconcileOVSBridge(t, r, name)
+	}
+
+	bridges := getBridges(t, r)
+	if _, ok := bridges["ex"]; !ok {
+		t.Error("the oldest OVSBridge of the bridge is not rendered")
+	}
+	if _, ok := bridges["ex-copy"]; ok {
+		t.Error("the duplicate OVSBridge got rendered")
+	}
+	if _, ok := bridges["tenant"]; !ok {
+		t.Error("the unrelated OVSBridge is not rendered")
+	}
+	for _, name := range []string{"ex", "ex-copy"} {
+		if status := getUniqueCondition(t, r, name); status != corev1.ConditionFalse {
+			t.Errorf("BridgeUnique of %s = %s, want False", name, status)
+		}
+	}
+	if status := getUniqueCondition(t, r, "tenant"); status != corev1.ConditionTrue {
+		t.Errorf("BridgeUnique of tenant = %s, want True", status)
+	}
+
+	// once the older one is gone, the duplicate takes over the bridge and
+	// the pruning leaves the other entries alone
+	if err := r.Client.Delete(context.TODO(), older); err != nil {
+		t.Fatal(err)
+	}
+	reconcileOVSBridge(t, r, "ex")
+	reconcileOVSBridge(t, r, "ex-copy")
+	bridges = getBridges(t, r)
+	if _, ok := bridges["ex"]; ok {
+		t.Error("the deleted OVSBridge did not get pruned")
+	}
+	if _, ok := bridges["ex-copy"]; !ok {
+		t.Error("the remaining OVSBridge of the bridge is not rendered")
+	}
+	if _, ok := bridges["tenant"]; !ok {
+		t.Error("the pruning removed the entry of another OVSBridge")
+	}
+	if status := getUniqueCondition(t, r, "ex-copy"); status != corev1.ConditionTrue {
+		t.Errorf("BridgeUnique of ex-copy = %s, want True", status)
+	}
+}
@@ -19,21 +19,29 @@ package controllers
 import (
 	"context"
 	"fmt"
-	"github.com/openstack-k8s-operators/lib-common/pkg/util"
 	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
 	appsv1 "k8s.io/api/apps/v1"
+	batchv1 "k8s.io/api/batch/v1"
 	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
 	"k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
 	"reflect"
 	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
 	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+	"sigs.k8s.io/controller-runtime/pkg/source"
+	"sort"
+	"strings"
 	"time"
 
 	"github.com/go-logr/logr"
 	"k8s.io/apimachinery/pkg/runtime"
 	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/builder"
 	"sigs.k8s.io/controller-runtime/pkg/client"
 
 	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
@@ -43,17 +51,24 @@ import (
 // OVSNodeOspReconciler reconciles a OVSNodeOsp object
 type OVSNodeOspReconciler struct {
 	client.Client
-	Log    logr.Logger
-	Scheme *runtime.Scheme
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
 }
 
 // +kubebuilder:rbac:groups=neutron.openstack.org,resources=ovsnodeosps,verbs=get;list;watch;create;update;patch;delete
 // +kubebuilder:rbac:groups=neutron.openstack.org,resources=ovsnodeosps/status,verbs=get;update;patch
-// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;create;update;delete;
-// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=core,resources=secrets,verbs=get;list;watch;create;update;delete;
+// +kubebuilder:rbac:groups=core,resources=configmaps,verbs=get;list;watch;create;update;patch;delete;
+// +kubebuilder:rbac:groups=core,resources=nodes,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=core,resources=events,verbs=create;patch;
+// +kubebuilder:rbac:groups=core,resources=pods,verbs=get;list;watch;
+// +kubebuilder:rbac:groups=rbac.authorization.k8s.io,resources=roles;rolebindings,verbs=get;list;create;update;delete;
 // +kubebuilder:rbac:groups=core,resources=services,verbs=get;list;create;update;delete;
-// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=batch,resources=jobs,verbs=get;list;watch;create;update;delete;
 // +kubebuilder:rbac:groups=apps,resources=deployments,verbs=get;list;create;update;delete;
+// +kubebuilder:rbac:groups=apps,resources=daemonsets,verbs=get;list;watch;create;update;delete;
+// +kubebuilder:rbac:groups=apps,resources=controllerrevisions,verbs=get;list;watch;create;delete;
 
 // Reconcile reconcile keystone API requests
 func (r *OVSNodeOspReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error) {
@@ -74,104 +89,374 @@ func (r *OVSNodeOspReconciler) Reconcile(req ctrl.Request) (ctrl.Result, error)
 		return reconcile.Result{}, err
 	}
 
-	// ScriptsConfigMap
-	scriptsConfigMap := ovsnodeosp.ScriptsConfigMap(instance, instance.Name+"-scripts")
-	if err := controllerutil.SetControllerReference(instance, scriptsConfigMap, r.Scheme); err != nil {
+	// Clean up the nodes before the instance goes away, even while paused as
+	// the finalizer would block the deletion otherwise
+	if !instance.DeletionTimestamp.IsZero() {
+		return r.reconcileDelete(instance)
+	}
+
+	operands := &operand.Reconciler{Client: r.Client, Log: r.Log, Scheme: r.Scheme, Recorder: r.Recorder}
+	status := operand.Status{Conditions: &instance.Status.Conditions, DaemonsetHash: &instance.Status.DaemonsetHash}
+
+	// Paused instances only get their status updated
+	paused, err := operands.Paused(instance, status)
+	if err != nil {
 		return reconcile.Result{}, err
 	}
-	// Check if this ScriptsConfigMap already exists
-	foundScriptsConfigMap := &corev1.ConfigMap{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: scriptsConfigMap.Name, Namespace: scriptsConfigMap.Namespace}, foundScriptsConfigMap)
-	if err != nil && errors.IsNotFound(err) {
-		r.Log.Info("Creating a new ScriptsConfigMap", "ScriptsConfigMap.Namespace", scriptsConfigMap.Namespace, "Job.Name", scriptsConfigMap.Name)
-		err = r.Client.Create(context.TODO(), scriptsConfigMap)
-		if err != nil {
+	if paused {
+		r.Log.Info("Reconcile paused", "Annotation", common.PausedAnnotation)
+		if err := updateCoverage(r.Client, r.Log, instance, instance.Spec.RoleName, &instance.Status.Count, &instance.Status.Coverage); err != nil {
 			return reconcile.Result{}, err
 		}
-	} else if !reflect.DeepEqual(scriptsConfigMap.Data, foundScriptsConfigMap.Data) {
-		r.Log.Info("Updating ScriptsConfigMap")
-		scriptsConfigMap.Data = foundScriptsConfigMap.Data
+		return reconcile.Result{}, r.updateNodeReports(instance)
 	}
 
-	scriptsConfigMapHash, err := util.ObjectHash(scriptsConfigMap.Data)
-	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("error calculating configuration hash: %v", err)
+	if !common.HasFinalizer(instance, common.ChassisCleanupFinalizer) {
+		controllerutil.AddFinalizer(instance, common.ChassisCleanupFinalizer)
+		if err := r.Client.Update(context.TODO(), instance); err != nil {
+			return reconcile.Result{}, err
+		}
 	}
-	r.Log.Info("ScriptsConfigMapHash: ", "Data Hash:", scriptsConfigMapHash)
 
-	// TemplatesConfigMap
-	templatesConfigMap := ovsnodeosp.TemplatesConfigMap(instance, instance.Name+"-templates")
-	if err := controllerutil.SetControllerReference(instance, templatesConfigMap, r.Scheme); err != nil {
+	// The OVNControllers of the same role have to use the same naming scheme
+	ovnControllers := &neutronv1beta1.OVNControllerList{}
+	if err := r.Client.List(context.TODO(), ovnControllers, client.InNamespace(instance.Namespace)); err != nil {
 		return reconcile.Result{}, err
 	}
-	// Check if this TemplatesConfigMap already exists
-	foundTemplatesConfigMap := &corev1.ConfigMap{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: templatesConfigMap.Name, Namespace: templatesConfigMap.Namespace}, foundTemplatesConfigMap)
-	if err != nil && errors.IsNotFound(err) {
-		r.Log.Info("Creating a new TemplatesConfigMap", "TemplatesConfigMap.Namespace", templatesConfigMap.Namespace, "Job.Name", templatesConfigMap.Name)
-		err = r.Client.Create(context.TODO(), templatesConfigMap)
-		if err != nil {
+	var namingErr error
+	for _, ovnController := range ovnControllers.Items {
+		if ovnController.Spec.RoleName != instance.Spec.RoleName {
+			continue
+		}
+		if err := common.ValidateNaming(instance.Spec.IntegrationBridge, instance.Spec.ChassisNamePattern,
+			ovnController.Spec.IntegrationBridge, ovnController.Spec.ChassisNamePattern); err != nil {
+			r.Log.Error(err, "Naming scheme does not match OVNController", "OVNController.Name", ovnController.Name)
+			namingErr = fmt.Errorf("OVNController %s: %v", ovnController.Name, err)
+			break
+		}
+	}
+	if err := updateNamingCondition(r.Client, instance, &instance.Status.Conditions, namingErr); err != nil {
+		return reconcile.Result{}, err
+	}
+	if namingErr != nil {
+		return reconcile.Result{}, namingErr
+	}
+
+	if err := ovsnodeosp.ValidateOVSConfig(instance); err != nil {
+		r.Log.Error(err, "Invalid OVS configuration")
+		return reconcile.Result{}, err
+	}
+	if err := ovsnodeosp.ValidateBonds(instance); err != nil {
+		r.Log.Error(err, "Invalid bond configuration")
+		return reconcile.Result{}, err
+	}
+
+	// SystemIDsConfigMap
+	if ovsnodeosp.GetSystemIDSource(instance) != ovsnodeosp.SystemIDSourceRandom {
+		if err := r.reconcileSystemIDs(instance); err != nil {
 			return reconcile.Result{}, err
 		}
-	} else if !reflect.DeepEqual(templatesConfigMap.Data, foundTemplatesConfigMap.Data) {
-		r.Log.Info("Updating TemplatesConfigMap")
-		templatesConfigMap.Data = foundTemplatesConfigMap.Data
 	}
 
-	templatesConfigMapHash, err := util.ObjectHash(templatesConfigMap.Data)
+	// GatewaysConfigMap
+	if err := r.reconcileGateways(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Nodes which left the role
+	if err := r.reconcileRemovedNodes(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// DPDK needs hugepages, the nodes without get no pod
+	if err := r.reconcileHugepages(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Allow the pods to report node information
+	if err := reconcileReportRBAC(r.Client, r.Log, r.Scheme, instance, instance.Spec.ServiceAccount); err != nil {
+		return reconcile.Result{}, err
+	}
+	if err := r.updateNodeReports(instance); err != nil {
+		return reconcile.Result{}, err
+	}
+
+	// Additional host entries added to the /etc/hosts file of the containers
+	hostAliases, commonConfigFound, err := getHostAliases(r.Client, r.Log, r.Recorder, instance, common.GetCommonConfigMap(instance.Spec.CommonConfigMap))
 	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("error calculating configuration hash: %v", err)
+		r.Log.Error(err, "Failed to create host entries", "ConfigMap", common.GetCommonConfigMap(instance.Spec.CommonConfigMap))
+		return reconcile.Result{}, err
+	}
+	if !commonConfigFound {
+		r.Log.Info("No common config map, not adding host entries", "ConfigMap", common.GetCommonConfigMap(instance.Spec.CommonConfigMap))
 	}
-	r.Log.Info("TemplatesConfigMapHash: ", "Data Hash:", templatesConfigMapHash)
 
-	// Define a new Daemonset object
-	ds := ovsNodeDaemonset(instance, instance.Name, templatesConfigMapHash, scriptsConfigMapHash)
-	dsHash, err := util.ObjectHash(ds)
+	// Define a new Daemonset object, with the last complete image if the
+	// canary of the spec image failed
+	rollout, err := newRollout(r.Client, r.Log, r.Recorder, r.Scheme, instance, instance.Spec.UpgradeStrategy, instance.Spec.RoleName,
+		instance.Spec.OvsNodeOspImage, nil, &instance.Status.Revisions, &instance.Status.Upgrade)
 	if err != nil {
-		return reconcile.Result{}, fmt.Errorf("error calculating configuration hash: %v", err)
+		return reconcile.Result{}, err
+	}
+	dsInstance := instance.DeepCopy()
+	dsInstance.Spec.OvsNodeOspImage = rollout.image
+	ds := ovsnodeosp.DaemonSet(dsInstance, instance.Name, hostAliases)
+	if err := rollout.prepare(ds); err != nil {
+		return reconcile.Result{}, err
 	}
-	r.Log.Info("DaemonsetHash: ", "Daemonset Hash:", dsHash)
 
-	// Set OVSNodeOsp instance as the owner and controller
-	if err := controllerutil.SetControllerReference(instance, ds, r.Scheme); err != nil {
+	// ovsnode.sh applies the OVSConfigConfigMap in place
+	result, err := operands.Reconcile(instance, status,
+		ovsnodeosp.ScriptsConfigMap(instance, instance.Name+"-scripts"),
+		ovsnodeosp.TemplatesConfigMap(instance, instance.Name+"-templates"),
+		operand.InPlace(ovsnodeosp.OVSConfigConfigMap(instance, instance.Name+"-ovs-config")),
+		ds)
+	if err != nil || result.DaemonSet == nil {
+		return result.Result, err
+	}
+	if err := updateCoverage(r.Client, r.Log, instance, instance.Spec.RoleName, &instance.Status.Count, &instance.Status.Coverage); err != nil {
 		return reconcile.Result{}, err
 	}
+	if result.Changed {
+		return reconcile.Result{RequeueAfter: time.Second}, nil
+	}
+	return rollout.reconcile(result.DaemonSet, result.TemplateHash)
+}
+
+// reconcileSystemIDs publishes the system-id of every compute node in the
+// <name>-system-ids ConfigMap and reports ids which changed since the last run
+func (r *OVSNodeOspReconciler) reconcileSystemIDs(instance *neutronv1beta1.OVSNodeOsp) error {
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(instance.Spec.RoleName))); err != nil {
+		return err
+	}
 
-	// Check if this Daemonset already exists
-	found := &appsv1.DaemonSet{}
-	err = r.Client.Get(context.TODO(), types.NamespacedName{Name: ds.Name, Namespace: ds.Namespace}, found)
-	if err != nil && errors.IsNotFound(err) {
-		r.Log.Info("Creating a new Daemonset", "Ds.Namespace", ds.Namespace, "Ds.Name", ds.Name)
-		err = r.Client.Create(context.TODO(), ds)
+	systemIDs := map[string]string{}
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		systemID, err := ovsnodeosp.SystemID(node, ovsnodeosp.GetSystemIDSource(instance))
 		if err != nil {
-			return reconcile.Result{}, err
+			r.Log.Error(err, "Failed to get system-id", "Node", node.Name)
+			continue
+		}
+		if previous, ok := instance.Status.SystemIDs[node.Name]; ok && previous != systemID {
+			r.recordSystemIDChange(instance, node.Name, previous, systemID)
+		}
+		systemIDs[node.Name] = systemID
+	}
+
+	systemIDsConfigMap := ovsnodeosp.SystemIDsConfigMap(instance, instance.Name+"-system-ids", systemIDs)
+	if err := operand.EnsureConfigMap(r.Client, r.Log, r.Scheme, instance, systemIDsConfigMap); err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(instance.Status.SystemIDs, systemIDs) {
+		instance.Status.SystemIDs = systemIDs
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// recordSystemIDChange keeps the last system-id change of the node in the
+// status and raises an event, the chassis of the previous id is left behind
+// in the SB DB
+func (r *OVSNodeOspReconciler) recordSystemIDChange(instance *neutronv1beta1.OVSNodeOsp, node string, previous string, systemID string) {
+	change := neutronv1beta1.SystemIDChange{
+		Node:     node,
+		Previous: previous,
+		SystemID: systemID,
+		Time:     metav1.Now(),
+	}
+	for i, recorded := range instance.Status.SystemIDChanges {
+		if recorded.Node != node {
+			continue
+		}
+		if recorded.Previous == previous && recorded.SystemID == systemID {
+			return
+		}
+		instance.Status.SystemIDChanges = append(instance.Status.SystemIDChanges[:i], instance.Status.SystemIDChanges[i+1:]...)
+		break
+	}
+	instance.Status.SystemIDChanges = append(instance.Status.SystemIDChanges, change)
+	sort.Slice(instance.Status.SystemIDChanges, func(i, j int) bool {
+		return instance.Status.SystemIDChanges[i].Node < instance.Status.SystemIDChanges[j].Node
+	})
+
+	r.Log.Info("System-id changed", "Node", node, "Previous", previous, "SystemID", systemID)
+	r.Recorder.Eventf(instance, corev1.EventTypeWarning, "SystemIDChanged",
+		"system-id of node %s changed from %s to %s, the chassis of the previous id is stale", node, previous, systemID)
+}
+
+// reconcileGateways publishes the ovn-cms-options of every compute node in the
+// <name>-gateways ConfigMap. The pods pick up changes without a restart.
+func (r *OVSNodeOspReconciler) reconcileGateways(instance *neutronv1beta1.OVSNodeOsp) error {
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(instance.Spec.RoleName))); err != nil {
+		return err
+	}
+
+	cmsOptions := map[string]string{}
+	var gatewayNodes []string
+	for i := range nodes.Items {
+		node := &nodes.Items[i]
+		gateway, err := ovsnodeosp.IsGatewayNode(instance, node)
+		if err != nil {
+			return err
 		}
+		if gateway {
+			gatewayNodes = append(gatewayNodes, node.Name)
+		}
+		cmsOptions[node.Name] = ovsnodeosp.GetCMSOptions(instance, gateway)
+	}
+
+	gatewaysConfigMap := ovsnodeosp.GatewaysConfigMap(instance, instance.Name+"-gateways", cmsOptions)
+	if err := operand.EnsureConfigMap(r.Client, r.Log, r.Scheme, instance, gatewaysConfigMap); err != nil {
+		return err
+	}
+
+	sort.Strings(gatewayNodes)
+	if !reflect.DeepEqual(instance.Status.GatewayNodes, gatewayNodes) {
+		r.Log.Info("Gateway nodes changed", "GatewayNodes", gatewayNodes)
+		instance.Status.GatewayNodes = gatewayNodes
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
 
-		// Daemonset created successfully - don't requeue
+// reconcileRemovedNodes undoes the node setup on the nodes which left the
+// role. The chassis of deleted nodes is left to the OVNController of the role.
+func (r *OVSNodeOspReconciler) reconcileRemovedNodes(instance *neutronv1beta1.OVSNodeOsp) error {
+	cmName := instance.Name
+	managedNodes, err := cleanupRemovedNodes(r.Client, r.Log, r.Recorder, r.Scheme, instance, instance.Spec.RoleName,
+		instance.Status.ManagedNodes,
+		func(nodeName string, nodeExists bool) (*batchv1.Job, string) {
+			chassisName := common.GetChassisName(instance.Spec.ChassisNamePattern, nodeName)
+			if !nodeExists {
+				return nil, fmt.Sprintf("node deleted, chassis %s is removed by the OVNController", chassisName)
+			}
+			return ovsnodeosp.CleanupJob(instance, cmName, nodeName),
+				fmt.Sprintf("removed chassis %s, its external_ids and the provider and integration bridges", chassisName)
+		})
+	if err != nil {
+		return err
+	}
+
+	if !reflect.DeepEqual(instance.Status.ManagedNodes, managedNodes) {
+		instance.Status.ManagedNodes = managedNodes
+		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// reconcileDelete removes the chassis of the nodes and the node setup done by
+// ovsnode.sh, then releases the instance
+func (r *OVSNodeOspReconciler) reconcileDelete(instance *neutronv1beta1.OVSNodeOsp) (ctrl.Result, error) {
+	if !common.HasFinalizer(instance, common.ChassisCleanupFinalizer) {
 		return reconcile.Result{}, nil
-	} else if err != nil {
+	}
+
+	cmName := instance.Name
+	completed, err := cleanupNodes(r.Client, r.Log, r.Scheme, instance, instance.Spec.RoleName,
+		ovsnodeosp.ScriptsConfigMap(instance, cmName+"-scripts"),
+		func(nodeName string) *batchv1.Job {
+			return ovsnodeosp.CleanupJob(instance, cmName, nodeName)
+		})
+	if err != nil {
 		return reconcile.Result{}, err
+	}
+	if !completed {
+		return reconcile.Result{RequeueAfter: time.Second * 5}, nil
+	}
+
+	r.Log.Info("Nodes cleaned up, removing finalizer")
+	controllerutil.RemoveFinalizer(instance, common.ChassisCleanupFinalizer)
+	return reconcile.Result{}, r.Client.Update(context.TODO(), instance)
+}
+
+// reconcileHugepages reports the nodes without enough hugepages for DPDK in
+// the status and the HugepagesAvailable condition, the DaemonSet affinity
+// keeps their pods from being scheduled without holding back the other nodes
+func (r *OVSNodeOspReconciler) reconcileHugepages(instance *neutronv1beta1.OVSNodeOsp) error {
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(instance.Spec.RoleName))); err != nil {
+		return err
+	}
+
+	var nodesWithoutHugepages []string
+	for i := range nodes.Items {
+		if !ovsnodeosp.HugepagesAvailable(instance, &nodes.Items[i]) {
+			nodesWithoutHugepages = append(nodesWithoutHugepages, nodes.Items[i].Name)
+		}
+	}
+	sort.Strings(nodesWithoutHugepages)
+
+	changed := false
+	if instance.Spec.DPDK == nil {
+		changed = common.RemoveCondition(&instance.Status.Conditions, common.ConditionHugepagesAvailable)
 	} else {
-		if instance.Status.DaemonsetHash != dsHash {
-			r.Log.Info("Daemonset Updated")
-			found.Spec = ds.Spec
-			err = r.Client.Update(context.TODO(), found)
-			if err != nil {
-				return reconcile.Result{}, err
-			}
-			r.setDaemonsetHash(instance, dsHash)
-			return reconcile.Result{RequeueAfter: time.Second}, err
+		status, reason, message := corev1.ConditionTrue, "Available", "all nodes have enough hugepages for DPDK"
+		if len(nodesWithoutHugepages) > 0 {
+			status, reason = corev1.ConditionFalse, "NodesWithoutHugepages"
+			message = fmt.Sprintf("nodes without enough hugepages for DPDK, they get no pod: %s", strings.Join(nodesWithoutHugepages, ", "))
 		}
+		changed = common.SetCondition(&instance.Status.Conditions, common.ConditionHugepagesAvailable, status, reason, message)
 	}
 
-	// Daemonset already exists - don't requeue
-	r.Log.Info("Skip reconcile: Daemonset already exists", "Ds.Namespace", found.Namespace, "Ds.Name", found.Name)
-	return reconcile.Result{}, nil
+	if !changed && reflect.DeepEqual(instance.Status.NodesWithoutHugepages, nodesWithoutHugepages) {
+		return nil
+	}
+	if len(nodesWithoutHugepages) > 0 {
+		r.Log.Info("Nodes without enough hugepages", "Nodes", nodesWithoutHugepages)
+	}
+	instance.Status.NodesWithoutHugepages = nodesWithoutHugepages
+	return r.Client.Status().Update(context.TODO(), instance)
 }
 
-func (r *OVSNodeOspReconciler) setDaemonsetHash(instance *neutronv1beta1.OVSNodeOsp, hashStr string) error {
-	if hashStr != instance.Status.DaemonsetHash {
-		instance.Status.DaemonsetHash = hashStr
+// updateNodeReports collects the reports written by the pods into the status
+func (r *OVSNodeOspReconciler) updateNodeReports(instance *neutronv1beta1.OVSNodeOsp) error {
+	pods := &corev1.PodList{}
+	if err := r.Client.List(context.TODO(), pods, client.InNamespace(instance.Namespace),
+		client.MatchingLabels(map[string]string{"daemonset": instance.Name + "-daemonset"})); err != nil {
+		return err
+	}
+
+	reports, err := getNodeReports(r.Client, r.Log, instance, pods.Items)
+	if err != nil {
+		return err
+	}
+	var hwOffload map[string]string
+	for node, report := range reports {
+		if state, ok := report["hw-offload"]; ok {
+			if hwOffload == nil {
+				hwOffload = map[string]string{}
+			}
+			hwOffload[node] = state
+		}
+	}
+	bonds := ovsnodeosp.GetBondStatus(instance, reports)
+	nodes := common.GetNodeStatus(pods.Items, reports, instance.Status.GatewayNodes)
+
+	// system-id changes found by the nodes, e.g. after the source changed
+	previousChanges := append([]neutronv1beta1.SystemIDChange(nil), instance.Status.SystemIDChanges...)
+	for _, node := range nodes {
+		previous := reports[node.Node]["previous-system-id"]
+		if previous != "" && node.SystemID != "" && previous != node.SystemID {
+			r.recordSystemIDChange(instance, node.Node, previous, node.SystemID)
+		}
+	}
+	systemIDChanged := !reflect.DeepEqual(previousChanges, instance.Status.SystemIDChanges)
+
+	if !reflect.DeepEqual(instance.Status.HWOffload, hwOffload) || !reflect.DeepEqual(instance.Status.Bonds, bonds) ||
+		!reflect.DeepEqual(instance.Status.Nodes, nodes) || systemIDChanged {
+		instance.Status.HWOffload = hwOffload
+		instance.Status.Bonds = bonds
+		instance.Status.Nodes = nodes
 		if err := r.Client.Status().Update(context.TODO(), instance); err != nil {
 			return err
 		}
@@ -179,148 +464,100 @@ func (r *OVSNodeOspReconciler) setDaemonsetHash(instance *neutronv1beta1.OVSNode
 	return nil
 }
 
-func ovsNodeDaemonset(cr *neutronv1beta1.OVSNodeOsp, cmName string, templatesConfigHash string, scriptsConfigHash string) *appsv1.DaemonSet {
-	var trueVar = true
-
-	daemonSet := appsv1.DaemonSet{
-		TypeMeta: metav1.TypeMeta{
-			Kind:       "DaemonSet",
-			APIVersion: "apps/v1",
-		},
-		ObjectMeta: metav1.ObjectMeta{
-			Name:      cmName,
-			Namespace: cr.Namespace,
-		},
-		Spec: appsv1.DaemonSetSpec{
-			Selector: &metav1.LabelSelector{
-				MatchLabels: map[string]string{"daemonset": cr.Name + "-daemonset"},
-			},
-			Template: corev1.PodTemplateSpec{
-				ObjectMeta: metav1.ObjectMeta{
-					Labels: map[string]string{"daemonset": cr.Name + "-daemonset"},
-				},
-				Spec: corev1.PodSpec{
-					NodeSelector:       common.GetComputeWorkerNodeSelector(cr.Spec.RoleName),
-					HostNetwork:        true,
-					HostPID:            true,
-					DNSPolicy:          "ClusterFirstWithHostNet",
-					Containers:         []corev1.Container{},
-					Tolerations:        []corev1.Toleration{},
-					ServiceAccountName: cr.Spec.ServiceAccount,
-					PriorityClassName:  "system-node-critical",
-				},
-			},
-		},
-	}
-
-	// add compute worker nodes tolerations
-	for _, toleration := range common.GetComputeWorkerTolerations(cr.Spec.RoleName) {
-		daemonSet.Spec.Template.Spec.Tolerations = append(daemonSet.Spec.Template.Spec.Tolerations, toleration)
-	}
-
-	containerSpec := corev1.Container{
-		Name:  "ovs-node-osp",
-		Image: cr.Spec.OvsNodeOspImage,
-		Command: []string{
-			"bash", "-c", "/usr/local/sbin/ovsnode.sh",
-		},
-		SecurityContext: &corev1.SecurityContext{
-			Privileged: &trueVar,
-		},
-		ReadinessProbe: &corev1.Probe{
-			Handler: corev1.Handler{
-				Exec: &corev1.ExecAction{
-					Command: []string{
-						"/usr/share/openvswitch/scripts/ovs-ctl", "status",
-					},
-				},
-			},
-			InitialDelaySeconds: 15,
-			PeriodSeconds:       5,
-		},
-		LivenessProbe: &corev1.Probe{
-			Handler: corev1.Handler{
-				Exec: &corev1.ExecAction{
-					Command: []string{
-						"/usr/share/openvswitch/scripts/ovs-ctl", "status",
-					},
-				},
-			},
-			InitialDelaySeconds: 15,
-			PeriodSeconds:       5,
-		},
-		Env: []corev1.EnvVar{
-			{
-				Name:  "TEMPLATES_CONFIG_HASH",
-				Value: templatesConfigHash,
-			},
-			{
-				Name:  "OVS_LOG_LEVEL",
-				Value: cr.Spec.OvsLogLevel,
-			},
-			{
-				Name:  "NIC",
-				Value: cr.Spec.Nic,
-			},
-			{
-				Name:  "GATEWAY",
-				Value: fmt.Sprintf("%t", cr.Spec.Gateway),
-			},
-			{
-				Name:  "BRIDGE_MAPPINGS",
-				Value: cr.Spec.BridgeMappings,
-			},
-			{
-				Name: "OVN_SB_REMOTE",
-				ValueFrom: &corev1.EnvVarSource{
-					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
-						LocalObjectReference: corev1.LocalObjectReference{
-							Name: "ovn-connection",
-						},
-						Key: "SBConnection",
-					},
-				},
-			},
-			{
-				Name:  "K8S_NODE",
-				Value: cmName,
-			},
-			{
-				Name:  "SCRIPTS_CONFIG_HASH",
-				Value: scriptsConfigHash,
-			},
-		},
-		VolumeMounts: []corev1.VolumeMount{},
-	}
-	// add common VolumeMounts
-	for _, volMount := range common.GetVolumeMounts() {
-		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
-	}
-	// add ovsnode specific VolumeMounts
-	for _, volMount := range ovsnodeosp.GetVolumeMounts(cmName) {
-		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
-	}
-
-	daemonSet.Spec.Template.Spec.Containers = append(daemonSet.Spec.Template.Spec.Containers, containerSpec)
-
-	// Volume config
-	// add common Volumes
-	for _, volConfig := range common.GetVolumes(cmName) {
-		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
-	}
-	// add ovs Volumes
-	for _, volConfig := range ovsnodeosp.GetVolumes(cmName) {
-		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
-	}
-
-	return &daemonSet
+// nodeToOVSNodeOsp maps a Node to the OVSNodeOsp instances of its compute role
+func (r *OVSNodeOspReconciler) nodeToOVSNodeOsp(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	instances := &neutronv1beta1.OVSNodeOspList{}
+	if err := r.Client.List(context.TODO(), instances); err != nil {
+		r.Log.Error(err, "Unable to list OVSNodeOsp instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		for label := range common.GetComputeWorkerNodeSelector(instance.Spec.RoleName) {
+			if _, ok := o.Meta.GetLabels()[label]; ok {
+				result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+			}
+		}
+	}
+	return result
+}
+
+// ovnControllerToOVSNodeOsp maps an OVNController to the OVSNodeOsp instances
+// of its role, which validate their naming scheme against it
+func (r *OVSNodeOspReconciler) ovnControllerToOVSNodeOsp(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	ovnController, ok := o.Object.(*neutronv1beta1.OVNController)
+	if !ok {
+		return result
+	}
+	instances := &neutronv1beta1.OVSNodeOspList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(o.Meta.GetNamespace())); err != nil {
+		r.Log.Error(err, "Unable to list OVSNodeOsp instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		if instance.Spec.RoleName == ovnController.Spec.RoleName {
+			result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+		}
+	}
+	return result
+}
+
+// commonConfigToOVSNodeOsp maps a ConfigMap to the OVSNodeOsp instances using it as
+// common config map
+func (r *OVSNodeOspReconciler) commonConfigToOVSNodeOsp(o handler.MapObject) []reconcile.Request {
+	result := []reconcile.Request{}
+
+	instances := &neutronv1beta1.OVSNodeOspList{}
+	if err := r.Client.List(context.TODO(), instances, client.InNamespace(o.Meta.GetNamespace()),
+		client.MatchingFields{commonConfigMapField: o.Meta.GetName()}); err != nil {
+		r.Log.Error(err, "Unable to list OVSNodeOsp instances")
+		return result
+	}
+	for _, instance := range instances.Items {
+		result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: instance.Name, Namespace: instance.Namespace}})
+	}
+	return result
 }
 
 // SetupWithManager x
 func (r *OVSNodeOspReconciler) SetupWithManager(mgr ctrl.Manager) error {
+	if err := mgr.GetFieldIndexer().IndexField(context.TODO(), &neutronv1beta1.OVSNodeOsp{}, commonConfigMapField, func(o runtime.Object) []string {
+		return []string{common.GetCommonConfigMap(o.(*neutronv1beta1.OVSNodeOsp).Spec.CommonConfigMap)}
+	}); err != nil {
+		return err
+	}
+
+	if err := operand.IndexReferences(mgr.GetFieldIndexer(), "OVSNodeOsp"); err != nil {
+		return err
+	}
+
 	return ctrl.NewControllerManagedBy(mgr).
 		For(&neutronv1beta1.OVSNodeOsp{}).
 		Owns(&corev1.ConfigMap{}).
 		Owns(&appsv1.DaemonSet{}).
+		Owns(&rbacv1.Role{}).
+		Owns(&rbacv1.RoleBinding{}).
+		Owns(&batchv1.Job{}).
+		Watches(&source.Kind{Type: &corev1.Node{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.nodeToOVSNodeOsp),
+		}, builder.WithPredicates(operand.NodeChanged)).
+		Watches(&source.Kind{Type: &neutronv1beta1.OVNController{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.ovnControllerToOVSNodeOsp),
+		}).
+		Watches(&source.Kind{Type: &corev1.Pod{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(operand.PodToOwner),
+		}).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: operand.ReferenceToOwner(mgr.GetClient(), "OVSNodeOsp"),
+		}, builder.WithPredicates(operand.DataChanged)).
+		Watches(&source.Kind{Type: &corev1.ConfigMap{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: handler.ToRequestsFunc(r.commonConfigToOVSNodeOsp),
+		}, builder.WithPredicates(operand.DataChanged)).
+		Watches(&source.Kind{Type: &corev1.Secret{}}, &handler.EnqueueRequestsFromMapFunc{
+			ToRequests: operand.ReferenceToOwner(mgr.GetClient(), "OVSNodeOsp"),
+		}, builder.WithPredicates(operand.DataChanged)).
 		Complete(r)
 }
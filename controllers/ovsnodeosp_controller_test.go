@@ -0,0 +1,308 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "openstack"
+
+// useTemplateCopy points OPERATOR_TEMPLATES to a copy of the templates, so a
+// test can change them. The returned function removes the copy.
+func useTemplateCopy(t *testing.T) (string, func()) {
+	t.Helper()
+	dir, err := ioutil.TempDir("", "templates")
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = filepath.Walk("../templates", func(path string, info os.FileInfo, err error) error {
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel("../templates", path)
+		if err != nil {
+			return err
+		}
+		if info.IsDir() {
+			return os.MkdirAll(filepath.Join(dir, rel), 0755)
+		}
+		data, err := ioutil.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		return ioutil.WriteFile(filepath.Join(dir, rel), data, info.Mode())
+	})
+	if err != nil {
+		os.RemoveAll(dir)
+		t.Fatal(err)
+	}
+	// the templates get read from OPERATOR_TEMPLATES + file name
+	os.Setenv("OPERATOR_TEMPLATES", dir+"/")
+	return dir, func() {
+		os.Unsetenv("OPERATOR_TEMPLATES")
+		os.RemoveAll(dir)
+	}
+}
+
+func newTestOVSNodeOspReconciler(t *testing.T, objs ...runtime.Object) *OVSNodeOspReconciler {
+	t.Helper()
+	if err := neutronv1beta1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	return &OVSNodeOspReconciler{
+		Client:   fake.NewFakeClientWithScheme(scheme.Scheme, objs...),
+		Log:      ctrl.Log.WithName("controllers").WithName("OVSNodeOsp"),
+		Scheme:   scheme.Scheme,
+		Recorder: record.NewFakeRecorder(1000),
+	}
+}
+
+func reconcileOVSNodeOsp(t *testing.T, r *OVSNodeOspReconciler, name string) {
+	t.Helper()
+	req := ctrl.Request{NamespacedName: types.NamespacedName{Name: name, Namespace: testNamespace}}
+	// the first reconciles add the finalizer and create the objects
+	for i := 0; i < 3; i++ {
+		if _, err := r.Reconcile(req); err != nil {
+			t.Fatalf("reconcile failed: %v", err)
+		}
+	}
+}
+
+func getScriptsAndTemplateHash(t *testing.T, c client.Client, name string) (map[string]string, string) {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name + "-scripts", Namespace: testNamespace}, configMap); err != nil {
+		t.Fatalf("scripts ConfigMap: %v", err)
+	}
+	ds := &appsv1.DaemonSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, ds); err != nil {
+		t.Fatalf("DaemonSet: %v", err)
+	}
+	return configMap.Data, ds.Spec.Template.Annotations[common.TemplateHashAnnotation]
+}
+
+func testOVSNodeOsp() *neutronv1beta1.OVSNodeOsp {
+	return &neutronv1beta1.OVSNodeOsp{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: neutronv1beta1.GroupVersion.String(),
+			Kind:       "OVSNodeOsp",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovs-node-osp",
+			Namespace: testNamespace,
+		},
+		Spec: neutronv1beta1.OVSNodeOspSpec{
+			OvsNodeOspImage: "ovs-node-osp:latest",
+			ServiceAccount:  "neutron",
+			RoleName:        "worker-osp",
+			OvsLogLevel:     "info",
+			Nic:             "enp2s0",
+		},
+	}
+}
+
+// testOVNConnection is the ConfigMap the daemonset takes the SB connection from
+func testOVNConnection() *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovn-connection",
+			Namespace: testNamespace,
+		},
+		Data: map[string]string{
+			"SBConnection": "tcp:192.168.122.10:6642",
+		},
+	}
+}
+
+func TestOVSNodeOspTemplateChangeRollsDaemonSet(t *testing.T) {
+	dir, cleanup := useTemplateCopy(t)
+	defer cleanup()
+
+	instance := testOVSNodeOsp()
+	r := newTestOVSNodeOspReconciler(t, instance, testOVNConnection())
+	reconcileOVSNodeOsp(t, r, instance.Name)
+	scripts, templateHash := getScriptsAndTemplateHash(t, r.Client, instance.Name)
+	if templateHash == "" {
+		t.Fatal("DaemonSet pod template has no template hash")
+	}
+
+	// an operator upgrade shipping a changed script
+	script := filepath.Join(dir, "ovsnodeosp", "ovsnode.sh")
+	data, err := ioutil.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := ioutil.WriteFile(script, append(data, []byte("# changed\n")...), 0644); err != nil {
+		t.Fatal(err)
+	}
+	reconcileOVSNodeOsp(t, r, instance.Name)
+
+	newScripts, newTemplateHash := getScriptsAndTemplateHash(t, r.Client, instance.Name)
+	if newScripts["ovsnode.sh"] != string(data)+"# changed\n" {
+		t.Error("scripts ConfigMap did not get the changed template")
+	}
+	if newScripts["cleanup.sh"] != scripts["cleanup.sh"] {
+		t.Error("unchanged template changed in the scripts ConfigMap")
+	}
+	if newTemplateHash == templateHash {
+		t.Error("DaemonSet pod template hash did not change, the pods would keep the old script")
+	}
+}
+
+func TestOVSNodeOspRevertsConfigMapEdit(t *testing.T) {
+	_, cleanup := useTemplateCopy(t)
+	defer cleanup()
+
+	instance := testOVSNodeOsp()
+	r := newTestOVSNodeOspReconciler(t, instance, testOVNConnection())
+	reconcileOVSNodeOsp(t, r, instance.Name)
+	scripts, templateHash := getScriptsAndTemplateHash(t, r.Client, instance.Name)
+
+	// out of band edit of the ConfigMap
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Name + "-scripts", Namespace: testNamespace}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	configMap.Data["ovsnode.sh"] = "#!/bin/bash\nexit 0\n"
+	configMap.Data["extra.sh"] = "#!/bin/bash\n"
+	if err := r.Client.Update(context.TODO(), configMap); err != nil {
+		t.Fatal(err)
+	}
+	reconcileOVSNodeOsp(t, r, instance.Name)
+
+	newScripts, newTemplateHash := getScriptsAndTemplateHash(t, r.Client, instance.Name)
+	if newScripts["ovsnode.sh"] != scripts["ovsnode.sh"] {
+		t.Error("out of band edit of ovsnode.sh was not reverted")
+	}
+	if _, ok := newScripts["extra.sh"]; ok {
+		t.Error("key added out of band was not removed")
+	}
+	if newTemplateHash != templateHash {
+		t.Error("DaemonSet pod template hash changed although the desired scripts did not")
+	}
+}
+
+func TestOVSNodeOspOVSConfigChangeKeepsDaemonSet(t *testing.T) {
+	_, cleanup := useTemplateCopy(t)
+	defer cleanup()
+
+	instance := testOVSNodeOsp()
+	instance.Spec.BridgeMappings = "datacentre:br-ex"
+	r := newTestOVSNodeOspReconciler(t, instance, testOVNConnection())
+	reconcileOVSNodeOsp(t, r, instance.Name)
+	_, templateHash := getScriptsAndTemplateHash(t, r.Client, instance.Name)
+
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: testNamespace}, instance); err != nil {
+		t.Fatal(err)
+	}
+	instance.Spec.ExternalIDs = map[string]string{"ovn-ofctrl-wait-before-clear": "8000"}
+	instance.Spec.Bonds = []neutronv1beta1.OVSBondSpec{
+		{Name: "bond0", Bridge: "br-ex", Interfaces: []string{"enp3s0", "enp4s0"}, Mode: "active-backup"},
+	}
+	if err := r.Client.Update(context.TODO(), instance); err != nil {
+		t.Fatal(err)
+	}
+	reconcileOVSNodeOsp(t, r, instance.Name)
+
+	configMap := &corev1.ConfigMap{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Name + "-ovs-config", Namespace: testNamespace}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	if configMap.Data["external_ids"] != "ovn-ofctrl-wait-before-clear=8000\n" {
+		t.Errorf("ovs-config ConfigMap has external_ids %q", configMap.Data["external_ids"])
+	}
+	if configMap.Data["bonds"] != "br-ex bond0 active-backup off enp3s0,enp4s0\n" {
+		t.Errorf("ovs-config ConfigMap has bonds %q", configMap.Data["bonds"])
+	}
+	if _, newTemplateHash := getScriptsAndTemplateHash(t, r.Client, instance.Name); newTemplateHash != templateHash {
+		t.Error("DaemonSet pod template hash changed, ovs-vswitchd would restart for an external_ids or bond change")
+	}
+}
+
+func TestOVSNodeOspExcludesNodesWithoutHugepages(t *testing.T) {
+	_, cleanup := useTemplateCopy(t)
+	defer cleanup()
+
+	instance := testOVSNodeOsp()
+	instance.Spec.DPDK = &neutronv1beta1.OVSDPDKSpec{
+		HugepageSize: "1Gi",
+		Hugepages:    resource.MustParse("4Gi"),
+	}
+	node := func(name string, hugepages string) *corev1.Node {
+		return &corev1.Node{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:   name,
+				Labels: common.GetComputeWorkerNodeSelector(instance.Spec.RoleName),
+			},
+			Status: corev1.NodeStatus{
+				Allocatable: corev1.ResourceList{"hugepages-1Gi": resource.MustParse(hugepages)},
+			},
+		}
+	}
+	r := newTestOVSNodeOspReconciler(t, instance, testOVNConnection(), node("worker-0", "8Gi"), node("worker-1", "0"))
+	reconcileOVSNodeOsp(t, r, instance.Name)
+
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: testNamespace}, instance); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(instance.Status.NodesWithoutHugepages, []string{"worker-1"}) {
+		t.Errorf("nodesWithoutHugepages = %v, want [worker-1]", instance.Status.NodesWithoutHugepages)
+	}
+	ds := &appsv1.DaemonSet{}
+	if err := r.Client.Get(context.TODO(), types.NamespacedName{Name: instance.Name, Namespace: testNamespace}, ds); err != nil {
+		t.Fatal(err)
+	}
+	affinity := ds.Spec.Template.Spec.Affinity
+	if affinity == nil || affinity.NodeAffinity == nil || affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution == nil {
+		t.Fatal("DaemonSet has no node affinity for the nodes without hugepages")
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || len(terms[0].MatchFields) != 1 || !reflect.DeepEqual(terms[0].MatchFields[0].Values, []string{"worker-1"}) {
+		t.Errorf("DaemonSet node affinity %v does not exclude worker-1", terms)
+	}
+
+	// the exclusion is per instance, the nodes stay untouched
+	nodes := &corev1.NodeList{}
+	if err := r.Client.List(context.TODO(), nodes); err != nil {
+		t.Fatal(err)
+	}
+	for _, n := range nodes.Items {
+		if !reflect.DeepEqual(n.Labels, common.GetComputeWorkerNodeSelector(instance.Spec.RoleName)) {
+			t.Errorf("node %s got labels %v", n.Name, n.Labels)
+		}
+	}
+}
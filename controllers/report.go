@@ -0,0 +1,121 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// reconcileReportRBAC creates the report ConfigMap and allows the service
+// account of the pods to write to it
+func reconcileReportRBAC(c client.Client, log logr.Logger, scheme *runtime.Scheme, owner operand.Object, serviceAccount string) error {
+	// the data belongs to the pods, the ConfigMap only gets created
+	configMap := common.ReportConfigMap(owner.GetNamespace(), owner.GetName())
+	if err := controllerutil.SetControllerReference(owner, configMap, scheme); err != nil {
+		return err
+	}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, &corev1.ConfigMap{})
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		if err := c.Create(context.TODO(), configMap); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	}
+
+	role := common.ReportRole(owner.GetNamespace(), owner.GetName()+"-report", configMap.Name)
+	if err := controllerutil.SetControllerReference(owner, role, scheme); err != nil {
+		return err
+	}
+	foundRole := &rbacv1.Role{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: role.Name, Namespace: role.Namespace}, foundRole)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
+		if err := c.Create(context.TODO(), role); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if !reflect.DeepEqual(role.Rules, foundRole.Rules) {
+		log.Info("Updating Role", "Role.Namespace", role.Namespace, "Role.Name", role.Name)
+		foundRole.Rules = role.Rules
+		if err := c.Update(context.TODO(), foundRole); err != nil {
+			return err
+		}
+	}
+
+	roleBinding := common.ReportRoleBinding(owner.GetNamespace(), owner.GetName()+"-report", serviceAccount)
+	if err := controllerutil.SetControllerReference(owner, roleBinding, scheme); err != nil {
+		return err
+	}
+	foundRoleBinding := &rbacv1.RoleBinding{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: roleBinding.Name, Namespace: roleBinding.Namespace}, foundRoleBinding)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new RoleBinding", "RoleBinding.Namespace", roleBinding.Namespace, "RoleBinding.Name", roleBinding.Name)
+		if err := c.Create(context.TODO(), roleBinding); err != nil {
+			return err
+		}
+	} else if err != nil {
+		return err
+	} else if !reflect.DeepEqual(roleBinding.Subjects, foundRoleBinding.Subjects) {
+		log.Info("Updating RoleBinding")
+		foundRoleBinding.Subjects = roleBinding.Subjects
+		if err := c.Update(context.TODO(), foundRoleBinding); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// getNodeReports returns the reports the pods wrote to the report ConfigMap
+// of the owner, keyed by node name. The reports of pods which are gone get
+// removed unless the owner is paused.
+func getNodeReports(c client.Client, log logr.Logger, owner operand.Object, pods []corev1.Pod) (map[string]map[string]string, error) {
+	configMap := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: common.ReportConfigMapName(owner.GetName()), Namespace: owner.GetNamespace()}, configMap)
+	if err != nil && errors.IsNotFound(err) {
+		return map[string]map[string]string{}, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	if stale := common.GetStaleReports(pods, configMap); len(stale) > 0 && !common.IsPaused(owner) {
+		original := configMap.DeepCopy()
+		for _, key := range stale {
+			delete(configMap.Data, key)
+		}
+		log.Info("Removing reports of deleted pods", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name, "Keys", stale)
+		// the merge patch only removes the stale keys, the pods keep writing theirs
+		if err := c.Patch(context.TODO(), configMap, client.MergeFrom(original)); err != nil {
+			return nil, err
+		}
+	}
+	return common.GetNodeReports(pods, configMap), nil
+}
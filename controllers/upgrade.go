@@ -0,0 +1,586 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/operand"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// rolloutGate tells which pods a rollout may restart
+type rolloutGate struct {
+	// nodes annotated to hold their upgrades
+	heldNodes []string
+	// no pods get restarted outside of the maintenance windows
+	open       bool
+	nextWindow time.Time
+	// the operator restarts the pods instead of the daemonset controller
+	restricted bool
+}
+
+// getRolloutGate gets the held nodes of the role and checks the maintenance windows
+func getRolloutGate(c client.Client, strategy *neutronv1beta1.UpgradeStrategy, roleName string) (rolloutGate, error) {
+	gate := rolloutGate{}
+	if err := common.ValidateMaintenanceWindows(strategy); err != nil {
+		return gate, err
+	}
+
+	nodes := &corev1.NodeList{}
+	if err := c.List(context.TODO(), nodes, client.MatchingLabels(common.GetComputeWorkerNodeSelector(roleName))); err != nil {
+		return gate, err
+	}
+	for i := range nodes.Items {
+		if common.IsNodeHeld(&nodes.Items[i]) {
+			gate.heldNodes = append(gate.heldNodes, nodes.Items[i].Name)
+		}
+	}
+	sort.Strings(gate.heldNodes)
+
+	gate.open, gate.nextWindow = common.InMaintenanceWindow(strategy, time.Now())
+	gate.restricted = len(gate.heldNodes) > 0 || (strategy != nil && len(strategy.MaintenanceWindows) > 0)
+	return gate, nil
+}
+
+// isHeld checks if the upgrades of the node are on hold
+func (g rolloutGate) isHeld(nodeName string) bool {
+	for _, heldNode := range g.heldNodes {
+		if heldNode == nodeName {
+			return true
+		}
+	}
+	return false
+}
+
+// waitForWindow requeues at the start of the next maintenance window, or
+// after an hour to pick up changes of the windows
+func (g rolloutGate) waitForWindow() ctrl.Result {
+	if g.nextWindow.IsZero() || time.Until(g.nextWindow) > time.Hour {
+		return ctrl.Result{RequeueAfter: time.Hour}
+	}
+	return ctrl.Result{RequeueAfter: time.Until(g.nextWindow) + time.Second}
+}
+
+// status sets the held nodes and the next maintenance window of the status
+func (g rolloutGate) status(status *neutronv1beta1.UpgradeStatus) {
+	status.HeldNodes = g.heldNodes
+	status.NextMaintenanceWindow = nil
+	if !g.open && !g.nextWindow.IsZero() {
+		next := metav1.NewTime(g.nextWindow)
+		status.NextMaintenanceWindow = &next
+	}
+}
+
+// rollout - the rollout of the pod template of an instance. It prepares the
+// desired daemonset for operand.Reconcile, then upgrades the pods of the live
+// one with the strategy of the spec.
+type rollout struct {
+	client    client.Client
+	log       logr.Logger
+	recorder  record.EventRecorder
+	scheme    *runtime.Scheme
+	owner     operand.Object
+	strategy  *neutronv1beta1.UpgradeStrategy
+	specImage string
+	// image to build the daemonset with, the last complete one if the
+	// canary of the spec image failed
+	image     string
+	gate      rolloutGate
+	check     canaryCheck
+	revisions *[]neutronv1beta1.DaemonsetRevision
+	upgrade   *neutronv1beta1.UpgradeStatus
+}
+
+// newRollout gets the rollout gate of the role and the image to roll out
+func newRollout(c client.Client, log logr.Logger, recorder record.EventRecorder, scheme *runtime.Scheme, owner operand.Object,
+	strategy *neutronv1beta1.UpgradeStrategy, roleName string, specImage string, check canaryCheck,
+	revisions *[]neutronv1beta1.DaemonsetRevision, upgrade *neutronv1beta1.UpgradeStatus) (*rollout, error) {
+
+	gate, err := getRolloutGate(c, strategy, roleName)
+	if err != nil {
+		log.Error(err, "Invalid upgrade strategy")
+		return nil, err
+	}
+	return &rollout{
+		client:    c,
+		log:       log,
+		recorder:  recorder,
+		scheme:    scheme,
+		owner:     owner,
+		strategy:  strategy,
+		specImage: specImage,
+		image:     common.GetRolloutImage(specImage, *revisions),
+		gate:      gate,
+		check:     check,
+		revisions: revisions,
+		upgrade:   upgrade,
+	}, nil
+}
+
+// prepare lets the operator restart the canary nodes, and the pods of the
+// nodes not on hold within the maintenance windows. After a failed canary the
+// nodes return to the template of the last complete revision.
+func (r *rollout) prepare(ds *appsv1.DaemonSet) error {
+	if r.gate.restricted || common.IsCanaryRollout(r.strategy, r.image, *r.revisions) {
+		ds.Spec.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{Type: appsv1.OnDeleteDaemonSetStrategyType}
+	}
+	return restoreRevisionTemplate(r.client, r.log, ds, r.specImage, *r.revisions)
+}
+
+// reconcile continues the rollout of the template on the live daemonset and
+// writes the revisions and the upgrade progress to the status
+func (r *rollout) reconcile(ds *appsv1.DaemonSet, templateHash string) (ctrl.Result, error) {
+	revisions, upgrade, result, err := reconcileRollout(r.client, r.log, r.recorder, r.scheme, r.owner, r.strategy, r.gate,
+		ds, templateHash, r.image, r.check, *r.revisions, *r.upgrade)
+	if err != nil {
+		return ctrl.Result{}, err
+	}
+	if !reflect.DeepEqual(*r.upgrade, upgrade) || !reflect.DeepEqual(*r.revisions, revisions) {
+		*r.upgrade = upgrade
+		*r.revisions = revisions
+		if err := r.client.Status().Update(context.TODO(), r.owner); err != nil {
+			return ctrl.Result{}, err
+		}
+	}
+	return result, nil
+}
+
+// canaryCheck is the health check of the kind run for each canary node at the
+// end of the soak time, on top of the pod readiness. It returns why the node
+// failed, or wait while its health since the soak start is not known yet.
+type canaryCheck func(nodeName string, since time.Time) (failure string, wait bool)
+
+// reconcileRollout records the pod template rolled out to the daemon pods in
+// the revisions and either runs the canary phase of a new image or upgrades
+// the pods with the strategy of the spec. The template of the newest revision
+// is kept to roll back to it.
+func reconcileRollout(c client.Client, log logr.Logger, recorder record.EventRecorder, scheme *runtime.Scheme, owner operand.Object,
+	strategy *neutronv1beta1.UpgradeStrategy, gate rolloutGate, ds *appsv1.DaemonSet, templateHash string, image string, check canaryCheck,
+	revisions []neutronv1beta1.DaemonsetRevision, upgrade neutronv1beta1.UpgradeStatus) ([]neutronv1beta1.DaemonsetRevision, neutronv1beta1.UpgradeStatus, ctrl.Result, error) {
+
+	var result ctrl.Result
+	var err error
+	if common.IsCanaryRollout(strategy, image, revisions) {
+		revisions, upgrade, result, err = reconcileCanary(c, log, recorder, owner, strategy.Canary, gate, ds, templateHash, image, check, revisions, upgrade)
+	} else {
+		revisions, upgrade, result, err = reconcileRolloutRevision(c, log, recorder, owner, strategy, gate, ds, templateHash, image, revisions, upgrade)
+	}
+	if err != nil {
+		return revisions, upgrade, result, err
+	}
+	return revisions, upgrade, result, reconcileRevisionTemplates(c, log, scheme, owner, ds, templateHash, revisions)
+}
+
+// reconcileRolloutRevision upgrades the pods and adds a revision for a new pod
+// template, which completes once all the nodes run it
+func reconcileRolloutRevision(c client.Client, log logr.Logger, recorder record.EventRecorder, owner operand.Object,
+	strategy *neutronv1beta1.UpgradeStrategy, gate rolloutGate, ds *appsv1.DaemonSet, templateHash string, image string,
+	revisions []neutronv1beta1.DaemonsetRevision, upgrade neutronv1beta1.UpgradeStatus) ([]neutronv1beta1.DaemonsetRevision, neutronv1beta1.UpgradeStatus, ctrl.Result, error) {
+
+	status, result, err := reconcileUpgrade(c, log, recorder, owner, strategy, gate, ds, templateHash)
+	if err != nil {
+		return revisions, upgrade, result, err
+	}
+	// the node restarted last is shown until the upgrade completes
+	if status.CurrentNode == "" && len(status.PendingNodes) > 0 {
+		status.CurrentNode = upgrade.CurrentNode
+	}
+
+	revisions = append([]neutronv1beta1.DaemonsetRevision{}, revisions...)
+	// rolling back keeps the failed revision on top
+	rollback := len(revisions) > 0 && revisions[0].Phase == common.RevisionPhaseRolledBack &&
+		image == common.GetLastCompleteImage(revisions)
+	if len(revisions) == 0 || (templateHash != revisions[0].TemplateHash && !rollback) {
+		revisions = common.AddRevision(revisions, neutronv1beta1.DaemonsetRevision{
+			Image:        image,
+			TemplateHash: templateHash,
+			Phase:        common.RevisionPhaseRollingOut,
+			Time:         metav1.Now(),
+		})
+	}
+	if revisions[0].Phase == common.RevisionPhaseRollingOut && len(status.PendingNodes) == 0 {
+		recorder.Eventf(owner, corev1.EventTypeNormal, "RolloutComplete", "All nodes run %s", image)
+		revisions[0].Phase = common.RevisionPhaseComplete
+	}
+	return revisions, status, result, nil
+}
+
+// reconcileCanary restarts the canary nodes with the new image and watches
+// them for the soak time. Once they passed, the rollout continues with the
+// strategy of the spec. Otherwise the revision gets rolled back and the nodes
+// return to the last complete image. Held nodes are no canary candidates.
+func reconcileCanary(c client.Client, log logr.Logger, recorder record.EventRecorder, owner operand.Object,
+	canary *neutronv1beta1.CanarySpec, gate rolloutGate, ds *appsv1.DaemonSet, templateHash string, image string, check canaryCheck,
+	revisions []neutronv1beta1.DaemonsetRevision, upgrade neutronv1beta1.UpgradeStatus) ([]neutronv1beta1.DaemonsetRevision, neutronv1beta1.UpgradeStatus, ctrl.Result, error) {
+
+	revisions = append([]neutronv1beta1.DaemonsetRevision{}, revisions...)
+	// a spec change during the canary phase starts it over
+	if revisions[0].Image != image || revisions[0].TemplateHash != templateHash {
+		log.Info("Starting canary", "Image", image)
+		revisions = common.AddRevision(revisions, neutronv1beta1.DaemonsetRevision{
+			Image:        image,
+			TemplateHash: templateHash,
+			Phase:        common.RevisionPhaseCanary,
+			Time:         metav1.Now(),
+		})
+		upgrade = neutronv1beta1.UpgradeStatus{}
+	}
+	gate.status(&upgrade)
+
+	pods := &corev1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(ds.Namespace),
+		client.MatchingLabels(ds.Spec.Selector.MatchLabels)); err != nil {
+		return revisions, upgrade, ctrl.Result{}, err
+	}
+	podsByNode := map[string]*corev1.Pod{}
+	upgrade.PendingNodes = nil
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		podsByNode[pod.Spec.NodeName] = pod
+		if common.IsPodOutdated(pod, templateHash) {
+			upgrade.PendingNodes = append(upgrade.PendingNodes, pod.Spec.NodeName)
+		}
+	}
+	sort.Strings(upgrade.PendingNodes)
+
+	// the canary nodes are picked once, among the nodes running a pod
+	if len(upgrade.CanaryNodes) == 0 {
+		selector, err := metav1.LabelSelectorAsSelector(&canary.NodeSelector)
+		if err != nil {
+			return revisions, upgrade, ctrl.Result{}, err
+		}
+		nodes := &corev1.NodeList{}
+		if err := c.List(context.TODO(), nodes, client.MatchingLabelsSelector{Selector: selector}); err != nil {
+			return revisions, upgrade, ctrl.Result{}, err
+		}
+		sort.Slice(nodes.Items, func(i, j int) bool {
+			return nodes.Items[i].Name < nodes.Items[j].Name
+		})
+		for _, node := range nodes.Items {
+			if _, ok := podsByNode[node.Name]; ok && !gate.isHeld(node.Name) && len(upgrade.CanaryNodes) < common.GetCanaryCount(canary) {
+				upgrade.CanaryNodes = append(upgrade.CanaryNodes, node.Name)
+			}
+		}
+		if len(upgrade.CanaryNodes) == 0 {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CanaryFailed", "No canary node runs a pod, not rolling out %s", image)
+			return rollbackCanary(recorder, owner, revisions, upgrade)
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "CanaryStarted", "Rolling out %s to canary nodes %s",
+			image, strings.Join(upgrade.CanaryNodes, ","))
+	}
+
+	restarting := false
+	for _, nodeName := range upgrade.CanaryNodes {
+		pod, ok := podsByNode[nodeName]
+		if !ok {
+			restarting = true
+			continue
+		}
+		if common.IsPodOutdated(pod, templateHash) {
+			restarting = true
+			if pod.DeletionTimestamp.IsZero() && gate.open {
+				log.Info("Restarting canary pod", "Pod.Name", pod.Name, "Node", nodeName)
+				if err := c.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+					return revisions, upgrade, ctrl.Result{}, err
+				}
+			}
+			continue
+		}
+		if upgrade.CanaryStartTime != nil && common.GetPodRestarts(pod) > 0 {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CanaryFailed", "Pod %s on canary node %s restarted with %s",
+				pod.Name, nodeName, image)
+			return rollbackCanary(recorder, owner, revisions, upgrade)
+		}
+	}
+	if restarting {
+		upgrade.CanaryStartTime = nil
+		if !gate.open {
+			log.Info("Waiting for the maintenance window to restart the canary nodes", "NextWindow", gate.nextWindow)
+			return revisions, upgrade, gate.waitForWindow(), nil
+		}
+		return revisions, upgrade, ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+
+	// all the canary nodes run the new image, soak them
+	if upgrade.CanaryStartTime == nil {
+		now := metav1.Now()
+		upgrade.CanaryStartTime = &now
+	}
+	soakEnd := upgrade.CanaryStartTime.Add(time.Duration(common.GetCanarySoakSeconds(canary)) * time.Second)
+	if time.Now().Before(soakEnd) {
+		return revisions, upgrade, ctrl.Result{RequeueAfter: time.Until(soakEnd) + time.Second}, nil
+	}
+	for _, nodeName := range upgrade.CanaryNodes {
+		if !common.IsPodHealthy(podsByNode[nodeName], 0, time.Now()) {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CanaryFailed", "Canary node %s is not ready with %s",
+				nodeName, image)
+			return rollbackCanary(recorder, owner, revisions, upgrade)
+		}
+		if check == nil {
+			continue
+		}
+		failure, wait := check(nodeName, upgrade.CanaryStartTime.Time)
+		if wait {
+			log.Info("Waiting for the health of the canary node", "Node", nodeName)
+			return revisions, upgrade, ctrl.Result{RequeueAfter: time.Second * 10}, nil
+		}
+		if failure != "" {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "CanaryFailed", "Canary node %s failed with %s: %s",
+				nodeName, image, failure)
+			return rollbackCanary(recorder, owner, revisions, upgrade)
+		}
+	}
+
+	recorder.Eventf(owner, corev1.EventTypeNormal, "CanaryPassed", "Rolling out %s to the remaining nodes", image)
+	revisions[0].Phase = common.RevisionPhaseRollingOut
+	return revisions, upgrade, ctrl.Result{RequeueAfter: time.Second}, nil
+}
+
+// rollbackCanary marks the canary revision as rolled back, the daemonset
+// returns to the template of the last complete revision with the next
+// reconcile
+func rollbackCanary(recorder record.EventRecorder, owner operand.Object, revisions []neutronv1beta1.DaemonsetRevision,
+	upgrade neutronv1beta1.UpgradeStatus) ([]neutronv1beta1.DaemonsetRevision, neutronv1beta1.UpgradeStatus, ctrl.Result, error) {
+
+	recorder.Eventf(owner, corev1.EventTypeWarning, "RollingBack", "Rolling back from %s to %s",
+		revisions[0].Image, common.GetLastCompleteImage(revisions))
+	revisions[0].Phase = common.RevisionPhaseRolledBack
+	upgrade.CanaryStartTime = nil
+	return revisions, upgrade, ctrl.Result{RequeueAfter: time.Second}, nil
+}
+
+// revisionTemplateLabel - label of the ControllerRevisions keeping the pod
+// templates of the revisions, set to the name of the daemonset
+const revisionTemplateLabel string = "neutron.openstack.org/revision-of"
+
+// revisionTemplateName is the name of the ControllerRevision keeping the pod
+// template of the revision
+func revisionTemplateName(ds *appsv1.DaemonSet, templateHash string) string {
+	return ds.Name + "-" + templateHash
+}
+
+// reconcileRevisionTemplates keeps the pod template of the newest revision in
+// a ControllerRevision owned by the instance, and deletes the ones of the
+// revisions dropped from the history
+func reconcileRevisionTemplates(c client.Client, log logr.Logger, scheme *runtime.Scheme, owner operand.Object,
+	ds *appsv1.DaemonSet, templateHash string, revisions []neutronv1beta1.DaemonsetRevision) error {
+
+	controllerRevisions := &appsv1.ControllerRevisionList{}
+	if err := c.List(context.TODO(), controllerRevisions, client.InNamespace(ds.Namespace),
+		client.MatchingLabels{revisionTemplateLabel: ds.Name}); err != nil {
+		return err
+	}
+	kept := map[string]bool{}
+	for _, revision := range revisions {
+		if revision.TemplateHash != "" {
+			kept[revisionTemplateName(ds, revision.TemplateHash)] = true
+		}
+	}
+	// after a rollback the newest revision is the failed one, its template
+	// got kept when its canary started
+	name := revisionTemplateName(ds, templateHash)
+	save := len(revisions) > 0 && revisions[0].TemplateHash == templateHash
+	var lastRevision int64
+	for i := range controllerRevisions.Items {
+		controllerRevision := &controllerRevisions.Items[i]
+		if !metav1.IsControlledBy(controllerRevision, owner) {
+			continue
+		}
+		if controllerRevision.Revision > lastRevision {
+			lastRevision = controllerRevision.Revision
+		}
+		if controllerRevision.Name == name {
+			save = false
+		}
+		if kept[controllerRevision.Name] {
+			continue
+		}
+		log.Info("Deleting the template of a dropped revision", "ControllerRevision.Name", controllerRevision.Name)
+		if err := c.Delete(context.TODO(), controllerRevision); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	if !save {
+		return nil
+	}
+
+	data, err := json.Marshal(ds.Spec.Template)
+	if err != nil {
+		return err
+	}
+	controllerRevision := &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: ds.Namespace,
+			Labels:    map[string]string{revisionTemplateLabel: ds.Name},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: lastRevision + 1,
+	}
+	if err := controllerutil.SetControllerReference(owner, controllerRevision, scheme); err != nil {
+		return err
+	}
+	log.Info("Keeping the template of the revision", "ControllerRevision.Name", name)
+	if err := c.Create(context.TODO(), controllerRevision); err != nil && !errors.IsAlreadyExists(err) {
+		return err
+	}
+	return nil
+}
+
+// restoreRevisionTemplate sets the pod template of the last complete revision
+// on the daemonset after the canary of the spec image failed. The template
+// keeps its template hash, operand.Reconcile rolls it out as is. The
+// ConfigMaps are not versioned, the restored pods get their current data.
+// Without a kept template only the image returns to the one of the revision.
+func restoreRevisionTemplate(c client.Client, log logr.Logger, ds *appsv1.DaemonSet, specImage string,
+	revisions []neutronv1beta1.DaemonsetRevision) error {
+
+	if !common.IsRolledBack(specImage, revisions) {
+		return nil
+	}
+	revision := common.GetLastCompleteRevision(revisions)
+	if revision.TemplateHash == "" {
+		return nil
+	}
+	controllerRevision := &appsv1.ControllerRevision{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: revisionTemplateName(ds, revision.TemplateHash), Namespace: ds.Namespace},
+		controllerRevision)
+	if errors.IsNotFound(err) {
+		log.Info("Template of the last complete revision not found, only rolling back the image", "Image", revision.Image)
+		return nil
+	} else if err != nil {
+		return err
+	}
+	template := corev1.PodTemplateSpec{}
+	if err := json.Unmarshal(controllerRevision.Data.Raw, &template); err != nil {
+		return err
+	}
+	ds.Spec.Template = template
+	return nil
+}
+
+// reconcileUpgrade tracks the nodes still running a previous spec of the
+// daemonset. With the OnDelete strategy it restarts them one at a time, the
+// next node only gets restarted once all the pods passed the health gate.
+// With held nodes or maintenance windows, the operator also does the rolling
+// update, within the windows and skipping the held nodes.
+func reconcileUpgrade(c client.Client, log logr.Logger, recorder record.EventRecorder, owner operand.Object,
+	strategy *neutronv1beta1.UpgradeStrategy, gate rolloutGate, ds *appsv1.DaemonSet, templateHash string) (neutronv1beta1.UpgradeStatus, ctrl.Result, error) {
+
+	status := neutronv1beta1.UpgradeStatus{}
+	gate.status(&status)
+
+	pods := &corev1.PodList{}
+	if err := c.List(context.TODO(), pods, client.InNamespace(ds.Namespace),
+		client.MatchingLabels(ds.Spec.Selector.MatchLabels)); err != nil {
+		return status, ctrl.Result{}, err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Spec.NodeName < pods.Items[j].Spec.NodeName
+	})
+
+	outdated := []*corev1.Pod{}
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if common.IsPodOutdated(pod, templateHash) {
+			outdated = append(outdated, pod)
+			status.PendingNodes = append(status.PendingNodes, pod.Spec.NodeName)
+		}
+	}
+	onDelete := common.GetUpgradeStrategyType(strategy) == common.UpgradeStrategyOnDelete
+	if (!onDelete && !gate.restricted) || len(outdated) == 0 {
+		return status, ctrl.Result{}, nil
+	}
+	if !gate.open {
+		log.Info("Waiting for the maintenance window to upgrade", "Nodes", len(outdated), "NextWindow", gate.nextWindow)
+		return status, gate.waitForWindow(), nil
+	}
+	candidates := []*corev1.Pod{}
+	for _, pod := range outdated {
+		if !gate.isHeld(pod.Spec.NodeName) && pod.DeletionTimestamp.IsZero() {
+			candidates = append(candidates, pod)
+		}
+	}
+
+	// health gate: every node runs a pod which is ready for minReadySeconds
+	minReadySeconds := common.GetMinReadySeconds(strategy)
+	healthy := 0
+	for i := range pods.Items {
+		pod := &pods.Items[i]
+		if common.IsPodHealthy(pod, minReadySeconds, time.Now()) {
+			healthy++
+		} else {
+			status.UnhealthyNodes = append(status.UnhealthyNodes, pod.Spec.NodeName)
+		}
+	}
+	unavailable := len(pods.Items) - healthy
+	if missing := int(ds.Status.DesiredNumberScheduled) - len(pods.Items); missing > 0 {
+		unavailable += missing
+	}
+
+	// OnDelete restarts one node at a time, the rolling update up to maxUnavailable
+	allowed := 1 - unavailable
+	if !onDelete {
+		maxUnavailable := common.GetDaemonsetUpdateStrategy(strategy).RollingUpdate.MaxUnavailable
+		limit, err := intstr.GetValueFromIntOrPercent(maxUnavailable, int(ds.Status.DesiredNumberScheduled), true)
+		if err != nil {
+			return status, ctrl.Result{}, err
+		}
+		allowed = limit - unavailable
+	}
+	if len(candidates) == 0 {
+		return status, ctrl.Result{}, nil
+	}
+	if allowed <= 0 {
+		log.Info("Waiting for the pods to pass the health gate", "Healthy", healthy, "Desired", ds.Status.DesiredNumberScheduled,
+			"Unhealthy", status.UnhealthyNodes)
+		return status, ctrl.Result{RequeueAfter: time.Second * 10}, nil
+	}
+	if allowed > len(candidates) {
+		allowed = len(candidates)
+	}
+
+	for _, pod := range candidates[:allowed] {
+		log.Info("Restarting pod for upgrade", "Pod.Name", pod.Name, "Node", pod.Spec.NodeName)
+		if err := c.Delete(context.TODO(), pod); err != nil && !errors.IsNotFound(err) {
+			return status, ctrl.Result{}, err
+		}
+		recorder.Eventf(owner, corev1.EventTypeNormal, "UpgradingNode", "Restarting pod %s on node %s, %d nodes left",
+			pod.Name, pod.Spec.NodeName, len(outdated)-1)
+		status.CurrentNode = pod.Spec.NodeName
+	}
+	return status, ctrl.Result{RequeueAfter: time.Second * 10}, nil
+}
@@ -0,0 +1,340 @@
+/*
+
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testUpgradeLabel = "ovs-node-osp-daemonset"
+
+func testUpgradeDaemonSet(desired int32) *appsv1.DaemonSet {
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{Name: "ovs-node-osp", Namespace: testNamespace},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: map[string]string{"daemonset": testUpgradeLabel}},
+		},
+		Status: appsv1.DaemonSetStatus{DesiredNumberScheduled: desired},
+	}
+}
+
+// testUpgradePod is the pod of the node running the template, ready for an
+// hour unless not ready
+func testUpgradePod(node string, templateHash string, ready bool) *corev1.Pod {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        "ovs-node-osp-" + node,
+			Namespace:   testNamespace,
+			Labels:      map[string]string{"daemonset": testUpgradeLabel},
+			Annotations: map[string]string{common.TemplateHashAnnotation: templateHash},
+		},
+		Spec: corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Conditions: []corev1.PodCondition{{
+				Type:               corev1.PodReady,
+				Status:             status,
+				LastTransitionTime: metav1.NewTime(time.Now().Add(-time.Hour)),
+			}},
+		},
+	}
+}
+
+func newTestUpgradeClient(objs ...runtime.Object) client.Client {
+	if err := neutronv1beta1.AddToScheme(scheme.Scheme); err != nil {
+		panic(err)
+	}
+	return fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+}
+
+func podExists(t *testing.T, c client.Client, node string) bool {
+	t.Helper()
+	err := c.Get(context.TODO(), types.NamespacedName{Name: "ovs-node-osp-" + node, Namespace: testNamespace}, &corev1.Pod{})
+	if err != nil && !errors.IsNotFound(err) {
+		t.Fatal(err)
+	}
+	return err == nil
+}
+
+func TestReconcileUpgradeHealthGate(t *testing.T) {
+	strategy := &neutronv1beta1.UpgradeStrategy{Type: common.UpgradeStrategyOnDelete}
+	ds := testUpgradeDaemonSet(3)
+	c := newTestUpgradeClient(
+		testUpgradePod("worker-0", "new", false),
+		testUpgradePod("worker-1", "old", true),
+		testUpgradePod("worker-2", "old", true),
+	)
+	owner := testOVSNodeOsp()
+
+	status, result, err := reconcileUpgrade(c, ctrl.Log, record.NewFakeRecorder(10), owner, strategy, rolloutGate{open: true}, ds, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(status.UnhealthyNodes, []string{"worker-0"}) {
+		t.Errorf("unhealthy nodes = %v, want [worker-0]", status.UnhealthyNodes)
+	}
+	if status.CurrentNode != "" {
+		t.Errorf("current node = %q, no node got restarted", status.CurrentNode)
+	}
+	if !podExists(t, c, "worker-1") || !podExists(t, c, "worker-2") {
+		t.Error("a pod got restarted while the health gate is closed")
+	}
+	if result.RequeueAfter == 0 {
+		t.Error("no requeue while waiting for the health gate")
+	}
+
+	// once healthy, the next node gets restarted
+	c = newTestUpgradeClient(
+		testUpgradePod("worker-0", "new", true),
+		testUpgradePod("worker-1", "old", true),
+		testUpgradePod("worker-2", "old", true),
+	)
+	status, _, err = reconcileUpgrade(c, ctrl.Log, record.NewFakeRecorder(10), owner, strategy, rolloutGate{open: true}, ds, "new")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(status.UnhealthyNodes) != 0 || status.CurrentNode != "worker-1" {
+		t.Errorf("unhealthy nodes = %v and current node = %q, want none and worker-1", status.UnhealthyNodes, status.CurrentNode)
+	}
+	if podExists(t, c, "worker-1") || !podExists(t, c, "worker-2") {
+		t.Error("only the pod of worker-1 should have been restarted")
+	}
+}
+
+// testCanaryStrategy has worker-0 as canary node and a soak time of a minute
+func testCanaryStrategy() *neutronv1beta1.UpgradeStrategy {
+	var soakSeconds int32 = 60
+	return &neutronv1beta1.UpgradeStrategy{
+		Canary: &neutronv1beta1.CanarySpec{
+			NodeSelector: metav1.LabelSelector{MatchLabels: map[string]string{"canary": "true"}},
+			SoakSeconds:  &soakSeconds,
+		},
+	}
+}
+
+func testCanaryNode(name string, canary bool) *corev1.Node {
+	node := &corev1.Node{ObjectMeta: metav1.ObjectMeta{Name: name, Labels: map[string]string{}}}
+	if canary {
+		node.Labels["canary"] = "true"
+	}
+	return node
+}
+
+// testCanaryDaemonSet runs the template of the image, with the hash
+func testCanaryDaemonSet(image string, templateHash string) *appsv1.DaemonSet {
+	ds := testUpgradeDaemonSet(2)
+	ds.Spec.Template = corev1.PodTemplateSpec{
+		ObjectMeta: metav1.ObjectMeta{
+			Labels:      map[string]string{"daemonset": testUpgradeLabel},
+			Annotations: map[string]string{common.TemplateHashAnnotation: templateHash},
+		},
+		Spec: corev1.PodSpec{Containers: []corev1.Container{{Name: "ovs-node-osp", Image: image}}},
+	}
+	return ds
+}
+
+// testCanaryRevisions is the canary of img:2 after the complete rollout of img:1
+func testCanaryRevisions() []neutronv1beta1.DaemonsetRevision {
+	return []neutronv1beta1.DaemonsetRevision{
+		{Image: "img:2", TemplateHash: "new", Phase: common.RevisionPhaseCanary},
+		{Image: "img:1", TemplateHash: "old", Phase: common.RevisionPhaseComplete},
+	}
+}
+
+// testSoakedCanary has worker-0 running the new template for two minutes
+func testSoakedCanary() neutronv1beta1.UpgradeStatus {
+	start := metav1.NewTime(time.Now().Add(-2 * time.Minute))
+	return neutronv1beta1.UpgradeStatus{CanaryNodes: []string{"worker-0"}, CanaryStartTime: &start}
+}
+
+// testRevisionTemplate keeps the pod template of the daemonset of img:1
+func testRevisionTemplate(t *testing.T, owner *neutronv1beta1.OVSNodeOsp) *appsv1.ControllerRevision {
+	t.Helper()
+	ds := testCanaryDaemonSet("img:1", "old")
+	data, err := json.Marshal(ds.Spec.Template)
+	if err != nil {
+		t.Fatal(err)
+	}
+	isController := true
+	return &appsv1.ControllerRevision{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            revisionTemplateName(ds, "old"),
+			Namespace:       testNamespace,
+			Labels:          map[string]string{revisionTemplateLabel: ds.Name},
+			OwnerReferences: []metav1.OwnerReference{{Kind: "OVSNodeOsp", Name: owner.Name, UID: owner.UID, Controller: &isController}},
+		},
+		Data:     runtime.RawExtension{Raw: data},
+		Revision: 1,
+	}
+}
+
+func TestReconcileRolloutCanaryFailed(t *testing.T) {
+	owner := testOVSNodeOsp()
+	restarted := testUpgradePod("worker-0", "new", true)
+	restarted.Status.ContainerStatuses = []corev1.ContainerStatus{{Name: "ovs-node-osp", RestartCount: 1}}
+	c := newTestUpgradeClient(testCanaryNode("worker-0", true), testCanaryNode("worker-1", false),
+		restarted, testUpgradePod("worker-1", "old", true), testRevisionTemplate(t, owner))
+	recorder := record.NewFakeRecorder(10)
+
+	revisions, _, _, err := reconcileRollout(c, ctrl.Log, recorder, scheme.Scheme, owner, testCanaryStrategy(), rolloutGate{open: true},
+		testCanaryDaemonSet("img:2", "new"), "new", "img:2", nil, testCanaryRevisions(), testSoakedCanary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revisions[0].Phase != common.RevisionPhaseRolledBack {
+		t.Fatalf("canary revision phase = %s, want %s", revisions[0].Phase, common.RevisionPhaseRolledBack)
+	}
+	if !podExists(t, c, "worker-1") {
+		t.Error("the pod of a node outside of the canary got restarted")
+	}
+
+	// the next reconcile returns to the template of img:1, not only its image
+	ds := testCanaryDaemonSet("img:2", "new")
+	ds.Spec.Template.Spec.Containers[0].Args = []string{"--added-with-img-2"}
+	if err := restoreRevisionTemplate(c, ctrl.Log, ds, "img:2", revisions); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(ds.Spec.Template, testCanaryDaemonSet("img:1", "old").Spec.Template) {
+		t.Errorf("restored template %v, want the one of img:1", ds.Spec.Template)
+	}
+
+	// both templates are kept, the failed one to tell the revisions apart
+	for _, hash := range []string{"old", "new"} {
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: revisionTemplateName(ds, hash), Namespace: testNamespace},
+			&appsv1.ControllerRevision{}); err != nil {
+			t.Errorf("template %s: %v", hash, err)
+		}
+	}
+}
+
+func TestReconcileRolloutCanaryPassed(t *testing.T) {
+	owner := testOVSNodeOsp()
+	c := newTestUpgradeClient(testCanaryNode("worker-0", true), testCanaryNode("worker-1", false),
+		testUpgradePod("worker-0", "new", true), testUpgradePod("worker-1", "old", true))
+	recorder := record.NewFakeRecorder(10)
+
+	revisions, upgrade, _, err := reconcileRollout(c, ctrl.Log, recorder, scheme.Scheme, owner, testCanaryStrategy(), rolloutGate{open: true},
+		testCanaryDaemonSet("img:2", "new"), "new", "img:2", nil, testCanaryRevisions(), testSoakedCanary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revisions[0].Phase != common.RevisionPhaseRollingOut {
+		t.Fatalf("canary revision phase = %s, want %s", revisions[0].Phase, common.RevisionPhaseRollingOut)
+	}
+	if !reflect.DeepEqual(upgrade.CanaryNodes, []string{"worker-0"}) {
+		t.Errorf("canary nodes = %v, want [worker-0]", upgrade.CanaryNodes)
+	}
+	// the template rolling out is kept to return to it
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "ovs-node-osp-new", Namespace: testNamespace},
+		&appsv1.ControllerRevision{}); err != nil {
+		t.Errorf("template of the revision: %v", err)
+	}
+
+	// the remaining nodes follow without canary
+	if common.IsCanaryRollout(testCanaryStrategy(), "img:2", revisions) {
+		t.Error("the rollout to the remaining nodes is still a canary")
+	}
+}
+
+func TestReconcileRolloutCanaryRetry(t *testing.T) {
+	owner := testOVSNodeOsp()
+	c := newTestUpgradeClient(testCanaryNode("worker-0", true), testCanaryNode("worker-1", false),
+		testUpgradePod("worker-0", "old", true), testUpgradePod("worker-1", "old", true), testRevisionTemplate(t, owner))
+	recorder := record.NewFakeRecorder(10)
+	rolledBack := testCanaryRevisions()
+	rolledBack[0].Phase = common.RevisionPhaseRolledBack
+
+	// the failed image stays rolled back
+	if image := common.GetRolloutImage("img:2", rolledBack); image != "img:1" {
+		t.Errorf("rollout image = %s, want img:1", image)
+	}
+
+	// a new image gets its own canary
+	if !common.IsCanaryRollout(testCanaryStrategy(), "img:3", rolledBack) {
+		t.Fatal("a new image after a rollback goes without canary")
+	}
+	revisions, upgrade, _, err := reconcileRollout(c, ctrl.Log, recorder, scheme.Scheme, owner, testCanaryStrategy(), rolloutGate{open: true},
+		testCanaryDaemonSet("img:3", "newer"), "newer", "img:3", nil, rolledBack, testSoakedCanary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revisions) != 3 || revisions[0].Image != "img:3" || revisions[0].TemplateHash != "newer" ||
+		revisions[0].Phase != common.RevisionPhaseCanary {
+		t.Fatalf("revisions = %v, want a canary revision of img:3 on top", revisions)
+	}
+	if upgrade.CanaryStartTime != nil {
+		t.Error("the soak of the failed canary carried over")
+	}
+	if podExists(t, c, "worker-0") || !podExists(t, c, "worker-1") {
+		t.Error("only the canary node should have been restarted")
+	}
+}
+
+func TestReconcileRolloutCanaryChassis(t *testing.T) {
+	owner := &neutronv1beta1.OVNController{ObjectMeta: metav1.ObjectMeta{Name: "ovn-controller", Namespace: testNamespace}}
+	newClient := func() client.Client {
+		return newTestUpgradeClient(testCanaryNode("worker-0", true), testCanaryNode("worker-1", false),
+			testUpgradePod("worker-0", "new", true), testUpgradePod("worker-1", "old", true))
+	}
+	common.SetCondition(&owner.Status.Conditions, common.ConditionChassisRegistered, corev1.ConditionFalse, "Unregistered", "")
+	owner.Status.Chassis.Unregistered = []neutronv1beta1.UnregisteredChassis{{Node: "worker-0", Chassis: "worker-0", Reason: "Missing"}}
+
+	// a chassis check from before the soak does not count
+	owner.Status.Chassis.LastCheckTime = metav1.NewTime(time.Now().Add(-time.Hour))
+	revisions, _, result, err := reconcileRollout(newClient(), ctrl.Log, record.NewFakeRecorder(10), scheme.Scheme, owner,
+		testCanaryStrategy(), rolloutGate{open: true}, testCanaryDaemonSet("img:2", "new"), "new", "img:2",
+		chassisCanaryCheck(owner), testCanaryRevisions(), testSoakedCanary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revisions[0].Phase != common.RevisionPhaseCanary || result.RequeueAfter == 0 {
+		t.Errorf("phase = %s, requeue = %s, want to wait for a chassis check", revisions[0].Phase, result.RequeueAfter)
+	}
+
+	// the ready canary pod registered no chassis
+	owner.Status.Chassis.LastCheckTime = metav1.Now()
+	revisions, _, _, err = reconcileRollout(newClient(), ctrl.Log, record.NewFakeRecorder(10), scheme.Scheme, owner,
+		testCanaryStrategy(), rolloutGate{open: true}, testCanaryDaemonSet("img:2", "new"), "new", "img:2",
+		chassisCanaryCheck(owner), testCanaryRevisions(), testSoakedCanary())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if revisions[0].Phase != common.RevisionPhaseRolledBack {
+		t.Errorf("phase = %s, want %s", revisions[0].Phase, common.RevisionPhaseRolledBack)
+	}
+}
@@ -9,6 +9,8 @@ require (
 	github.com/onsi/gomega v1.8.1
 	github.com/openstack-k8s-operators/lib-common v0.0.0-20200511145352-a17ab43c6b58
 	github.com/operator-framework/operator-lifecycle-manager v0.0.0-20200321030439-57b580e57e88
+	github.com/prometheus/client_golang v1.2.1
+	github.com/robfig/cron/v3 v3.0.1
 	golang.org/x/lint v0.0.0-20200302205851-738671d3881b // indirect
 	golang.org/x/tools v0.0.0-20200831203904-5a2aa26beb65 // indirect
 	k8s.io/api v0.18.2
@@ -0,0 +1,100 @@
+package common
+
+import (
+	"crypto/sha256"
+	"fmt"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ChassisCleanupFinalizer - keeps the CR until its nodes got cleaned up
+const ChassisCleanupFinalizer string = "neutron.openstack.org/chassis-cleanup"
+
+// HasFinalizer - checks if the object has the finalizer
+func HasFinalizer(o metav1.Object, finalizer string) bool {
+	for _, f := range o.GetFinalizers() {
+		if f == finalizer {
+			return true
+		}
+	}
+	return false
+}
+
+// GetCleanupJobName - name of the cleanup job of a node
+func GetCleanupJobName(crName string, nodeName string) string {
+	name := fmt.Sprintf("%s-cleanup-%s", crName, nodeName)
+	// job names end up in the job-name label, which is limited to 63 characters
+	if len(name) > 63 {
+		name = fmt.Sprintf("%s-cleanup-%x", crName, sha256.Sum256([]byte(nodeName)))[:63]
+	}
+	return name
+}
+
+// CleanupJob - job running /usr/local/sbin/cleanup.sh on the node
+func CleanupJob(name string, namespace string, nodeName string, image string, serviceAccount string,
+	env []corev1.EnvVar, volumes []corev1.Volume, volumeMounts []corev1.VolumeMount) *batchv1.Job {
+	var trueVar = true
+	var backoffLimit int32 = 3
+
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "Job",
+			APIVersion: "batch/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: &backoffLimit,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					NodeName:           nodeName,
+					RestartPolicy:      corev1.RestartPolicyOnFailure,
+					HostNetwork:        true,
+					HostPID:            true,
+					DNSPolicy:          "ClusterFirstWithHostNet",
+					ServiceAccountName: serviceAccount,
+					Tolerations:        GetComputeWorkerTolerations(""),
+					Containers: []corev1.Container{
+						{
+							Name:  "cleanup",
+							Image: image,
+							Command: []string{
+								"bash", "-c", "/usr/local/sbin/cleanup.sh",
+							},
+							SecurityContext: &corev1.SecurityContext{
+								Privileged: &trueVar,
+							},
+							Env:          env,
+							VolumeMounts: volumeMounts,
+						},
+					},
+					Volumes: volumes,
+				},
+			},
+		},
+	}
+}
+
+// IsJobComplete - checks if the job finished successfully
+func IsJobComplete(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobComplete && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
+
+// IsJobFailed - checks if the job failed
+func IsJobFailed(job *batchv1.Job) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == batchv1.JobFailed && condition.Status == corev1.ConditionTrue {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package common
+
+import (
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ConditionReferencesResolved - condition telling the ConfigMaps and Secrets
+// the pods read env vars from exist
+const ConditionReferencesResolved string = "ReferencesResolved"
+
+// ConditionNamingConsistent - condition telling the OVSNodeOsp and
+// OVNController of the role use the same naming scheme
+const ConditionNamingConsistent string = "NamingConsistent"
+
+// ConditionHugepagesAvailable - condition telling all nodes of the role have
+// enough hugepages for DPDK
+const ConditionHugepagesAvailable string = "HugepagesAvailable"
+
+// ConditionChassisRegistered - condition telling every node of the role has a
+// matching chassis in the SB DB and no chassis got left behind
+const ConditionChassisRegistered string = "ChassisRegistered"
+
+// ConditionSpecValid - condition telling the spec passed the checks which can
+// not be expressed in the CRD validation
+const ConditionSpecValid string = "SpecValid"
+
+// ConditionTunnelDegraded - condition telling some nodes of the role can not
+// reach the encap IPs of others, or have BFD down on the tunnels to them
+const ConditionTunnelDegraded string = "TunnelDegraded"
+
+// ConditionBridgeUnique - condition telling no other OVSBridge of the role
+// declares the same bridge
+const ConditionBridgeUnique string = "BridgeUnique"
+
+// GetCondition - returns the condition of the type, nil if not set
+func GetCondition(conditions []neutronv1.Condition, conditionType string) *neutronv1.Condition {
+	for i := range conditions {
+		if conditions[i].Type == conditionType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue - checks if the condition of the type is set and true
+func IsConditionTrue(conditions []neutronv1.Condition, conditionType string) bool {
+	condition := GetCondition(conditions, conditionType)
+	return condition != nil && condition.Status == corev1.ConditionTrue
+}
+
+// SetCondition - sets the condition of the type, the transition time only
+// changes with the status. Returns true if the condition changed.
+func SetCondition(conditions *[]neutronv1.Condition, conditionType string, status corev1.ConditionStatus, reason string, message string) bool {
+	condition := GetCondition(*conditions, conditionType)
+	if condition == nil {
+		*conditions = append(*conditions, neutronv1.Condition{
+			Type:               conditionType,
+			Status:             status,
+			Reason:             reason,
+			Message:            message,
+			LastTransitionTime: metav1.Now(),
+		})
+		return true
+	}
+	if condition.Status == status && condition.Reason == reason && condition.Message == message {
+		return false
+	}
+	if condition.Status != status {
+		condition.LastTransitionTime = metav1.Now()
+	}
+	condition.Status = status
+	condition.Reason = reason
+	condition.Message = message
+	return true
+}
+
+// RemoveCondition - removes the condition of the type. Returns true if it was set.
+func RemoveCondition(conditions *[]neutronv1.Condition, conditionType string) bool {
+	for i := range *conditions {
+		if (*conditions)[i].Type == conditionType {
+			*conditions = append((*conditions)[:i], (*conditions)[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
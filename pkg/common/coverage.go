@@ -0,0 +1,191 @@
+package common
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Reasons of the nodes without a ready daemon pod
+const (
+	// NodeReasonNoPod - the node has no daemon pod
+	NodeReasonNoPod string = "NoPod"
+	// NodeReasonTainted - the node has no daemon pod as it has a taint not tolerated by the daemon
+	NodeReasonTainted string = "Tainted"
+	// NodeReasonNodeNotReady - the node itself is not ready
+	NodeReasonNodeNotReady string = "NodeNotReady"
+	// NodeReasonUnschedulable - the pod can not be scheduled to the node
+	NodeReasonUnschedulable string = "Unschedulable"
+	// NodeReasonPodNotReady - the pod is not ready
+	NodeReasonPodNotReady string = "PodNotReady"
+)
+
+// daemonSetTolerations - tolerations the DaemonSet controller adds to the
+// daemon pods, the ones of the host network pods included as all our daemons
+// use the host network
+var daemonSetTolerations = []corev1.Toleration{
+	{Key: corev1.TaintNodeNotReady, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeUnreachable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoExecute},
+	{Key: corev1.TaintNodeDiskPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeMemoryPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodePIDPressure, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeUnschedulable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+	{Key: corev1.TaintNodeNetworkUnavailable, Operator: corev1.TolerationOpExists, Effect: corev1.TaintEffectNoSchedule},
+}
+
+// GetNodeCoverage - compares the nodes of the role with the nodes running a
+// ready pod of the daemonset, tolerations are the ones of the daemonset pod
+// template. A node which is not ready is reported as such, even if it has no
+// pod because of a taint.
+func GetNodeCoverage(nodes []corev1.Node, pods []corev1.Pod, tolerations []corev1.Toleration) neutronv1.NodeCoverage {
+	tolerations = append(append([]corev1.Toleration{}, tolerations...), daemonSetTolerations...)
+	nodePods := map[string]*corev1.Pod{}
+	for i := range pods {
+		pod := &pods[i]
+		// a terminating pod might get replaced already
+		if current, ok := nodePods[pod.Spec.NodeName]; ok && pod.DeletionTimestamp != nil && current.DeletionTimestamp == nil {
+			continue
+		}
+		nodePods[GetPodNodeName(pod)] = pod
+	}
+
+	coverage := neutronv1.NodeCoverage{Expected: int32(len(nodes))}
+	for i := range nodes {
+		node := &nodes[i]
+		pod, ok := nodePods[node.Name]
+		if ok && isPodReady(pod) {
+			coverage.Ready++
+			continue
+		}
+
+		unhealthy := neutronv1.UnhealthyNode{Node: node.Name}
+		if !isNodeReady(node) {
+			unhealthy.Reason = NodeReasonNodeNotReady
+			unhealthy.Message = "node is not ready"
+		}
+		if !ok {
+			// the taints of a node which is not ready are no cause
+			taint := getUntoleratedTaint(node, tolerations)
+			switch {
+			case unhealthy.Reason != "":
+			case taint != nil:
+				unhealthy.Reason = NodeReasonTainted
+				unhealthy.Message = fmt.Sprintf("taint %s=%s:%s is not tolerated", taint.Key, taint.Value, taint.Effect)
+			default:
+				unhealthy.Reason = NodeReasonNoPod
+				unhealthy.Message = "no daemon pod on the node"
+			}
+			coverage.Unhealthy = append(coverage.Unhealthy, unhealthy)
+			continue
+		}
+
+		unhealthy.PodPhase = pod.Status.Phase
+		unhealthy.Restarts = GetPodRestarts(pod)
+		unhealthy.LastRestartReason = getLastRestartReason(pod)
+		if unhealthy.Reason == "" {
+			unhealthy.Reason = NodeReasonPodNotReady
+			unhealthy.Message = getPodNotReadyMessage(pod)
+			for _, condition := range pod.Status.Conditions {
+				if condition.Type == corev1.PodScheduled && condition.Status == corev1.ConditionFalse {
+					unhealthy.Reason = NodeReasonUnschedulable
+					unhealthy.Message = condition.Message
+				}
+			}
+		}
+		coverage.Unhealthy = append(coverage.Unhealthy, unhealthy)
+	}
+
+	sort.Slice(coverage.Unhealthy, func(i, j int) bool {
+		return coverage.Unhealthy[i].Node < coverage.Unhealthy[j].Node
+	})
+	return coverage
+}
+
+// GetPodNodeName - returns the node of a daemon pod. A pod which could not be
+// scheduled has no node name yet, the DaemonSet controller targets its node
+// with a required node affinity on the metadata.name field.
+func GetPodNodeName(pod *corev1.Pod) string {
+	if pod.Spec.NodeName != "" || pod.Spec.Affinity == nil || pod.Spec.Affinity.NodeAffinity == nil {
+		return pod.Spec.NodeName
+	}
+	required := pod.Spec.Affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution
+	if required == nil {
+		return ""
+	}
+	for _, term := range required.NodeSelectorTerms {
+		for _, field := range term.MatchFields {
+			if field.Key == "metadata.name" && field.Operator == corev1.NodeSelectorOpIn && len(field.Values) == 1 {
+				return field.Values[0]
+			}
+		}
+	}
+	return ""
+}
+
+func isPodReady(pod *corev1.Pod) bool {
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// getUntoleratedTaint returns a taint of the node keeping the daemon pods away
+func getUntoleratedTaint(node *corev1.Node, tolerations []corev1.Toleration) *corev1.Taint {
+	for i := range node.Spec.Taints {
+		taint := &node.Spec.Taints[i]
+		if taint.Effect != corev1.TaintEffectNoSchedule && taint.Effect != corev1.TaintEffectNoExecute {
+			continue
+		}
+		tolerated := false
+		for j := range tolerations {
+			if tolerations[j].ToleratesTaint(taint) {
+				tolerated = true
+				break
+			}
+		}
+		if !tolerated {
+			return taint
+		}
+	}
+	return nil
+}
+
+// getLastRestartReason returns why a container of the pod last terminated
+func getLastRestartReason(pod *corev1.Pod) string {
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		if terminated := containerStatus.LastTerminationState.Terminated; terminated != nil && terminated.Reason != "" {
+			return terminated.Reason
+		}
+	}
+	return ""
+}
+
+// getPodNotReadyMessage returns why the containers of the pod are not ready,
+// like CrashLoopBackOff or ImagePullBackOff
+func getPodNotReadyMessage(pod *corev1.Pod) string {
+	reasons := []string{}
+	containerStatuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+	for _, containerStatus := range containerStatuses {
+		if waiting := containerStatus.State.Waiting; waiting != nil && waiting.Reason != "" {
+			reasons = append(reasons, containerStatus.Name+": "+waiting.Reason)
+		}
+	}
+	if len(reasons) == 0 {
+		return "pod is " + strings.ToLower(string(pod.Status.Phase)) + " and not ready"
+	}
+	return strings.Join(reasons, ", ")
+}
@@ -0,0 +1,127 @@
+package common
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testNode(name string, ready bool, taints ...corev1.Taint) corev1.Node {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: name},
+		Spec:       corev1.NodeSpec{Taints: taints},
+		Status:     corev1.NodeStatus{Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: status}}},
+	}
+}
+
+func testPod(node string, ready bool) corev1.Pod {
+	status := corev1.ConditionTrue
+	if !ready {
+		status = corev1.ConditionFalse
+	}
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-" + node},
+		Spec:       corev1.PodSpec{NodeName: node},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: status}},
+		},
+	}
+}
+
+// testUnschedulablePod is a daemon pod the scheduler could not place on its
+// node, it only targets the node by its affinity
+func testUnschedulablePod(node string) corev1.Pod {
+	return corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-" + node},
+		Spec: corev1.PodSpec{
+			Affinity: &corev1.Affinity{
+				NodeAffinity: &corev1.NodeAffinity{
+					RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+						NodeSelectorTerms: []corev1.NodeSelectorTerm{{
+							MatchFields: []corev1.NodeSelectorRequirement{{
+								Key:      "metadata.name",
+								Operator: corev1.NodeSelectorOpIn,
+								Values:   []string{node},
+							}},
+						}},
+					},
+				},
+			},
+		},
+		Status: corev1.PodStatus{
+			Phase: corev1.PodPending,
+			Conditions: []corev1.PodCondition{{
+				Type:    corev1.PodScheduled,
+				Status:  corev1.ConditionFalse,
+				Reason:  corev1.PodReasonUnschedulable,
+				Message: "0/3 nodes are available: 1 Insufficient hugepages-1Gi.",
+			}},
+		},
+	}
+}
+
+func TestGetNodeCoverage(t *testing.T) {
+	noSchedule := func(key string) corev1.Taint {
+		return corev1.Taint{Key: key, Effect: corev1.TaintEffectNoSchedule}
+	}
+	noExecute := func(key string) corev1.Taint {
+		return corev1.Taint{Key: key, Effect: corev1.TaintEffectNoExecute}
+	}
+	dedicated := corev1.Toleration{Key: "dedicated", Operator: corev1.TolerationOpExists}
+
+	tests := []struct {
+		name        string
+		node        corev1.Node
+		pods        []corev1.Pod
+		tolerations []corev1.Toleration
+		reason      string
+	}{
+		{name: "ready", node: testNode("n", true), pods: []corev1.Pod{testPod("n", true)}},
+		{name: "no pod", node: testNode("n", true), reason: NodeReasonNoPod},
+		{name: "pod not ready", node: testNode("n", true), pods: []corev1.Pod{testPod("n", false)}, reason: NodeReasonPodNotReady},
+		{name: "tainted", node: testNode("n", true, noSchedule("dedicated")), reason: NodeReasonTainted},
+		{name: "tolerated taint", node: testNode("n", true, noSchedule("dedicated")),
+			tolerations: []corev1.Toleration{dedicated}, reason: NodeReasonNoPod},
+		{name: "PreferNoSchedule taint", node: testNode("n", true,
+			corev1.Taint{Key: "dedicated", Effect: corev1.TaintEffectPreferNoSchedule}), reason: NodeReasonNoPod},
+		{name: "not ready", node: testNode("n", false), reason: NodeReasonNodeNotReady},
+		{name: "not ready with the not-ready taint", node: testNode("n", false,
+			noExecute(corev1.TaintNodeNotReady), noSchedule(corev1.TaintNodeNotReady)), reason: NodeReasonNodeNotReady},
+		{name: "not ready with the unreachable taint", node: testNode("n", false,
+			noExecute(corev1.TaintNodeUnreachable), noSchedule(corev1.TaintNodeUnreachable)), reason: NodeReasonNodeNotReady},
+		{name: "not ready with another taint", node: testNode("n", false, noSchedule("dedicated")), reason: NodeReasonNodeNotReady},
+		{name: "not ready with a pod", node: testNode("n", false), pods: []corev1.Pod{testPod("n", false)}, reason: NodeReasonNodeNotReady},
+		{name: "cordoned", node: testNode("n", true, noSchedule(corev1.TaintNodeUnschedulable)), reason: NodeReasonNoPod},
+		{name: "disk pressure", node: testNode("n", true, noSchedule(corev1.TaintNodeDiskPressure)), reason: NodeReasonNoPod},
+		{name: "unschedulable", node: testNode("n", true), pods: []corev1.Pod{testUnschedulablePod("n")}, reason: NodeReasonUnschedulable},
+		{name: "unschedulable on another node", node: testNode("n", true), pods: []corev1.Pod{testUnschedulablePod("m")}, reason: NodeReasonNoPod},
+		{name: "network unavailable", node: testNode("n", true, noSchedule(corev1.TaintNodeNetworkUnavailable)), reason: NodeReasonNoPod},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			coverage := GetNodeCoverage([]corev1.Node{test.node}, test.pods, test.tolerations)
+			if coverage.Expected != 1 {
+				t.Errorf("expected: got %d, want 1", coverage.Expected)
+			}
+			if test.reason == "" {
+				if coverage.Ready != 1 || len(coverage.Unhealthy) != 0 {
+					t.Errorf("got %+v, want the node ready", coverage)
+				}
+				return
+			}
+			if coverage.Ready != 0 || len(coverage.Unhealthy) != 1 {
+				t.Fatalf("got %+v, want the node unhealthy", coverage)
+			}
+			if reason := coverage.Unhealthy[0].Reason; reason != test.reason {
+				t.Errorf("reason: got %s (%s), want %s", reason, coverage.Unhealthy[0].Message, test.reason)
+			}
+		})
+	}
+}
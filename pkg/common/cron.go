@@ -0,0 +1,59 @@
+package common
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/robfig/cron/v3"
+)
+
+// ParseCronSchedule - parses a five field schedule like "30 2 * * 1-5" or
+// "0 2 * * SAT". Fields support *, values, names of months and days of the
+// week, ranges, lists and steps. Sunday is 0 or 7.
+func ParseCronSchedule(spec string) (cron.Schedule, error) {
+	fields := strings.Fields(spec)
+	if len(fields) == 5 {
+		fields[4] = normalizeSunday(fields[4])
+	}
+	schedule, err := cron.ParseStandard(strings.Join(fields, " "))
+	if err != nil {
+		return nil, fmt.Errorf("invalid schedule %q: %v", spec, err)
+	}
+	return schedule, nil
+}
+
+// normalizeSunday - rewrites 7 in the day-of-week field to 0, the parser
+// only knows 0-6
+func normalizeSunday(field string) string {
+	parts := strings.Split(field, ",")
+	for i, part := range parts {
+		values, step := part, ""
+		if j := strings.Index(part, "/"); j >= 0 {
+			values, step = part[:j], part[j:]
+		}
+		if values == "7" {
+			parts[i] = "0" + step
+			continue
+		}
+		if !strings.HasSuffix(values, "-7") {
+			continue
+		}
+		low, err := strconv.Atoi(strings.TrimSuffix(values, "-7"))
+		if err != nil {
+			continue
+		}
+		parts[i] = fmt.Sprintf("%d-6%s", low, step)
+		// sunday is in the range if the steps hit 7
+		stepSize := 1
+		if step != "" {
+			if stepSize, err = strconv.Atoi(step[1:]); err != nil || stepSize < 1 {
+				continue
+			}
+		}
+		if (7-low)%stepSize == 0 {
+			parts[i] += ",0"
+		}
+	}
+	return strings.Join(parts, ",")
+}
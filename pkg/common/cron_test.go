@@ -0,0 +1,152 @@
+package common
+
+import (
+	"testing"
+	"time"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func mustTime(t *testing.T, value string) time.Time {
+	t.Helper()
+	parsed, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return parsed
+}
+
+func TestParseCronSchedule(t *testing.T) {
+	tests := []struct {
+		name  string
+		spec  string
+		after string
+		next  string
+		err   bool
+	}{
+		{name: "every minute", spec: "* * * * *", after: "2020-06-01T10:00:30Z", next: "2020-06-01T10:01:00Z"},
+		{name: "value", spec: "30 2 * * *", after: "2020-06-01T10:00:00Z", next: "2020-06-02T02:30:00Z"},
+		{name: "range of days", spec: "0 2 * * 1-5", after: "2020-06-05T03:00:00Z", next: "2020-06-08T02:00:00Z"},
+		{name: "list", spec: "0 2,14 * * *", after: "2020-06-01T03:00:00Z", next: "2020-06-01T14:00:00Z"},
+		{name: "step", spec: "*/15 * * * *", after: "2020-06-01T10:16:00Z", next: "2020-06-01T10:30:00Z"},
+		{name: "day name", spec: "0 2 * * SAT", after: "2020-06-01T00:00:00Z", next: "2020-06-06T02:00:00Z"},
+		{name: "day name range", spec: "0 2 * * MON-FRI", after: "2020-06-06T00:00:00Z", next: "2020-06-08T02:00:00Z"},
+		{name: "month name", spec: "0 0 1 JAN *", after: "2020-06-01T00:00:00Z", next: "2021-01-01T00:00:00Z"},
+		{name: "sunday as 7", spec: "0 0 * * 7", after: "2020-06-01T00:00:00Z", next: "2020-06-07T00:00:00Z"},
+		{name: "range up to 7", spec: "0 0 * * 6-7", after: "2020-06-06T12:00:00Z", next: "2020-06-07T00:00:00Z"},
+		{name: "stepped range up to 7", spec: "0 0 * * 1-7/2", after: "2020-06-06T00:00:00Z", next: "2020-06-07T00:00:00Z"},
+		{name: "stepped range missing 7", spec: "0 0 * * 2-7/2", after: "2020-06-06T00:00:00Z", next: "2020-06-09T00:00:00Z"},
+		{name: "day of month or day of week", spec: "0 0 15 * MON", after: "2020-06-09T00:00:00Z", next: "2020-06-15T00:00:00Z"},
+		{name: "too few fields", spec: "0 2 * *", err: true},
+		{name: "too many fields", spec: "0 0 2 * * *", err: true},
+		{name: "out of range", spec: "60 * * * *", err: true},
+		{name: "reversed range", spec: "0 5-2 * * *", err: true},
+		{name: "invalid step", spec: "*/0 * * * *", err: true},
+		{name: "invalid name", spec: "0 0 * * FOO", err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			schedule, err := ParseCronSchedule(test.spec)
+			if test.err {
+				if err == nil {
+					t.Fatalf("expected an error for %q", test.spec)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			next := schedule.Next(mustTime(t, test.after))
+			if !next.Equal(mustTime(t, test.next)) {
+				t.Errorf("next activation after %s: got %s, want %s", test.after, next.Format(time.RFC3339), test.next)
+			}
+		})
+	}
+}
+
+func TestInMaintenanceWindow(t *testing.T) {
+	window := func(schedule string, duration time.Duration) neutronv1.MaintenanceWindow {
+		return neutronv1.MaintenanceWindow{Schedule: schedule, Duration: metav1.Duration{Duration: duration}}
+	}
+
+	tests := []struct {
+		name    string
+		windows []neutronv1.MaintenanceWindow
+		now     string
+		open    bool
+		next    string
+	}{
+		{name: "no windows", now: "2020-06-06T12:00:00Z", open: true},
+		{name: "at the start", windows: []neutronv1.MaintenanceWindow{window("0 2 * * SAT", 2*time.Hour)},
+			now: "2020-06-06T02:00:00Z", open: true},
+		{name: "within", windows: []neutronv1.MaintenanceWindow{window("0 2 * * SAT", 2*time.Hour)},
+			now: "2020-06-06T03:59:59Z", open: true},
+		{name: "at the end", windows: []neutronv1.MaintenanceWindow{window("0 2 * * SAT", 2*time.Hour)},
+			now: "2020-06-06T04:00:00Z", next: "2020-06-13T02:00:00Z"},
+		{name: "before", windows: []neutronv1.MaintenanceWindow{window("0 2 * * SAT", 2*time.Hour)},
+			now: "2020-06-06T01:59:00Z", next: "2020-06-06T02:00:00Z"},
+		{name: "spanning midnight", windows: []neutronv1.MaintenanceWindow{window("0 22 * * FRI", 6*time.Hour)},
+			now: "2020-06-06T03:00:00Z", open: true},
+		{name: "spanning days", windows: []neutronv1.MaintenanceWindow{window("0 0 * * SAT", 48*time.Hour)},
+			now: "2020-06-07T23:00:00Z", open: true},
+		{name: "earliest next window", windows: []neutronv1.MaintenanceWindow{
+			window("0 2 * * SAT", time.Hour), window("0 2 * * WED", time.Hour)},
+			now: "2020-06-01T12:00:00Z", next: "2020-06-03T02:00:00Z"},
+		{name: "second window open", windows: []neutronv1.MaintenanceWindow{
+			window("0 2 * * SAT", time.Hour), window("0 12 * * MON", time.Hour)},
+			now: "2020-06-01T12:30:00Z", open: true},
+		{name: "monthly", windows: []neutronv1.MaintenanceWindow{window("0 0 1 * *", time.Hour)},
+			now: "2020-06-02T00:00:00Z", next: "2020-07-01T00:00:00Z"},
+		{name: "never starts", windows: []neutronv1.MaintenanceWindow{window("0 0 30 FEB *", time.Hour)},
+			now: "2020-06-02T00:00:00Z"},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			strategy := &neutronv1.UpgradeStrategy{MaintenanceWindows: test.windows}
+			open, next := InMaintenanceWindow(strategy, mustTime(t, test.now))
+			if open != test.open {
+				t.Errorf("open: got %t, want %t", open, test.open)
+			}
+			want := time.Time{}
+			if test.next != "" {
+				want = mustTime(t, test.next)
+			}
+			if !next.Equal(want) {
+				t.Errorf("next window: got %s, want %s", next, want)
+			}
+		})
+	}
+}
+
+func TestValidateMaintenanceWindows(t *testing.T) {
+	tests := []struct {
+		name     string
+		schedule string
+		duration time.Duration
+		err      bool
+	}{
+		{name: "valid", schedule: "0 2 * * SAT", duration: time.Hour},
+		{name: "invalid schedule", schedule: "0 2 * *", duration: time.Hour, err: true},
+		{name: "never starts", schedule: "0 0 30 2 *", duration: time.Hour, err: true},
+		{name: "no duration", schedule: "0 2 * * *", err: true},
+		{name: "too long", schedule: "0 2 * * *", duration: 8 * 24 * time.Hour, err: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			strategy := &neutronv1.UpgradeStrategy{MaintenanceWindows: []neutronv1.MaintenanceWindow{
+				{Schedule: test.schedule, Duration: metav1.Duration{Duration: test.duration}},
+			}}
+			err := ValidateMaintenanceWindows(strategy)
+			if test.err && err == nil {
+				t.Error("expected an error")
+			}
+			if !test.err && err != nil {
+				t.Errorf("unexpected error: %v", err)
+			}
+		})
+	}
+}
@@ -0,0 +1,12 @@
+package common
+
+// DefaultCommonConfigMap - ConfigMap with the /etc/hosts entries of the OSP environment
+const DefaultCommonConfigMap string = "common-config"
+
+// GetCommonConfigMap - returns the common config map name, or the default if not set
+func GetCommonConfigMap(name string) string {
+	if name == "" {
+		return DefaultCommonConfigMap
+	}
+	return name
+}
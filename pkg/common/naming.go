@@ -0,0 +1,66 @@
+package common
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Defaults of the OVN naming scheme shared by OVSNodeOsp and OVNController
+const (
+	// DefaultIntegrationBridge - integration bridge used by ovn-controller
+	DefaultIntegrationBridge string = "br-int-osp"
+	// DefaultChassisNamePattern - chassis name, used as suffix of the ovn external-ids
+	DefaultChassisNamePattern string = HostnamePlaceholder + "-osp"
+	// HostnamePlaceholder - replaced with the node name in the chassis name
+	// pattern, the kernel hostname of the node is not used
+	HostnamePlaceholder string = "{hostname}"
+)
+
+// GetIntegrationBridge - returns the integration bridge name, or the default if not set
+func GetIntegrationBridge(bridge string) string {
+	if bridge == "" {
+		return DefaultIntegrationBridge
+	}
+	return bridge
+}
+
+// GetChassisNamePattern - returns the chassis name pattern, or the default if not set
+func GetChassisNamePattern(pattern string) string {
+	if pattern == "" {
+		return DefaultChassisNamePattern
+	}
+	return pattern
+}
+
+// GetChassisName - returns the chassis name of a node for the given pattern
+func GetChassisName(pattern string, nodeName string) string {
+	return strings.Replace(GetChassisNamePattern(pattern), HostnamePlaceholder, nodeName, -1)
+}
+
+// ValidateNaming - checks that the OVSNodeOsp and OVNController of a role agree on the naming scheme
+func ValidateNaming(ovsBridge string, ovsPattern string, ovnBridge string, ovnPattern string) error {
+	if GetIntegrationBridge(ovsBridge) != GetIntegrationBridge(ovnBridge) {
+		return fmt.Errorf("integration bridge mismatch: OVSNodeOsp uses %s, OVNController uses %s",
+			GetIntegrationBridge(ovsBridge), GetIntegrationBridge(ovnBridge))
+	}
+	if GetChassisNamePattern(ovsPattern) != GetChassisNamePattern(ovnPattern) {
+		return fmt.Errorf("chassis name pattern mismatch: OVSNodeOsp uses %s, OVNController uses %s",
+			GetChassisNamePattern(ovsPattern), GetChassisNamePattern(ovnPattern))
+	}
+	return nil
+}
+
+// GetChassisHostname - returns the node name of a chassis name of the given
+// pattern, false if the chassis name does not follow the pattern
+func GetChassisHostname(pattern string, chassisName string) (string, bool) {
+	parts := strings.Split(GetChassisNamePattern(pattern), HostnamePlaceholder)
+	for i := range parts {
+		parts[i] = regexp.QuoteMeta(parts[i])
+	}
+	match := regexp.MustCompile("^" + strings.Join(parts, "(.+)") + "$").FindStringSubmatch(chassisName)
+	if len(match) < 2 || GetChassisName(pattern, match[1]) != chassisName {
+		return "", false
+	}
+	return match[1], true
+}
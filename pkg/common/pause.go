@@ -0,0 +1,19 @@
+package common
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PausedAnnotation - while set, the operator does not change the objects of the
+// instance but keeps its status up to date. Deleting the instance still cleans
+// up the nodes.
+const PausedAnnotation string = "neutron.openstack.org/paused"
+
+// ConditionPaused - condition telling the reconcile of the instance is paused
+const ConditionPaused string = "Paused"
+
+// IsPaused - checks if the reconcile of the instance is paused, any value but "false" pauses
+func IsPaused(o metav1.Object) bool {
+	value, ok := o.GetAnnotations()[PausedAnnotation]
+	return ok && value != "false"
+}
@@ -0,0 +1,187 @@
+package common
+
+import (
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ReportKeySeparator - separates the pod name from the report key in the keys
+// of the report ConfigMap, pod names never contain it
+const ReportKeySeparator string = "_"
+
+// ReportConfigMapName - name of the ConfigMap the pods of an instance write their reports to
+func ReportConfigMapName(name string) string {
+	return name + "-reports"
+}
+
+// ReportConfigMap - ConfigMap the pods of an instance write their reports to
+func ReportConfigMap(namespace string, name string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ReportConfigMapName(name),
+			Namespace: namespace,
+		},
+		Data: map[string]string{},
+	}
+}
+
+// GetReportEnvVars - env vars needed by report.sh
+func GetReportEnvVars(configMap string) []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{
+			Name:  "REPORT_CONFIGMAP",
+			Value: configMap,
+		},
+		{
+			Name: "POD_NAME",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.name",
+				},
+			},
+		},
+		{
+			Name: "POD_NAMESPACE",
+			ValueFrom: &corev1.EnvVarSource{
+				FieldRef: &corev1.ObjectFieldSelector{
+					FieldPath: "metadata.namespace",
+				},
+			},
+		},
+	}
+}
+
+// GetNodeReports - returns the reports of the pods from the report ConfigMap,
+// keyed by node name and report key. Reports of other pods are ignored.
+func GetNodeReports(pods []corev1.Pod, configMap *corev1.ConfigMap) map[string]map[string]string {
+	nodes := map[string]string{}
+	for _, pod := range pods {
+		// a terminating pod might get replaced already
+		if pod.Spec.NodeName != "" && pod.DeletionTimestamp == nil {
+			nodes[pod.Name] = pod.Spec.NodeName
+		}
+	}
+
+	reports := map[string]map[string]string{}
+	if configMap == nil {
+		return reports
+	}
+	for key, value := range configMap.Data {
+		parts := strings.SplitN(key, ReportKeySeparator, 2)
+		if len(parts) != 2 {
+			continue
+		}
+		node, ok := nodes[parts[0]]
+		if !ok {
+			continue
+		}
+		if _, ok := reports[node]; !ok {
+			reports[node] = map[string]string{}
+		}
+		reports[node][parts[1]] = value
+	}
+	return reports
+}
+
+// GetStaleReports - returns the keys of the report ConfigMap written by pods
+// which no longer exist
+func GetStaleReports(pods []corev1.Pod, configMap *corev1.ConfigMap) []string {
+	names := map[string]bool{}
+	for _, pod := range pods {
+		names[pod.Name] = true
+	}
+	stale := []string{}
+	for key := range configMap.Data {
+		if !names[strings.SplitN(key, ReportKeySeparator, 2)[0]] {
+			stale = append(stale, key)
+		}
+	}
+	sort.Strings(stale)
+	return stale
+}
+
+// ReportRole - role allowing the pods to write to the report ConfigMap only
+func ReportRole(namespace string, name string, configMap string) *rbacv1.Role {
+	return &rbacv1.Role{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"configmaps"},
+				ResourceNames: []string{configMap},
+				Verbs:         []string{"get", "patch"},
+			},
+		},
+	}
+}
+
+// ReportRoleBinding - binds the report role to the service account of the pods
+func ReportRoleBinding(namespace string, name string, serviceAccount string) *rbacv1.RoleBinding {
+	return &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: "rbac.authorization.k8s.io",
+			Kind:     "Role",
+			Name:     name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      "ServiceAccount",
+				Name:      serviceAccount,
+				Namespace: namespace,
+			},
+		},
+	}
+}
+
+// GetNodeStatus - returns the state of the daemon on each node from the pods
+// and the reports they wrote, sorted by node name
+func GetNodeStatus(pods []corev1.Pod, reports map[string]map[string]string, gatewayNodes []string) []neutronv1.NodeStatus {
+	gateways := map[string]bool{}
+	for _, node := range gatewayNodes {
+		gateways[node] = true
+	}
+
+	nodes := []neutronv1.NodeStatus{}
+	for _, pod := range pods {
+		// a terminating pod might get replaced already
+		if pod.Spec.NodeName == "" || pod.DeletionTimestamp != nil {
+			continue
+		}
+		report := reports[pod.Spec.NodeName]
+		status := neutronv1.NodeStatus{
+			Node:       pod.Spec.NodeName,
+			Chassis:    report["chassis"],
+			SystemID:   report["system-id"],
+			EncapIP:    report["encap-ip"],
+			Gateway:    gateways[pod.Spec.NodeName],
+			OVSVersion: report["ovs-version"],
+			OVNVersion: report["ovn-version"],
+		}
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				status.Ready = condition.Status == corev1.ConditionTrue
+				status.LastTransitionTime = condition.LastTransitionTime
+			}
+		}
+		nodes = append(nodes, status)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Node < nodes[j].Node
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes
+}
@@ -0,0 +1,236 @@
+package common
+
+import (
+	"fmt"
+	"time"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// Upgrade strategy types
+const (
+	// UpgradeStrategyRollingUpdate - the daemonset controller replaces the pods
+	UpgradeStrategyRollingUpdate string = "RollingUpdate"
+	// UpgradeStrategyOnDelete - the operator restarts the nodes one at a time
+	UpgradeStrategyOnDelete string = "OnDelete"
+)
+
+// TemplateHashAnnotation - pod template annotation telling which spec a pod runs
+const TemplateHashAnnotation string = "neutron.openstack.org/template-hash"
+
+// GetUpgradeStrategyType - returns the upgrade strategy type, or the default if not set
+func GetUpgradeStrategyType(strategy *neutronv1.UpgradeStrategy) string {
+	if strategy == nil || strategy.Type == "" {
+		return UpgradeStrategyRollingUpdate
+	}
+	return strategy.Type
+}
+
+// GetDaemonsetUpdateStrategy - daemonset update strategy of the upgrade strategy
+func GetDaemonsetUpdateStrategy(strategy *neutronv1.UpgradeStrategy) appsv1.DaemonSetUpdateStrategy {
+	if GetUpgradeStrategyType(strategy) == UpgradeStrategyOnDelete {
+		return appsv1.DaemonSetUpdateStrategy{
+			Type: appsv1.OnDeleteDaemonSetStrategyType,
+		}
+	}
+
+	maxUnavailable := intstr.FromInt(1)
+	if strategy != nil && strategy.MaxUnavailable != nil {
+		maxUnavailable = *strategy.MaxUnavailable
+	}
+	return appsv1.DaemonSetUpdateStrategy{
+		Type: appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{
+			MaxUnavailable: &maxUnavailable,
+		},
+	}
+}
+
+// GetMinReadySeconds - seconds a pod has to be ready for to be healthy
+func GetMinReadySeconds(strategy *neutronv1.UpgradeStrategy) int32 {
+	if strategy == nil {
+		return 0
+	}
+	return strategy.MinReadySeconds
+}
+
+// IsPodHealthy - checks that the pod is ready for at least minReadySeconds
+func IsPodHealthy(pod *corev1.Pod, minReadySeconds int32, now time.Time) bool {
+	if !pod.DeletionTimestamp.IsZero() {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady && condition.Status == corev1.ConditionTrue {
+			return !condition.LastTransitionTime.Add(time.Duration(minReadySeconds) * time.Second).After(now)
+		}
+	}
+	return false
+}
+
+// IsPodOutdated - checks if the pod runs a previous spec of the daemonset
+func IsPodOutdated(pod *corev1.Pod, templateHash string) bool {
+	return pod.Annotations[TemplateHashAnnotation] != templateHash
+}
+
+// Phases of a daemonset revision
+const (
+	// RevisionPhaseCanary - the image runs on the canary nodes only
+	RevisionPhaseCanary string = "Canary"
+	// RevisionPhaseRollingOut - the image rolls out to all the nodes
+	RevisionPhaseRollingOut string = "RollingOut"
+	// RevisionPhaseComplete - all the nodes run the image
+	RevisionPhaseComplete string = "Complete"
+	// RevisionPhaseRolledBack - the canary failed, the nodes run the last complete image
+	RevisionPhaseRolledBack string = "RolledBack"
+)
+
+// RevisionHistoryLimit - number of revisions kept in the status
+const RevisionHistoryLimit int = 5
+
+// DefaultCanarySoakSeconds - soak time of the canary nodes if not set
+const DefaultCanarySoakSeconds int32 = 300
+
+// GetCanarySoakSeconds - returns the soak time of the canary nodes, or the default if not set
+func GetCanarySoakSeconds(canary *neutronv1.CanarySpec) int32 {
+	if canary.SoakSeconds == nil {
+		return DefaultCanarySoakSeconds
+	}
+	return *canary.SoakSeconds
+}
+
+// GetCanaryCount - returns the number of canary nodes, or the default if not set
+func GetCanaryCount(canary *neutronv1.CanarySpec) int {
+	if canary.Count == 0 {
+		return 1
+	}
+	return int(canary.Count)
+}
+
+// GetLastCompleteRevision - returns the newest complete revision, nil if none
+func GetLastCompleteRevision(revisions []neutronv1.DaemonsetRevision) *neutronv1.DaemonsetRevision {
+	for i := range revisions {
+		if revisions[i].Phase == RevisionPhaseComplete {
+			return &revisions[i]
+		}
+	}
+	return nil
+}
+
+// GetLastCompleteImage - returns the image of the newest complete revision
+func GetLastCompleteImage(revisions []neutronv1.DaemonsetRevision) string {
+	if revision := GetLastCompleteRevision(revisions); revision != nil {
+		return revision.Image
+	}
+	return ""
+}
+
+// IsRolledBack - checks if the canary of the spec image failed, the nodes run
+// the last complete revision until the spec has a different image
+func IsRolledBack(specImage string, revisions []neutronv1.DaemonsetRevision) bool {
+	return len(revisions) > 0 && revisions[0].Image == specImage && revisions[0].Phase == RevisionPhaseRolledBack &&
+		GetLastCompleteRevision(revisions) != nil
+}
+
+// GetRolloutImage - returns the image to run, which is the spec image unless
+// its canary failed. A different spec image is needed to try again.
+func GetRolloutImage(specImage string, revisions []neutronv1.DaemonsetRevision) string {
+	if IsRolledBack(specImage, revisions) {
+		return GetLastCompleteImage(revisions)
+	}
+	return specImage
+}
+
+// IsCanaryRollout - checks if the image has to go through the canary phase
+func IsCanaryRollout(strategy *neutronv1.UpgradeStrategy, image string, revisions []neutronv1.DaemonsetRevision) bool {
+	if strategy == nil || strategy.Canary == nil {
+		return false
+	}
+	// the first image, and rollbacks, go straight to all nodes
+	lastCompleteImage := GetLastCompleteImage(revisions)
+	if lastCompleteImage == "" || image == lastCompleteImage {
+		return false
+	}
+	return revisions[0].Image != image || revisions[0].Phase == RevisionPhaseCanary
+}
+
+// AddRevision - adds the revision as the newest one, dropping the oldest ones
+// beyond RevisionHistoryLimit
+func AddRevision(revisions []neutronv1.DaemonsetRevision, revision neutronv1.DaemonsetRevision) []neutronv1.DaemonsetRevision {
+	revisions = append([]neutronv1.DaemonsetRevision{revision}, revisions...)
+	if len(revisions) > RevisionHistoryLimit {
+		revisions = revisions[:RevisionHistoryLimit]
+	}
+	return revisions
+}
+
+// GetPodRestarts - sum of the container restarts of the pod
+func GetPodRestarts(pod *corev1.Pod) int32 {
+	var restarts int32
+	for _, containerStatus := range pod.Status.ContainerStatuses {
+		restarts += containerStatus.RestartCount
+	}
+	return restarts
+}
+
+// UpgradeHoldAnnotation - node annotation keeping the pods on the node from
+// being upgraded while set to "true"
+const UpgradeHoldAnnotation string = "neutron.openstack.org/upgrade-hold"
+
+// maxMaintenanceWindow - longest maintenance window
+const maxMaintenanceWindow = 7 * 24 * time.Hour
+
+// IsNodeHeld - checks if the upgrades of the node are on hold
+func IsNodeHeld(node *corev1.Node) bool {
+	return node.Annotations[UpgradeHoldAnnotation] == "true"
+}
+
+// ValidateMaintenanceWindows - checks the schedules and durations of the maintenance windows
+func ValidateMaintenanceWindows(strategy *neutronv1.UpgradeStrategy) error {
+	if strategy == nil {
+		return nil
+	}
+	for _, window := range strategy.MaintenanceWindows {
+		schedule, err := ParseCronSchedule(window.Schedule)
+		if err != nil {
+			return err
+		}
+		// e.g. 0 0 30 2 *
+		if schedule.Next(time.Now()).IsZero() {
+			return fmt.Errorf("maintenance window %q never starts", window.Schedule)
+		}
+		if window.Duration.Duration <= 0 || window.Duration.Duration > maxMaintenanceWindow {
+			return fmt.Errorf("invalid duration %s of maintenance window %q, must be up to %s",
+				window.Duration.Duration, window.Schedule, maxMaintenanceWindow)
+		}
+	}
+	return nil
+}
+
+// InMaintenanceWindow - checks if now is within one of the maintenance windows,
+// always true without windows. Otherwise it also returns the next window start.
+func InMaintenanceWindow(strategy *neutronv1.UpgradeStrategy, now time.Time) (bool, time.Time) {
+	if strategy == nil || len(strategy.MaintenanceWindows) == 0 {
+		return true, time.Time{}
+	}
+
+	now = now.UTC().Truncate(time.Minute)
+	var next time.Time
+	for _, window := range strategy.MaintenanceWindows {
+		schedule, err := ParseCronSchedule(window.Schedule)
+		if err != nil {
+			continue
+		}
+		// a start within the duration before now opens the window
+		if start := schedule.Next(now.Add(-window.Duration.Duration)); !start.IsZero() && !start.After(now) {
+			return true, time.Time{}
+		}
+		start := schedule.Next(now)
+		if !start.IsZero() && (next.IsZero() || start.Before(next)) {
+			next = start
+		}
+	}
+	return false, next
+}
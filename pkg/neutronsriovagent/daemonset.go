@@ -0,0 +1,211 @@
+package neutronsriovagent
+
+import (
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DaemonSet - the neutron-sriov-agent DaemonSet running on the nodes with the daemon label
+func DaemonSet(cr *neutronv1.NeutronSriovAgent, cmName string, hostAliases []corev1.HostAlias) *appsv1.DaemonSet {
+	var bidirectional = corev1.MountPropagationBidirectional
+	var hostToContainer = corev1.MountPropagationHostToContainer
+	var trueVar = true
+	var configVolumeDefaultMode int32 = 0644
+	var dirOrCreate = corev1.HostPathDirectoryOrCreate
+
+	daemonSet := appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"daemonset": cr.Name + "-daemonset"},
+			},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"daemonset": cr.Name + "-daemonset"},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:   map[string]string{"daemon": cr.Spec.Label},
+					HostNetwork:    true,
+					HostPID:        true,
+					DNSPolicy:      "ClusterFirstWithHostNet",
+					HostAliases:    hostAliases,
+					InitContainers: []corev1.Container{},
+					Containers:     []corev1.Container{},
+				},
+			},
+		},
+	}
+
+	initContainerSpec := corev1.Container{
+		Name:  "sriov-agent-config-init",
+		Image: cr.Spec.NeutronSriovImage,
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &trueVar,
+		},
+		Command: []string{
+			"/bin/bash", "-c", "export CTRL_IP_TENANT=$(getent hosts controller-0.tenant | awk '{print $1}') && export POD_IP_TENANT=$(ip route get $CTRL_IP_TENANT | awk '{print $5}') && cp -a /etc/neutron/* /tmp/neutron/",
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name: "MY_POD_IP",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "status.podIP",
+					},
+				},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      cmName,
+				ReadOnly:  true,
+				MountPath: "/etc/neutron/neutron.conf",
+				SubPath:   "neutron.conf",
+			},
+			{
+				Name:      cmName,
+				ReadOnly:  true,
+				MountPath: "/etc/neutron/plugins/ml2/sriov_agent.ini",
+				SubPath:   "sriov_agent.ini",
+			},
+			{
+				Name:      "etc-machine-id",
+				MountPath: "/etc/machine-id",
+				ReadOnly:  true,
+			},
+			{
+				Name:      "neutron-config-vol",
+				MountPath: "/tmp/neutron",
+				ReadOnly:  false,
+			},
+		},
+	}
+	daemonSet.Spec.Template.Spec.InitContainers = append(daemonSet.Spec.Template.Spec.InitContainers, initContainerSpec)
+
+	neutronSriovAgentContainerSpec := corev1.Container{
+		Name:  "neutron-sriov-agent",
+		Image: cr.Spec.NeutronSriovImage,
+		Command: []string{
+			"/bin/sleep", "86400",
+		},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &trueVar,
+		},
+		VolumeMounts: []corev1.VolumeMount{
+			{
+				Name:      cmName,
+				ReadOnly:  true,
+				MountPath: "/etc/neutron/neutron.conf",
+				SubPath:   "neutron.conf",
+			},
+			{
+				Name:      cmName,
+				ReadOnly:  true,
+				MountPath: "/etc/neutron/plugins/ml2/openvswitch_agent.ini",
+				SubPath:   "openvswitch_agent.ini",
+			},
+			{
+				Name:      "etc-machine-id",
+				MountPath: "/etc/machine-id",
+				ReadOnly:  true,
+			},
+			{
+				Name:             "lib-modules-volume",
+				MountPath:        "/lib/modules",
+				MountPropagation: &hostToContainer,
+			},
+			{
+				Name:             "run-openvswitch-volume",
+				MountPath:        "/var/run/openvswitch",
+				MountPropagation: &bidirectional,
+			},
+			{
+				Name:             "neutron-log-volume",
+				MountPath:        "/var/log/neutron",
+				MountPropagation: &bidirectional,
+			},
+			{
+				Name:      "neutron-config-vol",
+				MountPath: "/etc/nova",
+				ReadOnly:  false,
+			},
+		},
+	}
+	daemonSet.Spec.Template.Spec.Containers = append(daemonSet.Spec.Template.Spec.Containers, neutronSriovAgentContainerSpec)
+
+	volConfigs := []corev1.Volume{
+		{
+			Name: "etc-machine-id",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/etc/machine-id",
+				},
+			},
+		},
+		{
+			Name: "run-volume",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/run",
+				},
+			},
+		},
+		{
+			Name: "lib-modules-volume",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/lib/modules",
+				},
+			},
+		},
+		{
+			Name: "run-openvswitch-volume",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/var/run/openvswitch",
+					Type: &dirOrCreate,
+				},
+			},
+		},
+		{
+			Name: "neutron-log-volume",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/var/log/containers/neutron",
+					Type: &dirOrCreate,
+				},
+			},
+		},
+		{
+			Name: cmName,
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					DefaultMode: &configVolumeDefaultMode,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName,
+					},
+				},
+			},
+		},
+		{
+			Name: "neutron-config-vol",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{},
+			},
+		},
+	}
+	for _, volConfig := range volConfigs {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+
+	return &daemonSet
+}
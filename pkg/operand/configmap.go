@@ -0,0 +1,64 @@
+package operand
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+
+	"github.com/go-logr/logr"
+	util "github.com/openstack-k8s-operators/lib-common/pkg/util"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// ConfigMapHash - hash of the ConfigMap data, set in the pod template to
+// restart the pods when the data changes
+func ConfigMapHash(configMap *corev1.ConfigMap) (string, error) {
+	hash, err := util.ObjectHash(configMap.Data)
+	if err != nil {
+		return "", fmt.Errorf("error calculating configuration hash: %v", err)
+	}
+	return hash, nil
+}
+
+// EnsureConfigMaps - creates or patches the ConfigMaps owned by the instance
+func EnsureConfigMaps(c client.Client, log logr.Logger, scheme *runtime.Scheme, owner Object, configMaps ...*corev1.ConfigMap) error {
+	for _, configMap := range configMaps {
+		if err := EnsureConfigMap(c, log, scheme, owner, configMap); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// EnsureConfigMap creates the ConfigMap owned by the instance, or merge
+// patches the data of the live ConfigMap if it differs, which also reverts out
+// of band edits. Labels, annotations and owners added by others are preserved.
+func EnsureConfigMap(c client.Client, log logr.Logger, scheme *runtime.Scheme, owner Object, configMap *corev1.ConfigMap) error {
+	found := &corev1.ConfigMap{}
+	err := c.Get(context.TODO(), types.NamespacedName{Name: configMap.Name, Namespace: configMap.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		if err := controllerutil.SetControllerReference(owner, configMap, scheme); err != nil {
+			return err
+		}
+		log.Info("Creating a new ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+		return c.Create(context.TODO(), configMap)
+	} else if err != nil {
+		return err
+	}
+
+	original := found.DeepCopy()
+	found.Data = configMap.Data
+	if err := controllerutil.SetControllerReference(owner, found, scheme); err != nil {
+		return err
+	}
+	if reflect.DeepEqual(original, found) {
+		return nil
+	}
+	log.Info("Updating ConfigMap", "ConfigMap.Namespace", configMap.Namespace, "ConfigMap.Name", configMap.Name)
+	return c.Patch(context.TODO(), found, client.MergeFrom(original))
+}
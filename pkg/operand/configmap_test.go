@@ -0,0 +1,114 @@
+package operand
+
+import (
+	"context"
+	"testing"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	ctrl "sigs.k8s.io/controller-runtime"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/client/fake"
+)
+
+const testNamespace = "openstack"
+
+var testLog = ctrl.Log.WithName("operand")
+
+func testClient(t *testing.T, objs ...runtime.Object) client.Client {
+	t.Helper()
+	if err := neutronv1beta1.AddToScheme(scheme.Scheme); err != nil {
+		t.Fatal(err)
+	}
+	return fake.NewFakeClientWithScheme(scheme.Scheme, objs...)
+}
+
+func testOwner() *neutronv1beta1.OVSNodeOsp {
+	return &neutronv1beta1.OVSNodeOsp{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: neutronv1beta1.GroupVersion.String(),
+			Kind:       "OVSNodeOsp",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovs-node-osp",
+			Namespace: testNamespace,
+			UID:       "2f0bc3a4-54a6-4bbc-9b5b-35e6b5a1e0a1",
+		},
+	}
+}
+
+func testConfigMap(data map[string]string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovs-node-osp-scripts",
+			Namespace: testNamespace,
+		},
+		Data: data,
+	}
+}
+
+func getConfigMap(t *testing.T, c client.Client) *corev1.ConfigMap {
+	t.Helper()
+	configMap := &corev1.ConfigMap{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "ovs-node-osp-scripts", Namespace: testNamespace}, configMap); err != nil {
+		t.Fatal(err)
+	}
+	return configMap
+}
+
+func TestEnsureConfigMapCreate(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+
+	if err := EnsureConfigMap(c, testLog, scheme.Scheme, owner, testConfigMap(map[string]string{"init.sh": "a"})); err != nil {
+		t.Fatal(err)
+	}
+	live := getConfigMap(t, c)
+	if live.Data["init.sh"] != "a" {
+		t.Errorf("data: got %v", live.Data)
+	}
+	if ref := metav1.GetControllerOf(live); ref == nil || ref.UID != owner.UID {
+		t.Errorf("controller reference: got %v, want the owner", ref)
+	}
+}
+
+func TestEnsureConfigMapPatch(t *testing.T) {
+	owner := testOwner()
+	existing := testConfigMap(map[string]string{"init.sh": "edited", "extra.sh": "b"})
+	existing.Labels = map[string]string{"added-by": "someone"}
+	c := testClient(t, owner, existing)
+
+	if err := EnsureConfigMap(c, testLog, scheme.Scheme, owner, testConfigMap(map[string]string{"init.sh": "a"})); err != nil {
+		t.Fatal(err)
+	}
+	live := getConfigMap(t, c)
+	if len(live.Data) != 1 || live.Data["init.sh"] != "a" {
+		t.Errorf("data: got %v, want only the desired data", live.Data)
+	}
+	if live.Labels["added-by"] != "someone" {
+		t.Errorf("labels added by others got removed: %v", live.Labels)
+	}
+	if ref := metav1.GetControllerOf(live); ref == nil || ref.UID != owner.UID {
+		t.Errorf("controller reference: got %v, want the owner", ref)
+	}
+}
+
+func TestEnsureConfigMapNoop(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	if err := EnsureConfigMap(c, testLog, scheme.Scheme, owner, testConfigMap(map[string]string{"init.sh": "a"})); err != nil {
+		t.Fatal(err)
+	}
+	resourceVersion := getConfigMap(t, c).ResourceVersion
+
+	if err := EnsureConfigMap(c, testLog, scheme.Scheme, owner, testConfigMap(map[string]string{"init.sh": "a"})); err != nil {
+		t.Fatal(err)
+	}
+	if live := getConfigMap(t, c); live.ResourceVersion != resourceVersion {
+		t.Errorf("unchanged ConfigMap got written, resource version %s -> %s", resourceVersion, live.ResourceVersion)
+	}
+}
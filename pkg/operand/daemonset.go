@@ -0,0 +1,96 @@
+package operand
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/go-logr/logr"
+	util "github.com/openstack-k8s-operators/lib-common/pkg/util"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/controller/controllerutil"
+)
+
+// SetTemplateHash annotates the pod template with its hash and returns it. It
+// tells the pods of a previous spec apart during upgrades, so it has to be set
+// once the template is final.
+func SetTemplateHash(ds *appsv1.DaemonSet) (string, error) {
+	template := ds.Spec.Template.DeepCopy()
+	delete(template.Annotations, common.TemplateHashAnnotation)
+	templateHash, err := util.ObjectHash(template)
+	if err != nil {
+		return "", fmt.Errorf("error calculating configuration hash: %v", err)
+	}
+	if ds.Spec.Template.Annotations == nil {
+		ds.Spec.Template.Annotations = map[string]string{}
+	}
+	ds.Spec.Template.Annotations[common.TemplateHashAnnotation] = templateHash
+	return templateHash, nil
+}
+
+// EnsureDaemonSet creates the DaemonSet owned by the instance, or updates its
+// spec when it differs from the live one. The fields we set are compared with
+// semantic equality, fields defaulted by the API server are ignored. As
+// removing a field from the desired spec can not be detected this way, the
+// spec also gets updated when its hash differs from daemonsetHash, the hash
+// kept in the status of the instance. Out of band changes to the live spec are
+// reverted with a DriftCorrected event. force updates the spec regardless,
+// e.g. to revert changes made while paused which the caller already reported.
+// The new hash is set in daemonsetHash, the caller writes the status. It
+// returns the live DaemonSet and true if it got created or updated.
+func EnsureDaemonSet(c client.Client, log logr.Logger, recorder record.EventRecorder, scheme *runtime.Scheme, owner Object,
+	ds *appsv1.DaemonSet, daemonsetHash *string, force bool) (*appsv1.DaemonSet, bool, error) {
+
+	dsHash, err := util.ObjectHash(ds)
+	if err != nil {
+		return nil, false, fmt.Errorf("error calculating configuration hash: %v", err)
+	}
+	log.Info("DaemonsetHash: ", "Daemonset Hash:", dsHash)
+
+	if err := controllerutil.SetControllerReference(owner, ds, scheme); err != nil {
+		return nil, false, err
+	}
+
+	found := &appsv1.DaemonSet{}
+	err = c.Get(context.TODO(), types.NamespacedName{Name: ds.Name, Namespace: ds.Namespace}, found)
+	if err != nil && errors.IsNotFound(err) {
+		log.Info("Creating a new Daemonset", "Ds.Namespace", ds.Namespace, "Ds.Name", ds.Name)
+		if err := c.Create(context.TODO(), ds); err != nil {
+			return nil, false, err
+		}
+		*daemonsetHash = dsHash
+		return ds, true, nil
+	} else if err != nil {
+		return nil, false, err
+	}
+
+	drift := SpecDiff("spec", ds.Spec, found.Spec)
+	specChanged := *daemonsetHash != dsHash
+	if len(drift) == 0 && !specChanged && !force {
+		return found, false, nil
+	}
+	// the live spec only has to match the spec it got updated with
+	if len(drift) > 0 && !specChanged {
+		log.Info("Correcting Daemonset drift", "Ds.Namespace", ds.Namespace, "Ds.Name", ds.Name, "Fields", drift)
+		driftCorrections.WithLabelValues(ds.Namespace, ds.Name).Inc()
+		if !force {
+			recorder.Eventf(owner, corev1.EventTypeWarning, "DriftCorrected", "Reverted out of band changes to DaemonSet %s: %s",
+				ds.Name, strings.Join(drift, ", "))
+		}
+	}
+
+	log.Info("Daemonset Updated", "Ds.Namespace", ds.Namespace, "Ds.Name", ds.Name)
+	found.Spec = ds.Spec
+	if err := c.Update(context.TODO(), found); err != nil {
+		return nil, false, err
+	}
+	*daemonsetHash = dsHash
+	return found, true, nil
+}
@@ -0,0 +1,177 @@
+package operand
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func testDaemonSet(image string, env ...corev1.EnvVar) *appsv1.DaemonSet {
+	labels := map[string]string{"daemonset": "ovs-node-osp" + PodLabelSuffix}
+	return &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovs-node-osp",
+			Namespace: testNamespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "ovs-node-osp", Image: image, Env: env}},
+				},
+			},
+		},
+	}
+}
+
+func getDaemonSet(t *testing.T, c client.Client) *appsv1.DaemonSet {
+	t.Helper()
+	ds := &appsv1.DaemonSet{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "ovs-node-osp", Namespace: testNamespace}, ds); err != nil {
+		t.Fatal(err)
+	}
+	return ds
+}
+
+// ensureDaemonSet calls EnsureDaemonSet with the hash kept in the status of the owner
+func ensureDaemonSet(t *testing.T, c client.Client, recorder record.EventRecorder, owner *neutronv1beta1.OVSNodeOsp,
+	ds *appsv1.DaemonSet, force bool) bool {
+	t.Helper()
+	_, changed, err := EnsureDaemonSet(c, testLog, recorder, scheme.Scheme, owner, ds, &owner.Status.DaemonsetHash, force)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return changed
+}
+
+func events(recorder *record.FakeRecorder) []string {
+	events := []string{}
+	for {
+		select {
+		case event := <-recorder.Events:
+			events = append(events, event)
+		default:
+			return events
+		}
+	}
+}
+
+func TestEnsureDaemonSetCreate(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	recorder := record.NewFakeRecorder(10)
+
+	if !ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), false) {
+		t.Error("created DaemonSet not reported as changed")
+	}
+	live := getDaemonSet(t, c)
+	if ref := metav1.GetControllerOf(live); ref == nil || ref.UID != owner.UID {
+		t.Errorf("controller reference: got %v, want the owner", ref)
+	}
+	if owner.Status.DaemonsetHash == "" {
+		t.Error("daemonset hash not set")
+	}
+
+	if ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), false) {
+		t.Error("unchanged DaemonSet reported as changed")
+	}
+	if events := events(recorder); len(events) != 0 {
+		t.Errorf("unexpected events: %v", events)
+	}
+}
+
+func TestEnsureDaemonSetHashChange(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	recorder := record.NewFakeRecorder(10)
+	ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1", corev1.EnvVar{Name: "DEBUG", Value: "true"}), false)
+	hash := owner.Status.DaemonsetHash
+
+	// removing a field can only be told apart by the hash
+	if !ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), false) {
+		t.Fatal("DaemonSet with a removed env var not reported as changed")
+	}
+	if env := getDaemonSet(t, c).Spec.Template.Spec.Containers[0].Env; len(env) != 0 {
+		t.Errorf("removed env var still in the live DaemonSet: %v", env)
+	}
+	if owner.Status.DaemonsetHash == hash {
+		t.Error("daemonset hash not updated")
+	}
+
+	if !ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:2"), false) {
+		t.Fatal("DaemonSet with a new image not reported as changed")
+	}
+	if image := getDaemonSet(t, c).Spec.Template.Spec.Containers[0].Image; image != "agent:2" {
+		t.Errorf("image: got %s, want agent:2", image)
+	}
+	// spec changes are no drift
+	if events := events(recorder); len(events) != 0 {
+		t.Errorf("unexpected events: %v", events)
+	}
+}
+
+func TestEnsureDaemonSetDrift(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	recorder := record.NewFakeRecorder(10)
+	ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), false)
+
+	live := getDaemonSet(t, c)
+	live.Spec.Template.Spec.Containers[0].Image = "agent:edited"
+	if err := c.Update(context.TODO(), live); err != nil {
+		t.Fatal(err)
+	}
+
+	if !ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), false) {
+		t.Fatal("DaemonSet changed out of band not reported as changed")
+	}
+	if image := getDaemonSet(t, c).Spec.Template.Spec.Containers[0].Image; image != "agent:1" {
+		t.Errorf("out of band image not reverted: %s", image)
+	}
+	events := events(recorder)
+	if len(events) != 1 || !strings.Contains(events[0], "DriftCorrected") ||
+		!strings.Contains(events[0], "spec.template.spec.containers[0].image") {
+		t.Errorf("expected a DriftCorrected event naming the image, got %v", events)
+	}
+}
+
+func TestEnsureDaemonSetForce(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	recorder := record.NewFakeRecorder(10)
+	ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), false)
+	resourceVersion := getDaemonSet(t, c).ResourceVersion
+
+	if !ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), true) {
+		t.Error("forced update not reported as changed")
+	}
+	if getDaemonSet(t, c).ResourceVersion == resourceVersion {
+		t.Error("forced update did not write the DaemonSet")
+	}
+
+	live := getDaemonSet(t, c)
+	live.Spec.Template.Spec.Containers[0].Image = "agent:edited"
+	if err := c.Update(context.TODO(), live); err != nil {
+		t.Fatal(err)
+	}
+	if !ensureDaemonSet(t, c, recorder, owner, testDaemonSet("agent:1"), true) {
+		t.Fatal("forced update not reported as changed")
+	}
+	if image := getDaemonSet(t, c).Spec.Template.Spec.Containers[0].Image; image != "agent:1" {
+		t.Errorf("out of band image not reverted: %s", image)
+	}
+	// the caller reports the drift when forcing
+	if events := events(recorder); len(events) != 0 {
+		t.Errorf("unexpected events: %v", events)
+	}
+}
@@ -0,0 +1,141 @@
+package operand
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/equality"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+// Drift returns the desired objects whose live object differs, e.g. as they
+// got changed by hand while the instance was paused
+func Drift(c client.Client, desired ...runtime.Object) ([]string, error) {
+	drifted := []string{}
+	for _, object := range desired {
+		drift, err := hasDrift(c, object)
+		if err != nil {
+			return nil, err
+		}
+		if drift != "" {
+			drifted = append(drifted, drift)
+		}
+	}
+	return drifted, nil
+}
+
+// hasDrift returns the kind and name of the object if the live object differs
+// from the desired one. Fields the desired object does not set, like the ones
+// defaulted by the API server, are not compared.
+func hasDrift(c client.Client, desired runtime.Object) (string, error) {
+	switch object := desired.(type) {
+	case *corev1.ConfigMap:
+		live := &corev1.ConfigMap{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: object.Name, Namespace: object.Namespace}, live); err != nil {
+			if errors.IsNotFound(err) {
+				return "ConfigMap " + object.Name + " deleted", nil
+			}
+			return "", err
+		}
+		if !reflect.DeepEqual(object.Data, live.Data) {
+			return "ConfigMap " + object.Name, nil
+		}
+	case *appsv1.DaemonSet:
+		live := &appsv1.DaemonSet{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: object.Name, Namespace: object.Namespace}, live); err != nil {
+			if errors.IsNotFound(err) {
+				return "DaemonSet " + object.Name + " deleted", nil
+			}
+			return "", err
+		}
+		if diff := SpecDiff("spec", object.Spec, live.Spec); len(diff) > 0 {
+			return "DaemonSet " + object.Name + " (" + strings.Join(diff, ", ") + ")", nil
+		}
+	}
+	return "", nil
+}
+
+// SpecDiff returns the paths of the fields set in desired whose live value
+// differs, like spec.template.spec.containers[0].image. Fields not set in
+// desired are ignored, as with equality.Semantic.DeepDerivative, including
+// numbers left at zero.
+func SpecDiff(path string, desired interface{}, live interface{}) []string {
+	return diffFields(path, reflect.ValueOf(desired), reflect.ValueOf(live))
+}
+
+func diffFields(path string, desired reflect.Value, live reflect.Value) []string {
+	if equality.Semantic.DeepDerivative(desired.Interface(), live.Interface()) {
+		return nil
+	}
+
+	diffs := []string{}
+	switch desired.Kind() {
+	case reflect.Ptr:
+		if !desired.IsNil() && !live.IsNil() {
+			return diffFields(path, desired.Elem(), live.Elem())
+		}
+	case reflect.Struct:
+		// types with unexported fields, like resource.Quantity, are compared as a whole
+		for i := 0; i < desired.NumField(); i++ {
+			if desired.Type().Field(i).PkgPath != "" {
+				return []string{path}
+			}
+		}
+		for i := 0; i < desired.NumField(); i++ {
+			if isUnsetNumber(desired.Type().Field(i), desired.Field(i)) {
+				continue
+			}
+			fieldPath := path
+			if name := jsonName(desired.Type().Field(i)); name != "" {
+				fieldPath += "." + name
+			}
+			diffs = append(diffs, diffFields(fieldPath, desired.Field(i), live.Field(i))...)
+		}
+		// only fields left to the API server differ
+		if len(diffs) == 0 {
+			return nil
+		}
+	case reflect.Slice:
+		if desired.Len() == live.Len() {
+			for i := 0; i < desired.Len(); i++ {
+				diffs = append(diffs, diffFields(fmt.Sprintf("%s[%d]", path, i), desired.Index(i), live.Index(i))...)
+			}
+			if len(diffs) == 0 {
+				return nil
+			}
+		}
+	}
+	if len(diffs) == 0 {
+		return []string{path}
+	}
+	return diffs
+}
+
+// isUnsetNumber tells if the field is an omitempty number left at zero, which
+// the API server may default, like the timeoutSeconds of a probe.
+// DeepDerivative only ignores unset pointers, slices, maps and strings.
+func isUnsetNumber(field reflect.StructField, value reflect.Value) bool {
+	switch value.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return value.IsZero() && strings.Contains(field.Tag.Get("json"), ",omitempty")
+	}
+	return false
+}
+
+// jsonName returns the name of the field in the json serialization, empty for
+// inlined structs
+func jsonName(field reflect.StructField) string {
+	name := strings.Split(field.Tag.Get("json"), ",")[0]
+	if name == "" && !field.Anonymous {
+		return field.Name
+	}
+	return name
+}
@@ -0,0 +1,164 @@
+package operand
+
+import (
+	"reflect"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// desiredSpec - a daemonset spec as built by the reconcilers, leaving the
+// fields defaulted by the API server unset
+func desiredSpec() appsv1.DaemonSetSpec {
+	ds := testDaemonSet("agent:1", corev1.EnvVar{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{
+		FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"},
+	}})
+	container := &ds.Spec.Template.Spec.Containers[0]
+	container.Command = []string{"/bin/bash", "-c", "/usr/local/bin/init.sh"}
+	container.ReadinessProbe = &corev1.Probe{
+		Handler:             corev1.Handler{Exec: &corev1.ExecAction{Command: []string{"/usr/local/bin/check.sh"}}},
+		InitialDelaySeconds: 10,
+	}
+	container.LivenessProbe = &corev1.Probe{
+		Handler: corev1.Handler{TCPSocket: &corev1.TCPSocketAction{Port: intstr.FromInt(6640)}},
+	}
+	container.VolumeMounts = []corev1.VolumeMount{{Name: "scripts", MountPath: "/usr/local/bin"}}
+	ds.Spec.Template.Spec.Volumes = []corev1.Volume{
+		{Name: "scripts", VolumeSource: corev1.VolumeSource{
+			ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "ovs-node-osp-scripts"}},
+		}},
+		{Name: "run", VolumeSource: corev1.VolumeSource{
+			HostPath: &corev1.HostPathVolumeSource{Path: "/var/run/openvswitch"},
+		}},
+	}
+	ds.Spec.Template.Spec.HostNetwork = true
+	return ds.Spec
+}
+
+// defaulted applies the defaults of the API server to the spec
+func defaulted(spec appsv1.DaemonSetSpec) appsv1.DaemonSetSpec {
+	live := *spec.DeepCopy()
+	int32Ptr := func(i int32) *int32 { return &i }
+	maxUnavailable := intstr.FromInt(1)
+	hostPathType := corev1.HostPathUnset
+
+	live.RevisionHistoryLimit = int32Ptr(10)
+	live.UpdateStrategy = appsv1.DaemonSetUpdateStrategy{
+		Type:          appsv1.RollingUpdateDaemonSetStrategyType,
+		RollingUpdate: &appsv1.RollingUpdateDaemonSet{MaxUnavailable: &maxUnavailable},
+	}
+	pod := &live.Template.Spec
+	pod.RestartPolicy = corev1.RestartPolicyAlways
+	pod.TerminationGracePeriodSeconds = func(i int64) *int64 { return &i }(30)
+	pod.DNSPolicy = corev1.DNSClusterFirst
+	pod.SchedulerName = corev1.DefaultSchedulerName
+	pod.SecurityContext = &corev1.PodSecurityContext{}
+	for i := range pod.Containers {
+		container := &pod.Containers[i]
+		container.TerminationMessagePath = corev1.TerminationMessagePathDefault
+		container.TerminationMessagePolicy = corev1.TerminationMessageReadFile
+		container.ImagePullPolicy = corev1.PullIfNotPresent
+		for _, probe := range []*corev1.Probe{container.ReadinessProbe, container.LivenessProbe} {
+			if probe == nil {
+				continue
+			}
+			probe.TimeoutSeconds = 1
+			probe.PeriodSeconds = 10
+			probe.SuccessThreshold = 1
+			probe.FailureThreshold = 3
+		}
+		for j := range container.Env {
+			if ref := container.Env[j].ValueFrom; ref != nil && ref.FieldRef != nil {
+				ref.FieldRef.APIVersion = "v1"
+			}
+		}
+	}
+	for i := range pod.Volumes {
+		volume := &pod.Volumes[i].VolumeSource
+		if volume.ConfigMap != nil {
+			volume.ConfigMap.DefaultMode = int32Ptr(corev1.ConfigMapVolumeSourceDefaultMode)
+		}
+		if volume.HostPath != nil {
+			volume.HostPath.Type = &hostPathType
+		}
+	}
+	return live
+}
+
+func TestSpecDiffIgnoresDefaults(t *testing.T) {
+	desired := desiredSpec()
+	live := defaulted(desired)
+	if reflect.DeepEqual(desired, live) {
+		t.Fatal("the defaults did not change the spec")
+	}
+	if diff := SpecDiff("spec", desired, live); len(diff) != 0 {
+		t.Errorf("defaulted fields reported as drift: %v", diff)
+	}
+}
+
+func TestSpecDiff(t *testing.T) {
+	tests := []struct {
+		name   string
+		change func(spec *appsv1.DaemonSetSpec)
+		diff   []string
+	}{
+		{
+			name:   "image",
+			change: func(spec *appsv1.DaemonSetSpec) { spec.Template.Spec.Containers[0].Image = "agent:edited" },
+			diff:   []string{"spec.template.spec.containers[0].image"},
+		},
+		{
+			name: "env value",
+			change: func(spec *appsv1.DaemonSetSpec) {
+				spec.Template.Spec.Containers[0].Env[0].ValueFrom.FieldRef.FieldPath = "metadata.name"
+			},
+			diff: []string{"spec.template.spec.containers[0].env[0].valueFrom.fieldRef.fieldPath"},
+		},
+		{
+			name: "env var removed",
+			change: func(spec *appsv1.DaemonSetSpec) {
+				spec.Template.Spec.Containers[0].Env = nil
+			},
+			diff: []string{"spec.template.spec.containers[0].env"},
+		},
+		{
+			name: "probe handler",
+			change: func(spec *appsv1.DaemonSetSpec) {
+				spec.Template.Spec.Containers[0].ReadinessProbe.Exec.Command = []string{"true"}
+			},
+			diff: []string{"spec.template.spec.containers[0].readinessProbe.exec.command[0]"},
+		},
+		{
+			name: "volume",
+			change: func(spec *appsv1.DaemonSetSpec) {
+				spec.Template.Spec.Volumes[1].HostPath.Path = "/run"
+			},
+			diff: []string{"spec.template.spec.volumes[1].hostPath.path"},
+		},
+		{
+			name:   "host network",
+			change: func(spec *appsv1.DaemonSetSpec) { spec.Template.Spec.HostNetwork = false },
+			diff:   []string{"spec.template.spec.hostNetwork"},
+		},
+		{
+			name: "field not set in desired",
+			change: func(spec *appsv1.DaemonSetSpec) {
+				spec.Template.Spec.Containers[0].WorkingDir = "/tmp"
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			desired := desiredSpec()
+			live := defaulted(desired)
+			test.change(&live)
+			diff := SpecDiff("spec", desired, live)
+			if len(diff) != len(test.diff) || (len(diff) > 0 && !reflect.DeepEqual(diff, test.diff)) {
+				t.Errorf("got %v, want %v", diff, test.diff)
+			}
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package operand
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"sigs.k8s.io/controller-runtime/pkg/metrics"
+)
+
+// driftCorrections - DaemonSets reverted to the desired spec after they got
+// changed out of band
+var driftCorrections = prometheus.NewCounterVec(
+	prometheus.CounterOpts{
+		Name: "neutron_operator_daemonset_drift_corrections_total",
+		Help: "Number of times a DaemonSet changed out of band got reverted to the desired spec",
+	},
+	[]string{"namespace", "daemonset"},
+)
+
+func init() {
+	metrics.Registry.MustRegister(driftCorrections)
+}
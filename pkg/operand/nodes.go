@@ -0,0 +1,42 @@
+package operand
+
+import (
+	"reflect"
+
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+)
+
+// NodeChanged filters the updates of Nodes which do not change what the
+// agent kinds read from them: the labels, the taints, the Ready condition,
+// the upgrade hold annotation, the allocatable resources like the hugepages
+// and the machine-id. The status heartbeats of the kubelet are dropped.
+var NodeChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		old, ok := e.ObjectOld.(*corev1.Node)
+		if !ok {
+			return true
+		}
+		updated, ok := e.ObjectNew.(*corev1.Node)
+		if !ok {
+			return true
+		}
+		return !reflect.DeepEqual(old.Labels, updated.Labels) ||
+			!reflect.DeepEqual(old.Spec.Taints, updated.Spec.Taints) ||
+			isNodeReady(old) != isNodeReady(updated) ||
+			old.Annotations[common.UpgradeHoldAnnotation] != updated.Annotations[common.UpgradeHoldAnnotation] ||
+			!reflect.DeepEqual(old.Status.Allocatable, updated.Status.Allocatable) ||
+			old.Status.NodeInfo.MachineID != updated.Status.NodeInfo.MachineID
+	},
+}
+
+func isNodeReady(node *corev1.Node) bool {
+	for _, condition := range node.Status.Conditions {
+		if condition.Type == corev1.NodeReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
@@ -0,0 +1,63 @@
+package operand
+
+import (
+	"testing"
+	"time"
+
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+)
+
+func TestNodeChanged(t *testing.T) {
+	node := &corev1.Node{
+		ObjectMeta: metav1.ObjectMeta{Name: "worker-0", ResourceVersion: "1", Labels: map[string]string{"node-role.kubernetes.io/worker-osp": ""}},
+		Status: corev1.NodeStatus{
+			Conditions: []corev1.NodeCondition{{Type: corev1.NodeReady, Status: corev1.ConditionTrue}},
+		},
+	}
+	change := func(f func(*corev1.Node)) *corev1.Node {
+		updated := node.DeepCopy()
+		updated.ResourceVersion = "2"
+		f(updated)
+		return updated
+	}
+
+	tests := []struct {
+		name    string
+		updated *corev1.Node
+		want    bool
+	}{
+		{name: "heartbeat", updated: change(func(n *corev1.Node) {
+			n.Status.Conditions[0].LastHeartbeatTime = metav1.NewTime(time.Now())
+			n.Status.Images = []corev1.ContainerImage{{Names: []string{"ovn-controller"}}}
+		})},
+		{name: "other annotation", updated: change(func(n *corev1.Node) {
+			n.Annotations = map[string]string{"volumes.kubernetes.io/controller-managed-attach-detach": "true"}
+		})},
+		{name: "label", updated: change(func(n *corev1.Node) {
+			n.Labels["gateway"] = "true"
+		}), want: true},
+		{name: "taint", updated: change(func(n *corev1.Node) {
+			n.Spec.Taints = []corev1.Taint{{Key: "dedicated", Effect: corev1.TaintEffectNoSchedule}}
+		}), want: true},
+		{name: "not ready", updated: change(func(n *corev1.Node) {
+			n.Status.Conditions[0].Status = corev1.ConditionUnknown
+		}), want: true},
+		{name: "upgrade hold", updated: change(func(n *corev1.Node) {
+			n.Annotations = map[string]string{common.UpgradeHoldAnnotation: "true"}
+		}), want: true},
+		{name: "hugepages", updated: change(func(n *corev1.Node) {
+			n.Status.Allocatable = corev1.ResourceList{"hugepages-1Gi": resource.MustParse("4Gi")}
+		}), want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := NodeChanged.Update(event.UpdateEvent{ObjectOld: node, ObjectNew: test.updated}); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
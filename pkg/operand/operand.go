@@ -0,0 +1,59 @@
+// Package operand creates and updates the objects the neutron agent kinds
+// deploy for their instances: the ConfigMaps with the scripts and the
+// configuration, and the DaemonSet running the agent on the nodes.
+//
+// A reconciler builds the desired objects and passes them to
+// Reconciler.Reconcile, which sets the owner references, creates or patches
+// the objects, hashes the configuration into the pod template and writes the
+// status. The Reconcile of a new agent kind with a ConfigMap and a DaemonSet,
+// built in its pkg/myagent, is:
+//
+//	instance := &neutronv1beta1.MyAgent{}
+//	if err := r.Client.Get(context.TODO(), req.NamespacedName, instance); err != nil {
+//		return ctrl.Result{}, client.IgnoreNotFound(err)
+//	}
+//	operands := &operand.Reconciler{Client: r.Client, Log: r.Log, Scheme: r.Scheme, Recorder: r.Recorder}
+//	status := operand.Status{Conditions: &instance.Status.Conditions, DaemonsetHash: &instance.Status.DaemonsetHash}
+//	if paused, err := operands.Paused(instance, status); paused || err != nil {
+//		return ctrl.Result{}, err
+//	}
+//	result, err := operands.Reconcile(instance, status,
+//		myagent.ConfigMap(instance, instance.Name),
+//		myagent.DaemonSet(instance, instance.Name))
+//	return result.Result, err
+//
+// Its SetupWithManager owns ConfigMaps and DaemonSets, and watches the
+// ConfigMaps and Secrets read by the pods with IndexReferences and
+// ReferenceToOwner. ConfigMaps the pods apply while running are marked with
+// InPlace, their changes do not restart the pods.
+package operand
+
+import (
+	"strings"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// Object - the instance owning the operand objects
+type Object interface {
+	metav1.Object
+	runtime.Object
+}
+
+// PodLabelSuffix - suffix of the instance name in the daemonset label of the pods
+const PodLabelSuffix string = "-daemonset"
+
+// PodToOwner maps a pod of a daemonset to the instance owning the daemonset
+func PodToOwner(o handler.MapObject) []reconcile.Request {
+	daemonset, ok := o.Meta.GetLabels()["daemonset"]
+	if !ok || !strings.HasSuffix(daemonset, PodLabelSuffix) {
+		return []reconcile.Request{}
+	}
+	return []reconcile.Request{
+		{NamespacedName: types.NamespacedName{Name: strings.TrimSuffix(daemonset, PodLabelSuffix), Namespace: o.Meta.GetNamespace()}},
+	}
+}
@@ -0,0 +1,51 @@
+package operand
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/client-go/tools/record"
+)
+
+// Paused keeps the Paused condition in sync with the paused annotation of the
+// instance. It returns true while the annotation is set, the instance then
+// only gets its status updated.
+func (r *Reconciler) Paused(owner Object, status Status) (bool, error) {
+	if !common.IsPaused(owner) {
+		return false, nil
+	}
+
+	if common.SetCondition(status.Conditions, common.ConditionPaused, corev1.ConditionTrue, "PausedByAnnotation",
+		fmt.Sprintf("%s is set, the operator does not change the objects of the instance", common.PausedAnnotation)) {
+		r.Recorder.Event(owner, corev1.EventTypeNormal, "Paused", "Reconcile paused")
+		if err := r.Client.Status().Update(context.TODO(), owner); err != nil {
+			return true, err
+		}
+	}
+	return true, nil
+}
+
+// Resumed returns true if the paused annotation of the instance got removed,
+// the drift of its objects still has to be reported with ReportResume
+func Resumed(owner Object, conditions []neutronv1beta1.Condition) bool {
+	return !common.IsPaused(owner) && common.IsConditionTrue(conditions, common.ConditionPaused)
+}
+
+// ReportResume records the objects changed while paused in an event and
+// clears the Paused condition. The caller overwrites the changes and writes
+// the status.
+func ReportResume(recorder record.EventRecorder, owner Object, conditions *[]neutronv1beta1.Condition, drifted []string) {
+	if len(drifted) > 0 {
+		recorder.Eventf(owner, corev1.EventTypeWarning, "DriftDetected", "Resumed, reverting changes made while paused: %s",
+			strings.Join(drifted, ", "))
+	} else {
+		recorder.Event(owner, corev1.EventTypeNormal, "Resumed", "Resumed, no changes made while paused")
+	}
+
+	common.SetCondition(conditions, common.ConditionPaused, corev1.ConditionFalse, "Resumed",
+		fmt.Sprintf("%s got removed", common.PausedAnnotation))
+}
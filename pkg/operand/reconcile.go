@@ -0,0 +1,209 @@
+package operand
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"strings"
+	"time"
+
+	"github.com/go-logr/logr"
+	util "github.com/openstack-k8s-operators/lib-common/pkg/util"
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ConfigHashAnnotation - pod template annotation with the hash of the data of
+// the ConfigMaps of the instance
+const ConfigHashAnnotation string = "neutron.openstack.org/config-hash"
+
+// InPlaceAnnotation - set on the ConfigMaps the pods apply while running,
+// changing them does not restart the pods
+const InPlaceAnnotation string = "neutron.openstack.org/in-place"
+
+// InPlace marks the ConfigMap as applied in place, it is left out of the
+// config hash
+func InPlace(configMap *corev1.ConfigMap) *corev1.ConfigMap {
+	if configMap.Annotations == nil {
+		configMap.Annotations = map[string]string{}
+	}
+	configMap.Annotations[InPlaceAnnotation] = "true"
+	return configMap
+}
+
+// Reconciler creates and updates the objects of the instances of an agent kind
+type Reconciler struct {
+	Client   client.Client
+	Log      logr.Logger
+	Scheme   *runtime.Scheme
+	Recorder record.EventRecorder
+}
+
+// Status - the fields of the status of the instance kept by the Reconciler
+type Status struct {
+	Conditions    *[]neutronv1beta1.Condition
+	DaemonsetHash *string
+}
+
+// Result of Reconcile
+type Result struct {
+	reconcile.Result
+	// DaemonSet is the live DaemonSet, nil while a reference of the pods is missing
+	DaemonSet *appsv1.DaemonSet
+	// TemplateHash is the hash of the pod template rolled out
+	TemplateHash string
+	// Changed is true if the DaemonSet got created or updated
+	Changed bool
+}
+
+// Reconcile creates or patches the desired ConfigMaps and the DaemonSet,
+// owned by the instance. The pod template gets annotated with
+//   - the hash of the data of the ConfigMaps not marked InPlace
+//   - the hash of the ConfigMaps and Secrets the containers read env vars
+//     from, the DaemonSet is left as is while one of them is missing
+//   - the template hash the rollout compares the pods with. A template which
+//     already has one, restored from a revision, is rolled out as is.
+//
+// Changes made while the instance was paused get reported and reverted. The
+// Conditions and the DaemonsetHash are written to the status once at the end.
+func (r *Reconciler) Reconcile(owner Object, status Status, desired ...runtime.Object) (Result, error) {
+	conditions := append([]neutronv1beta1.Condition(nil), *status.Conditions...)
+	daemonsetHash := *status.DaemonsetHash
+
+	result, err := r.reconcile(owner, status, desired)
+	if err != nil {
+		return result, err
+	}
+	if reflect.DeepEqual(conditions, *status.Conditions) && daemonsetHash == *status.DaemonsetHash {
+		return result, nil
+	}
+	return result, r.Client.Status().Update(context.TODO(), owner)
+}
+
+func (r *Reconciler) reconcile(owner Object, status Status, desired []runtime.Object) (Result, error) {
+	var configMaps []*corev1.ConfigMap
+	var configObjects []runtime.Object
+	var ds *appsv1.DaemonSet
+	for _, object := range desired {
+		switch object := object.(type) {
+		case *corev1.ConfigMap:
+			configMaps = append(configMaps, object)
+			configObjects = append(configObjects, object)
+		case *appsv1.DaemonSet:
+			ds = object
+		default:
+			return Result{}, fmt.Errorf("unsupported operand object %T", object)
+		}
+	}
+	if ds == nil {
+		return Result{}, fmt.Errorf("no DaemonSet in the operand objects of %s", owner.GetName())
+	}
+
+	resumed := Resumed(owner, *status.Conditions)
+	var drifted []string
+	if resumed {
+		var err error
+		drifted, err = Drift(r.Client, configObjects...)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	for _, configMap := range configMaps {
+		if err := EnsureConfigMap(r.Client, r.Log, r.Scheme, owner, configMap); err != nil {
+			return Result{}, err
+		}
+	}
+
+	templateHash, restored := ds.Spec.Template.Annotations[common.TemplateHashAnnotation]
+	if !restored {
+		if err := setConfigHash(ds, configMaps); err != nil {
+			return Result{}, err
+		}
+		// the pods only read the env vars from ConfigMaps and Secrets at start
+		resolved, err := r.reconcileReferences(owner, status, ds)
+		if err != nil {
+			return Result{}, err
+		}
+		if !resolved {
+			return Result{Result: reconcile.Result{RequeueAfter: time.Second * 30}}, nil
+		}
+		templateHash, err = SetTemplateHash(ds)
+		if err != nil {
+			return Result{}, err
+		}
+	}
+
+	// Report what got changed while paused, the daemonset gets overwritten
+	if resumed {
+		dsDrift, err := Drift(r.Client, ds)
+		if err != nil {
+			return Result{}, err
+		}
+		ReportResume(r.Recorder, owner, status.Conditions, append(drifted, dsDrift...))
+	}
+
+	found, changed, err := EnsureDaemonSet(r.Client, r.Log, r.Recorder, r.Scheme, owner, ds, status.DaemonsetHash, resumed)
+	if err != nil {
+		return Result{}, err
+	}
+	return Result{DaemonSet: found, TemplateHash: templateHash, Changed: changed}, nil
+}
+
+// setConfigHash annotates the pod template with the hash of the data of the
+// ConfigMaps, leaving out the ones applied in place
+func setConfigHash(ds *appsv1.DaemonSet, configMaps []*corev1.ConfigMap) error {
+	hashes := []string{}
+	for _, configMap := range configMaps {
+		if configMap.Annotations[InPlaceAnnotation] == "true" {
+			continue
+		}
+		hash, err := ConfigMapHash(configMap)
+		if err != nil {
+			return err
+		}
+		hashes = append(hashes, hash)
+	}
+	if len(hashes) == 0 {
+		return nil
+	}
+	configHash, err := util.ObjectHash(hashes)
+	if err != nil {
+		return fmt.Errorf("error calculating configuration hash: %v", err)
+	}
+	if ds.Spec.Template.Annotations == nil {
+		ds.Spec.Template.Annotations = map[string]string{}
+	}
+	ds.Spec.Template.Annotations[ConfigHashAnnotation] = configHash
+	return nil
+}
+
+// reconcileReferences folds the hash of the ConfigMaps and Secrets the pods
+// read env vars from into the pod template, and keeps the ReferencesResolved
+// condition in sync. It returns false if a reference does not exist, the
+// daemonset is then left as is instead of rolling out pods which can not start.
+func (r *Reconciler) reconcileReferences(owner Object, status Status, ds *appsv1.DaemonSet) (bool, error) {
+	missing, err := SetReferencesHash(r.Client, ds)
+	if err != nil {
+		return false, err
+	}
+
+	if len(missing) > 0 {
+		message := "Missing " + strings.Join(missing, ", ")
+		r.Log.Info("Waiting for the references of the pods", "Missing", missing)
+		if common.SetCondition(status.Conditions, common.ConditionReferencesResolved, corev1.ConditionFalse, "MissingReference", message) {
+			r.Recorder.Event(owner, corev1.EventTypeWarning, "MissingReference", message)
+		}
+		return false, nil
+	}
+
+	common.SetCondition(status.Conditions, common.ConditionReferencesResolved, corev1.ConditionTrue, "Resolved",
+		"The ConfigMaps and Secrets read by the pods exist")
+	return true, nil
+}
@@ -0,0 +1,157 @@
+package operand
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	neutronv1beta1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes/scheme"
+	"k8s.io/client-go/tools/record"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+)
+
+func testReconciler(c client.Client, recorder record.EventRecorder) *Reconciler {
+	return &Reconciler{Client: c, Log: testLog, Scheme: scheme.Scheme, Recorder: recorder}
+}
+
+func testInPlaceConfigMap(data map[string]string) *corev1.ConfigMap {
+	return InPlace(&corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ovs-node-osp-ovs-config",
+			Namespace: testNamespace,
+		},
+		Data: data,
+	})
+}
+
+// reconcileOperands runs Reconcile with the status of the owner
+func reconcileOperands(t *testing.T, c client.Client, recorder record.EventRecorder, owner *neutronv1beta1.OVSNodeOsp,
+	desired ...runtime.Object) Result {
+	t.Helper()
+	result, err := testReconciler(c, recorder).Reconcile(owner,
+		Status{Conditions: &owner.Status.Conditions, DaemonsetHash: &owner.Status.DaemonsetHash}, desired...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+func getOwner(t *testing.T, c client.Client) *neutronv1beta1.OVSNodeOsp {
+	t.Helper()
+	owner := &neutronv1beta1.OVSNodeOsp{}
+	if err := c.Get(context.TODO(), types.NamespacedName{Name: "ovs-node-osp", Namespace: testNamespace}, owner); err != nil {
+		t.Fatal(err)
+	}
+	return owner
+}
+
+func TestReconcile(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	recorder := record.NewFakeRecorder(10)
+
+	result := reconcileOperands(t, c, recorder, owner, testConfigMap(map[string]string{"init.sh": "a"}),
+		testInPlaceConfigMap(map[string]string{"bonds": "a"}), testDaemonSet("agent:1"))
+	if !result.Changed || result.DaemonSet == nil || result.TemplateHash == "" {
+		t.Fatalf("created DaemonSet not reported: %+v", result)
+	}
+	live := getDaemonSet(t, c)
+	if live.Spec.Template.Annotations[common.TemplateHashAnnotation] != result.TemplateHash {
+		t.Errorf("template hash annotation %q, want %q", live.Spec.Template.Annotations[common.TemplateHashAnnotation], result.TemplateHash)
+	}
+	if live.Spec.Template.Annotations[ConfigHashAnnotation] == "" {
+		t.Error("config hash not set")
+	}
+	for _, name := range []string{"ovs-node-osp-scripts", "ovs-node-osp-ovs-config"} {
+		configMap := &corev1.ConfigMap{}
+		if err := c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: testNamespace}, configMap); err != nil {
+			t.Fatal(err)
+		}
+		if ref := metav1.GetControllerOf(configMap); ref == nil || ref.UID != owner.UID {
+			t.Errorf("controller reference of %s: got %v, want the owner", name, ref)
+		}
+	}
+
+	// the status gets written once, with the daemonset hash and the conditions
+	status := getOwner(t, c).Status
+	if status.DaemonsetHash == "" || status.DaemonsetHash != owner.Status.DaemonsetHash {
+		t.Errorf("daemonset hash not stored in the status: %q", status.DaemonsetHash)
+	}
+	if !common.IsConditionTrue(status.Conditions, common.ConditionReferencesResolved) {
+		t.Errorf("ReferencesResolved not stored in the status: %v", status.Conditions)
+	}
+
+	// changes of in place ConfigMaps keep the pods
+	result = reconcileOperands(t, c, recorder, owner, testConfigMap(map[string]string{"init.sh": "a"}),
+		testInPlaceConfigMap(map[string]string{"bonds": "b"}), testDaemonSet("agent:1"))
+	if result.Changed {
+		t.Error("DaemonSet changed with an in place ConfigMap")
+	}
+	templateHash := result.TemplateHash
+
+	result = reconcileOperands(t, c, recorder, owner, testConfigMap(map[string]string{"init.sh": "b"}),
+		testInPlaceConfigMap(map[string]string{"bonds": "b"}), testDaemonSet("agent:1"))
+	if !result.Changed || result.TemplateHash == templateHash {
+		t.Error("DaemonSet not changed with the scripts")
+	}
+}
+
+func TestReconcileMissingReference(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+	recorder := record.NewFakeRecorder(10)
+
+	ds := testDaemonSet("agent:1", corev1.EnvVar{Name: "OVN_SB_REMOTE", ValueFrom: configMapKeyRef("ovn-connection", "SBConnection", false)})
+	result := reconcileOperands(t, c, recorder, owner, ds)
+	if result.DaemonSet != nil || result.RequeueAfter != time.Second*30 {
+		t.Errorf("DaemonSet reconciled with a missing reference: %+v", result)
+	}
+	status := getOwner(t, c).Status
+	if common.IsConditionTrue(status.Conditions, common.ConditionReferencesResolved) || len(status.Conditions) == 0 {
+		t.Errorf("missing reference not stored in the status: %v", status.Conditions)
+	}
+	if events := events(recorder); len(events) != 1 || !strings.Contains(events[0], "MissingReference") {
+		t.Errorf("events: got %v, want MissingReference", events)
+	}
+}
+
+func TestReconcileRestoredTemplate(t *testing.T) {
+	owner := testOwner()
+	c := testClient(t, owner)
+
+	ds := testDaemonSet("agent:1")
+	ds.Spec.Template.Annotations = map[string]string{common.TemplateHashAnnotation: "old"}
+	result := reconcileOperands(t, c, record.NewFakeRecorder(10), owner, testConfigMap(map[string]string{"init.sh": "a"}), ds)
+	if result.TemplateHash != "old" {
+		t.Errorf("template hash %q, want old", result.TemplateHash)
+	}
+	if annotations := getDaemonSet(t, c).Spec.Template.Annotations; len(annotations) != 1 {
+		t.Errorf("restored template got changed: %v", annotations)
+	}
+}
+
+func TestReconcileResumed(t *testing.T) {
+	owner := testOwner()
+	common.SetCondition(&owner.Status.Conditions, common.ConditionPaused, corev1.ConditionTrue, "PausedByAnnotation", "")
+	c := testClient(t, owner, testConfigMap(map[string]string{"init.sh": "edited"}))
+	recorder := record.NewFakeRecorder(10)
+
+	reconcileOperands(t, c, recorder, owner, testConfigMap(map[string]string{"init.sh": "a"}), testDaemonSet("agent:1"))
+	if data := getConfigMap(t, c).Data["init.sh"]; data != "a" {
+		t.Errorf("change made while paused not reverted: %s", data)
+	}
+	if common.IsConditionTrue(getOwner(t, c).Status.Conditions, common.ConditionPaused) {
+		t.Error("Paused condition not cleared")
+	}
+	events := events(recorder)
+	if len(events) != 1 || !strings.Contains(events[0], "DriftDetected") || !strings.Contains(events[0], "ConfigMap ovs-node-osp-scripts") {
+		t.Errorf("events: got %v, want the drift of the ConfigMap", events)
+	}
+}
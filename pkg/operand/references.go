@@ -0,0 +1,217 @@
+package operand
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sort"
+
+	util "github.com/openstack-k8s-operators/lib-common/pkg/util"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/predicate"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+// ReferencesHashAnnotation - pod template annotation with the hash of the
+// ConfigMaps and Secrets the containers read env vars from
+const ReferencesHashAnnotation string = "neutron.openstack.org/references-hash"
+
+// reference - a ConfigMap or Secret, or one of its keys, read by an env var
+type reference struct {
+	kind     string
+	name     string
+	key      string
+	optional bool
+}
+
+// getReferences returns the ConfigMaps and Secrets the containers of the pod
+// template read env vars from. Mounted ConfigMaps and Secrets are not
+// included, the kubelet updates them in the running pods.
+func getReferences(spec *corev1.PodSpec) []reference {
+	references := []reference{}
+	containers := append(append([]corev1.Container{}, spec.InitContainers...), spec.Containers...)
+	for _, container := range containers {
+		for _, env := range container.Env {
+			if env.ValueFrom == nil {
+				continue
+			}
+			if ref := env.ValueFrom.ConfigMapKeyRef; ref != nil {
+				references = append(references, reference{"ConfigMap", ref.Name, ref.Key, isOptional(ref.Optional)})
+			}
+			if ref := env.ValueFrom.SecretKeyRef; ref != nil {
+				references = append(references, reference{"Secret", ref.Name, ref.Key, isOptional(ref.Optional)})
+			}
+		}
+		for _, envFrom := range container.EnvFrom {
+			if ref := envFrom.ConfigMapRef; ref != nil {
+				references = append(references, reference{"ConfigMap", ref.Name, "", isOptional(ref.Optional)})
+			}
+			if ref := envFrom.SecretRef; ref != nil {
+				references = append(references, reference{"Secret", ref.Name, "", isOptional(ref.Optional)})
+			}
+		}
+	}
+	return references
+}
+
+func isOptional(optional *bool) bool {
+	return optional != nil && *optional
+}
+
+// SetReferencesHash annotates the pod template with the hash of the
+// ConfigMaps and Secrets its containers read env vars from. Env vars are only
+// read when the container starts, so the pods have to be replaced when the
+// referenced data changes. It has to be called before SetTemplateHash and
+// returns the references which do not exist, the pods could not start.
+func SetReferencesHash(c client.Client, ds *appsv1.DaemonSet) ([]string, error) {
+	values := map[string]interface{}{}
+	missing := []string{}
+	for _, ref := range getReferences(&ds.Spec.Template.Spec) {
+		data, err := getReferenceData(c, ds.Namespace, ref.kind, ref.name)
+		if err != nil {
+			return nil, err
+		}
+		if data == nil {
+			if !ref.optional {
+				missing = append(missing, ref.kind+" "+ref.name)
+			}
+			continue
+		}
+
+		if ref.key == "" {
+			values[ref.kind+"/"+ref.name] = data
+			continue
+		}
+		value, ok := data[ref.key]
+		if !ok && !ref.optional {
+			missing = append(missing, fmt.Sprintf("%s %s key %s", ref.kind, ref.name, ref.key))
+		}
+		values[ref.kind+"/"+ref.name+"/"+ref.key] = value
+	}
+	sort.Strings(missing)
+
+	hash, err := util.ObjectHash(values)
+	if err != nil {
+		return nil, fmt.Errorf("error calculating configuration hash: %v", err)
+	}
+	if ds.Spec.Template.Annotations == nil {
+		ds.Spec.Template.Annotations = map[string]string{}
+	}
+	ds.Spec.Template.Annotations[ReferencesHashAnnotation] = hash
+	return missing, nil
+}
+
+// getReferenceData returns the data of the ConfigMap or Secret, nil if it
+// does not exist
+func getReferenceData(c client.Client, namespace string, kind string, name string) (map[string]string, error) {
+	var err error
+	data := map[string]string{}
+	if kind == "ConfigMap" {
+		configMap := &corev1.ConfigMap{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, configMap)
+		for key, value := range configMap.Data {
+			data[key] = value
+		}
+		for key, value := range configMap.BinaryData {
+			data[key] = string(value)
+		}
+	} else {
+		secret := &corev1.Secret{}
+		err = c.Get(context.TODO(), types.NamespacedName{Name: name, Namespace: namespace}, secret)
+		for key, value := range secret.Data {
+			data[key] = string(value)
+		}
+	}
+	if err != nil {
+		if errors.IsNotFound(err) {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return data, nil
+}
+
+// referencesField returns the name of the index of the DaemonSets controlled
+// by the kind, by the ConfigMaps and Secrets their containers read env vars
+// from. Each kind has its own index so that every controller can add it.
+func referencesField(kind string) string {
+	return ".spec.template.references." + kind
+}
+
+// referenceIndexValue - value of the references index for a ConfigMap or Secret
+func referenceIndexValue(kind string, name string) string {
+	return kind + "/" + name
+}
+
+// IndexReferences adds the index of the DaemonSets controlled by the kind by
+// the ConfigMaps and Secrets they read env vars from, used by ReferenceToOwner
+func IndexReferences(indexer client.FieldIndexer, kind string) error {
+	return indexer.IndexField(context.TODO(), &appsv1.DaemonSet{}, referencesField(kind), func(o runtime.Object) []string {
+		ds := o.(*appsv1.DaemonSet)
+		owner := metav1.GetControllerOf(ds)
+		if owner == nil || owner.Kind != kind {
+			return nil
+		}
+		values := []string{}
+		for _, ref := range getReferences(&ds.Spec.Template.Spec) {
+			values = append(values, referenceIndexValue(ref.kind, ref.name))
+		}
+		return values
+	})
+}
+
+// ReferenceToOwner returns a map function enqueueing the instances of the
+// kind whose DaemonSet reads env vars from the mapped ConfigMap or Secret. It
+// needs the index added by IndexReferences.
+func ReferenceToOwner(c client.Client, kind string) handler.ToRequestsFunc {
+	return func(o handler.MapObject) []reconcile.Request {
+		refKind := "ConfigMap"
+		if _, ok := o.Object.(*corev1.Secret); ok {
+			refKind = "Secret"
+		}
+
+		result := []reconcile.Request{}
+		daemonsets := &appsv1.DaemonSetList{}
+		if err := c.List(context.TODO(), daemonsets, client.InNamespace(o.Meta.GetNamespace()),
+			client.MatchingFields{referencesField(kind): referenceIndexValue(refKind, o.Meta.GetName())}); err != nil {
+			return result
+		}
+		for _, ds := range daemonsets.Items {
+			owner := metav1.GetControllerOf(&ds)
+			if owner == nil || owner.Kind != kind {
+				continue
+			}
+			for _, ref := range getReferences(&ds.Spec.Template.Spec) {
+				if ref.kind == refKind && ref.name == o.Meta.GetName() {
+					result = append(result, reconcile.Request{NamespacedName: types.NamespacedName{Name: owner.Name, Namespace: ds.Namespace}})
+					break
+				}
+			}
+		}
+		return result
+	}
+}
+
+// DataChanged filters the updates of ConfigMaps and Secrets which do not
+// change their data, like the frequent ones of the leader election ConfigMap
+var DataChanged = predicate.Funcs{
+	UpdateFunc: func(e event.UpdateEvent) bool {
+		switch old := e.ObjectOld.(type) {
+		case *corev1.ConfigMap:
+			updated, ok := e.ObjectNew.(*corev1.ConfigMap)
+			return !ok || !reflect.DeepEqual(old.Data, updated.Data) || !reflect.DeepEqual(old.BinaryData, updated.BinaryData)
+		case *corev1.Secret:
+			updated, ok := e.ObjectNew.(*corev1.Secret)
+			return !ok || !reflect.DeepEqual(old.Data, updated.Data)
+		}
+		return true
+	},
+}
@@ -0,0 +1,274 @@
+package operand
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+	"sigs.k8s.io/controller-runtime/pkg/client"
+	"sigs.k8s.io/controller-runtime/pkg/event"
+	"sigs.k8s.io/controller-runtime/pkg/handler"
+	"sigs.k8s.io/controller-runtime/pkg/reconcile"
+)
+
+func configMapKeyRef(name string, key string, optional bool) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key, Optional: &optional,
+	}}
+}
+
+func secretKeyRef(name string, key string) *corev1.EnvVarSource {
+	return &corev1.EnvVarSource{SecretKeyRef: &corev1.SecretKeySelector{
+		LocalObjectReference: corev1.LocalObjectReference{Name: name}, Key: key,
+	}}
+}
+
+// referencingDaemonSet - daemonset reading env vars from the ovn-connection
+// ConfigMap, the neutron-secret Secret and all keys of the agent-env ConfigMap
+func referencingDaemonSet() *appsv1.DaemonSet {
+	ds := testDaemonSet("agent:1",
+		corev1.EnvVar{Name: "SB", ValueFrom: configMapKeyRef("ovn-connection", "SBConnection", false)},
+		corev1.EnvVar{Name: "NODE_NAME", ValueFrom: &corev1.EnvVarSource{FieldRef: &corev1.ObjectFieldSelector{FieldPath: "spec.nodeName"}}},
+		corev1.EnvVar{Name: "DEBUG", Value: "true"},
+	)
+	ds.Spec.Template.Spec.Containers[0].EnvFrom = []corev1.EnvFromSource{
+		{ConfigMapRef: &corev1.ConfigMapEnvSource{LocalObjectReference: corev1.LocalObjectReference{Name: "agent-env"}}},
+	}
+	ds.Spec.Template.Spec.InitContainers = []corev1.Container{{
+		Name: "init",
+		Env:  []corev1.EnvVar{{Name: "PASSWORD", ValueFrom: secretKeyRef("neutron-secret", "NeutronPassword")}},
+	}}
+	return ds
+}
+
+func TestGetReferences(t *testing.T) {
+	ds := referencingDaemonSet()
+	optional := true
+	ds.Spec.Template.Spec.Containers[0].EnvFrom = append(ds.Spec.Template.Spec.Containers[0].EnvFrom,
+		corev1.EnvFromSource{SecretRef: &corev1.SecretEnvSource{
+			LocalObjectReference: corev1.LocalObjectReference{Name: "extra"}, Optional: &optional,
+		}})
+	// mounted ConfigMaps are updated by the kubelet
+	ds.Spec.Template.Spec.Volumes = []corev1.Volume{{Name: "scripts", VolumeSource: corev1.VolumeSource{
+		ConfigMap: &corev1.ConfigMapVolumeSource{LocalObjectReference: corev1.LocalObjectReference{Name: "scripts"}},
+	}}}
+
+	want := []reference{
+		{kind: "Secret", name: "neutron-secret", key: "NeutronPassword"},
+		{kind: "ConfigMap", name: "ovn-connection", key: "SBConnection"},
+		{kind: "ConfigMap", name: "agent-env"},
+		{kind: "Secret", name: "extra", optional: true},
+	}
+	if got := getReferences(&ds.Spec.Template.Spec); !reflect.DeepEqual(got, want) {
+		t.Errorf("got %+v, want %+v", got, want)
+	}
+}
+
+func referencedObjects(sbConnection string, agentEnv map[string]string) []runtime.Object {
+	return []runtime.Object{
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "ovn-connection", Namespace: testNamespace},
+			Data:       map[string]string{"SBConnection": sbConnection, "NBConnection": "tcp:192.168.122.10:6641"},
+		},
+		&corev1.ConfigMap{
+			ObjectMeta: metav1.ObjectMeta{Name: "agent-env", Namespace: testNamespace},
+			Data:       agentEnv,
+		},
+		&corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{Name: "neutron-secret", Namespace: testNamespace},
+			Data:       map[string][]byte{"NeutronPassword": []byte("password")},
+		},
+	}
+}
+
+func referencesHash(t *testing.T, objs ...runtime.Object) (string, []string) {
+	t.Helper()
+	ds := referencingDaemonSet()
+	missing, err := SetReferencesHash(testClient(t, objs...), ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return ds.Spec.Template.Annotations[ReferencesHashAnnotation], missing
+}
+
+func TestSetReferencesHash(t *testing.T) {
+	hash, missing := referencesHash(t, referencedObjects("tcp:192.168.122.10:6642", map[string]string{"A": "1"})...)
+	if hash == "" {
+		t.Fatal("no references hash")
+	}
+	if len(missing) != 0 {
+		t.Errorf("missing: got %v, want none", missing)
+	}
+
+	tests := []struct {
+		name    string
+		objs    []runtime.Object
+		changed bool
+		missing []string
+	}{
+		{
+			name: "same data",
+			objs: referencedObjects("tcp:192.168.122.10:6642", map[string]string{"A": "1"}),
+		},
+		{
+			name:    "referenced key changed",
+			objs:    referencedObjects("tcp:192.168.122.11:6642", map[string]string{"A": "1"}),
+			changed: true,
+		},
+		{
+			name: "other key changed",
+			objs: func() []runtime.Object {
+				objs := referencedObjects("tcp:192.168.122.10:6642", map[string]string{"A": "1"})
+				objs[0].(*corev1.ConfigMap).Data["NBConnection"] = "tcp:192.168.122.11:6641"
+				return objs
+			}(),
+		},
+		{
+			name:    "env from ConfigMap changed",
+			objs:    referencedObjects("tcp:192.168.122.10:6642", map[string]string{"A": "1", "B": "2"}),
+			changed: true,
+		},
+		{
+			name:    "ConfigMap missing",
+			objs:    referencedObjects("", map[string]string{"A": "1"})[1:],
+			changed: true,
+			missing: []string{"ConfigMap ovn-connection"},
+		},
+		{
+			name: "key missing",
+			objs: func() []runtime.Object {
+				objs := referencedObjects("", map[string]string{"A": "1"})
+				delete(objs[0].(*corev1.ConfigMap).Data, "SBConnection")
+				return objs[:2]
+			}(),
+			changed: true,
+			missing: []string{"ConfigMap ovn-connection key SBConnection", "Secret neutron-secret"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			newHash, missing := referencesHash(t, test.objs...)
+			if (newHash != hash) != test.changed {
+				t.Errorf("hash changed: got %t, want %t", newHash != hash, test.changed)
+			}
+			if len(missing) != len(test.missing) || (len(missing) > 0 && !reflect.DeepEqual(missing, test.missing)) {
+				t.Errorf("missing: got %v, want %v", missing, test.missing)
+			}
+		})
+	}
+}
+
+func TestSetReferencesHashOptional(t *testing.T) {
+	ds := testDaemonSet("agent:1", corev1.EnvVar{Name: "SB", ValueFrom: configMapKeyRef("ovn-connection", "SBConnection", true)})
+	missing, err := SetReferencesHash(testClient(t), ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(missing) != 0 {
+		t.Errorf("optional reference reported missing: %v", missing)
+	}
+}
+
+// fieldIndexer keeps the index functions
+type fieldIndexer map[string]client.IndexerFunc
+
+func (i fieldIndexer) IndexField(ctx context.Context, obj runtime.Object, field string, extractValue client.IndexerFunc) error {
+	i[field] = extractValue
+	return nil
+}
+
+func ownedDaemonSet(kind string, owner string) *appsv1.DaemonSet {
+	ds := referencingDaemonSet()
+	ds.Name = owner
+	controller := true
+	ds.OwnerReferences = []metav1.OwnerReference{{Kind: kind, Name: owner, Controller: &controller}}
+	return ds
+}
+
+func TestIndexReferences(t *testing.T) {
+	indexer := fieldIndexer{}
+	if err := IndexReferences(indexer, "OVSNodeOsp"); err != nil {
+		t.Fatal(err)
+	}
+	index, ok := indexer[referencesField("OVSNodeOsp")]
+	if !ok {
+		t.Fatalf("no index %s", referencesField("OVSNodeOsp"))
+	}
+
+	got := index(ownedDaemonSet("OVSNodeOsp", "ovs-node-osp"))
+	sort.Strings(got)
+	want := []string{"ConfigMap/agent-env", "ConfigMap/ovn-connection", "Secret/neutron-secret"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	if got := index(ownedDaemonSet("OVNController", "ovn-controller")); len(got) != 0 {
+		t.Errorf("DaemonSet of another kind indexed: %v", got)
+	}
+}
+
+func TestReferenceToOwner(t *testing.T) {
+	c := testClient(t, ownedDaemonSet("OVSNodeOsp", "ovs-node-osp"), ownedDaemonSet("OVNController", "ovn-controller"))
+	toOwner := ReferenceToOwner(c, "OVSNodeOsp")
+
+	mapObject := func(object runtime.Object) handler.MapObject {
+		meta, _ := object.(metav1.Object)
+		return handler.MapObject{Meta: meta, Object: object}
+	}
+	want := []reconcile.Request{{NamespacedName: types.NamespacedName{Name: "ovs-node-osp", Namespace: testNamespace}}}
+
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "neutron-secret", Namespace: testNamespace}}
+	if got := toOwner(mapObject(secret)); !reflect.DeepEqual(got, want) {
+		t.Errorf("referenced Secret: got %v, want %v", got, want)
+	}
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "agent-env", Namespace: testNamespace}}
+	if got := toOwner(mapObject(configMap)); !reflect.DeepEqual(got, want) {
+		t.Errorf("referenced ConfigMap: got %v, want %v", got, want)
+	}
+	// a Secret with the name of a referenced ConfigMap
+	secret = &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "agent-env", Namespace: testNamespace}}
+	if got := toOwner(mapObject(secret)); len(got) != 0 {
+		t.Errorf("unreferenced Secret: got %v", got)
+	}
+	configMap = &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "other", Namespace: testNamespace}}
+	if got := toOwner(mapObject(configMap)); len(got) != 0 {
+		t.Errorf("unreferenced ConfigMap: got %v", got)
+	}
+}
+
+func TestDataChanged(t *testing.T) {
+	configMap := &corev1.ConfigMap{ObjectMeta: metav1.ObjectMeta{Name: "c", ResourceVersion: "1"}, Data: map[string]string{"a": "1"}}
+	annotated := configMap.DeepCopy()
+	annotated.ResourceVersion = "2"
+	annotated.Annotations = map[string]string{"control-plane.alpha.kubernetes.io/leader": "{}"}
+	changed := configMap.DeepCopy()
+	changed.Data["a"] = "2"
+	secret := &corev1.Secret{ObjectMeta: metav1.ObjectMeta{Name: "s", ResourceVersion: "1"}, Data: map[string][]byte{"a": []byte("1")}}
+	changedSecret := secret.DeepCopy()
+	changedSecret.Data["a"] = []byte("2")
+
+	tests := []struct {
+		name    string
+		old     runtime.Object
+		updated runtime.Object
+		want    bool
+	}{
+		{name: "ConfigMap metadata", old: configMap, updated: annotated},
+		{name: "ConfigMap data", old: configMap, updated: changed, want: true},
+		{name: "Secret metadata", old: secret, updated: secret.DeepCopy()},
+		{name: "Secret data", old: secret, updated: changedSecret, want: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			if got := DataChanged.Update(event.UpdateEvent{ObjectOld: test.old, ObjectNew: test.updated}); got != test.want {
+				t.Errorf("got %t, want %t", got, test.want)
+			}
+		})
+	}
+}
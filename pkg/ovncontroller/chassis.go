@@ -0,0 +1,124 @@
+package ovncontroller
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb"
+)
+
+// Reasons of the nodes without a matching chassis
+const (
+	// ChassisReasonMissing - the SB DB has no chassis of the node
+	ChassisReasonMissing string = "Missing"
+	// ChassisReasonHostnameMismatch - the chassis of the node has another hostname
+	ChassisReasonHostnameMismatch string = "HostnameMismatch"
+	// ChassisReasonEncapMismatch - the chassis of the node has no encap with the node IP
+	ChassisReasonEncapMismatch string = "EncapMismatch"
+)
+
+// sbDatabase - name of the OVN southbound database schema
+const sbDatabase string = "OVN_Southbound"
+
+// Chassis - a chassis registered in the SB DB
+type Chassis struct {
+	Name     string
+	Hostname string
+	EncapIPs []string
+}
+
+// GetChassis - reads the chassis and their encap IPs from the SB DB at the
+// remotes of the ovn-connection ConfigMap, within timeout. ssl remotes are
+// not supported, see ovsdb.Dial.
+func GetChassis(remotes string, timeout time.Duration) (map[string]Chassis, error) {
+	client, err := ovsdb.Dial(remotes, timeout)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	chassisRows, err := client.Select(sbDatabase, "Chassis", []string{"name", "hostname"})
+	if err != nil {
+		return nil, err
+	}
+	encapRows, err := client.Select(sbDatabase, "Encap", []string{"chassis_name", "ip"})
+	if err != nil {
+		return nil, err
+	}
+
+	chassis := map[string]Chassis{}
+	for _, row := range chassisRows {
+		name := ovsdb.GetString(row, "name")
+		chassis[name] = Chassis{
+			Name:     name,
+			Hostname: ovsdb.GetString(row, "hostname"),
+		}
+	}
+	for _, row := range encapRows {
+		c, ok := chassis[ovsdb.GetString(row, "chassis_name")]
+		if !ok {
+			continue
+		}
+		c.EncapIPs = append(c.EncapIPs, ovsdb.GetString(row, "ip"))
+		chassis[c.Name] = c
+	}
+	return chassis, nil
+}
+
+// GetChassisRegistration - compares the nodes of the role with the chassis.
+// encapIPs are the encap IPs reported by the nodes, the encap is not checked
+// for the nodes which did not report one yet. Chassis of the naming scheme
+// are stale if their node is not in existingNodes.
+func GetChassisRegistration(cr *neutronv1.OVNController, roleNodes []string, encapIPs map[string]string,
+	existingNodes map[string]bool, chassis map[string]Chassis) neutronv1.ChassisRegistration {
+
+	registration := neutronv1.ChassisRegistration{}
+	for _, node := range roleNodes {
+		name := common.GetChassisName(cr.Spec.ChassisNamePattern, node)
+		unregistered := neutronv1.UnregisteredChassis{
+			Node:    node,
+			Chassis: name,
+		}
+
+		c, ok := chassis[name]
+		switch {
+		case !ok:
+			unregistered.Reason = ChassisReasonMissing
+			unregistered.Message = fmt.Sprintf("no chassis %s in the southbound database", name)
+		case c.Hostname != node:
+			unregistered.Reason = ChassisReasonHostnameMismatch
+			unregistered.Message = fmt.Sprintf("chassis %s has hostname %q", name, c.Hostname)
+		case encapIPs[node] != "" && !containsString(c.EncapIPs, encapIPs[node]):
+			unregistered.Reason = ChassisReasonEncapMismatch
+			unregistered.Message = fmt.Sprintf("chassis %s has encap IPs %v, the node uses %s", name, c.EncapIPs, encapIPs[node])
+		default:
+			registration.Registered++
+			continue
+		}
+		registration.Unregistered = append(registration.Unregistered, unregistered)
+	}
+
+	for name := range chassis {
+		hostname, ok := common.GetChassisHostname(cr.Spec.ChassisNamePattern, name)
+		if ok && !existingNodes[hostname] {
+			registration.Stale = append(registration.Stale, name)
+		}
+	}
+	sort.Slice(registration.Unregistered, func(i, j int) bool {
+		return registration.Unregistered[i].Node < registration.Unregistered[j].Node
+	})
+	sort.Strings(registration.Stale)
+	return registration
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
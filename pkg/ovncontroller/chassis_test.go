@@ -0,0 +1,146 @@
+package ovncontroller
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb/ovsdbtest"
+)
+
+func rows(t *testing.T, values ...string) []ovsdb.Row {
+	t.Helper()
+	result := []ovsdb.Row{}
+	for _, value := range values {
+		row := ovsdb.Row{}
+		if err := json.Unmarshal([]byte(value), &row); err != nil {
+			t.Fatal(err)
+		}
+		result = append(result, row)
+	}
+	return result
+}
+
+func TestGetChassis(t *testing.T) {
+	server, err := ovsdbtest.NewServer(sbDatabase, map[string][]ovsdb.Row{
+		"Chassis": rows(t,
+			`{"name": "compute-0-osp", "hostname": "compute-0"}`,
+			`{"name": "compute-1-osp", "hostname": ["set", []]}`,
+		),
+		"Encap": rows(t,
+			`{"chassis_name": "compute-0-osp", "ip": "172.19.0.10", "type": "geneve"}`,
+			`{"chassis_name": "compute-0-osp", "ip": "172.19.0.10", "type": "vxlan"}`,
+			`{"chassis_name": "deleted-osp", "ip": "172.19.0.12", "type": "geneve"}`,
+		),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	chassis, err := GetChassis(server.Remote(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := map[string]Chassis{
+		"compute-0-osp": {Name: "compute-0-osp", Hostname: "compute-0", EncapIPs: []string{"172.19.0.10", "172.19.0.10"}},
+		"compute-1-osp": {Name: "compute-1-osp"},
+	}
+	if !reflect.DeepEqual(chassis, want) {
+		t.Errorf("got %+v, want %+v", chassis, want)
+	}
+}
+
+func TestGetChassisUnsupportedRemote(t *testing.T) {
+	_, err := GetChassis("ssl:192.168.122.10:6642", time.Second)
+	if _, ok := err.(*ovsdb.UnsupportedRemoteError); !ok {
+		t.Errorf("expected an UnsupportedRemoteError, got %v", err)
+	}
+}
+
+func TestGetChassisTimeout(t *testing.T) {
+	server, err := ovsdbtest.NewServer(sbDatabase, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Stall = true
+
+	start := time.Now()
+	if _, err := GetChassis(server.Remote(), 200*time.Millisecond); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("GetChassis took %s, more than its timeout", elapsed)
+	}
+}
+
+func TestGetChassisRegistration(t *testing.T) {
+	cr := &neutronv1.OVNController{}
+	chassis := map[string]Chassis{
+		"compute-0-osp": {Name: "compute-0-osp", Hostname: "compute-0", EncapIPs: []string{"172.19.0.10"}},
+		"compute-1-osp": {Name: "compute-1-osp", Hostname: "compute-1.localdomain", EncapIPs: []string{"172.19.0.11"}},
+		"compute-2-osp": {Name: "compute-2-osp", Hostname: "compute-2", EncapIPs: []string{"172.19.0.99"}},
+		"compute-4-osp": {Name: "compute-4-osp", Hostname: "compute-4", EncapIPs: []string{"172.19.0.14"}},
+		"deleted-osp":   {Name: "deleted-osp", Hostname: "deleted"},
+		// other naming scheme, e.g. of the OSP controllers
+		"controller-0.localdomain": {Name: "controller-0.localdomain", Hostname: "controller-0"},
+	}
+	roleNodes := []string{"compute-0", "compute-1", "compute-2", "compute-3", "compute-4"}
+	encapIPs := map[string]string{
+		"compute-0": "172.19.0.10",
+		"compute-1": "172.19.0.11",
+		"compute-2": "172.19.0.12",
+		// compute-4 did not report its encap IP yet
+	}
+	existingNodes := map[string]bool{"compute-0": true, "compute-1": true, "compute-2": true, "compute-3": true,
+		"compute-4": true, "worker-0": true}
+
+	registration := GetChassisRegistration(cr, roleNodes, encapIPs, existingNodes, chassis)
+	if registration.Registered != 2 {
+		t.Errorf("registered: got %d, want 2 (compute-0 and compute-4)", registration.Registered)
+	}
+	reasons := map[string]string{}
+	for _, unregistered := range registration.Unregistered {
+		if unregistered.Chassis != unregistered.Node+"-osp" {
+			t.Errorf("chassis of %s: got %s", unregistered.Node, unregistered.Chassis)
+		}
+		reasons[unregistered.Node] = unregistered.Reason
+	}
+	wantReasons := map[string]string{
+		"compute-1": ChassisReasonHostnameMismatch,
+		"compute-2": ChassisReasonEncapMismatch,
+		"compute-3": ChassisReasonMissing,
+	}
+	if !reflect.DeepEqual(reasons, wantReasons) {
+		t.Errorf("reasons: got %v, want %v", reasons, wantReasons)
+	}
+	if nodes := []string{registration.Unregistered[0].Node, registration.Unregistered[2].Node}; nodes[0] != "compute-1" || nodes[1] != "compute-3" {
+		t.Errorf("unregistered nodes not sorted: %+v", registration.Unregistered)
+	}
+	if want := []string{"deleted-osp"}; !reflect.DeepEqual(registration.Stale, want) {
+		t.Errorf("stale: got %v, want %v", registration.Stale, want)
+	}
+}
+
+func TestGetChassisRegistrationPattern(t *testing.T) {
+	cr := &neutronv1.OVNController{Spec: neutronv1.OVNControllerSpec{ChassisNamePattern: "ovn-{hostname}"}}
+	chassis := map[string]Chassis{
+		"ovn-compute-0": {Name: "ovn-compute-0", Hostname: "compute-0"},
+		"ovn-deleted":   {Name: "ovn-deleted", Hostname: "deleted"},
+		"compute-1-osp": {Name: "compute-1-osp", Hostname: "compute-1"},
+	}
+	registration := GetChassisRegistration(cr, []string{"compute-0", "compute-1"}, nil,
+		map[string]bool{"compute-0": true, "compute-1": true}, chassis)
+
+	if registration.Registered != 1 || len(registration.Unregistered) != 1 ||
+		registration.Unregistered[0].Chassis != "ovn-compute-1" || registration.Unregistered[0].Reason != ChassisReasonMissing {
+		t.Errorf("got %+v, want compute-1 missing as ovn-compute-1", registration)
+	}
+	if want := []string{"ovn-deleted"}; !reflect.DeepEqual(registration.Stale, want) {
+		t.Errorf("stale: got %v, want %v", registration.Stale, want)
+	}
+}
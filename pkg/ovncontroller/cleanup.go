@@ -0,0 +1,61 @@
+package ovncontroller
+
+import (
+	"fmt"
+
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CleanupJob - job removing the chassis of the node from the SB DB. Unless
+// onNode is set, e.g. as the node is gone, the job runs on any node of the
+// role and leaves the host alone.
+func CleanupJob(cr *neutronv1.OVNController, cmName string, nodeName string, onNode bool) *batchv1.Job {
+	var optional = true
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "INTEGRATION_BRIDGE",
+			Value: common.GetIntegrationBridge(cr.Spec.IntegrationBridge),
+		},
+		{
+			Name:  "CHASSIS_NAME_PATTERN",
+			Value: common.GetChassisNamePattern(cr.Spec.ChassisNamePattern),
+		},
+		{
+			Name:  "NODE_NAME",
+			Value: nodeName,
+		},
+		{
+			Name:  "CLEANUP_HOST",
+			Value: fmt.Sprintf("%t", onNode),
+		},
+		{
+			Name: "OVN_SB_REMOTE",
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: "ovn-connection",
+					},
+					Key: "SBConnection",
+					// the chassis is left in place if the SB DB is gone
+					Optional: &optional,
+				},
+			},
+		},
+	}
+
+	volumeMounts := append(common.GetVolumeMounts(), GetVolumeMounts(cmName)...)
+	volumes := append(common.GetVolumes(cmName), GetVolumes(cmName)...)
+
+	job := common.CleanupJob(common.GetCleanupJobName(cr.Name, nodeName), cr.Namespace, nodeName,
+		cr.Spec.OvnControllerImage, cr.Spec.ServiceAccount, env, volumes, volumeMounts)
+	if !onNode {
+		job.Spec.Template.Spec.NodeName = ""
+		job.Spec.Template.Spec.NodeSelector = common.GetComputeWorkerNodeSelector(cr.Spec.RoleName)
+	}
+	return job
+}
@@ -22,7 +22,10 @@ func ScriptsConfigMap(cr *neutronv1.OVNController, cmName string) *corev1.Config
 			Namespace: cr.Namespace,
 		},
 		Data: map[string]string{
-			"ovn.sh": util.ExecuteTemplateFile(strings.ToLower(cr.Kind)+"/ovn.sh", nil),
+			"ovn.sh":     util.ExecuteTemplateFile(strings.ToLower(cr.Kind)+"/ovn.sh", nil),
+			"report.sh":  util.ExecuteTemplateFile("common/report.sh", nil),
+			"probe.sh":   util.ExecuteTemplateFile(strings.ToLower(cr.Kind)+"/probe.sh", nil),
+			"cleanup.sh": util.ExecuteTemplateFile(strings.ToLower(cr.Kind)+"/cleanup.sh", nil),
 		},
 	}
 
@@ -0,0 +1,135 @@
+package ovncontroller
+
+import (
+	"strconv"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DaemonSet - the ovn-controller DaemonSet running on the compute nodes of the role
+func DaemonSet(cr *neutronv1.OVNController, cmName string, hostAliases []corev1.HostAlias) *appsv1.DaemonSet {
+	var trueVar = true
+
+	daemonSet := appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"daemonset": cr.Name + "-daemonset"},
+			},
+			UpdateStrategy:  common.GetDaemonsetUpdateStrategy(cr.Spec.UpgradeStrategy),
+			MinReadySeconds: common.GetMinReadySeconds(cr.Spec.UpgradeStrategy),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"daemonset": cr.Name + "-daemonset"},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       common.GetComputeWorkerNodeSelector(cr.Spec.RoleName),
+					HostNetwork:        true,
+					HostPID:            true,
+					DNSPolicy:          "ClusterFirstWithHostNet",
+					HostAliases:        hostAliases,
+					Containers:         []corev1.Container{},
+					Tolerations:        []corev1.Toleration{},
+					ServiceAccountName: cr.Spec.ServiceAccount,
+					PriorityClassName:  "system-node-critical",
+				},
+			},
+		},
+	}
+
+	// add compute worker nodes tolerations
+	for _, toleration := range common.GetComputeWorkerTolerations(cr.Spec.RoleName) {
+		daemonSet.Spec.Template.Spec.Tolerations = append(daemonSet.Spec.Template.Spec.Tolerations, toleration)
+	}
+
+	containerSpec := corev1.Container{
+		Name:  "ovn-controller",
+		Image: cr.Spec.OvnControllerImage,
+		Command: []string{
+			"bash", "-c", "/usr/local/sbin/ovn.sh",
+		},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &trueVar,
+		},
+		ReadinessProbe: GetReadinessProbe(cr),
+		LivenessProbe:  GetLivenessProbe(cr),
+		StartupProbe:   GetStartupProbe(cr),
+		// keep the datapath flows while the pod gets replaced, the new
+		// ovn-controller picks them up
+		Lifecycle: &corev1.Lifecycle{
+			PreStop: &corev1.Handler{
+				Exec: &corev1.ExecAction{
+					Command: []string{
+						"ovn-appctl", "-t", "ovn-controller", "exit", "--restart",
+					},
+				},
+			},
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "OVN_LOG_LEVEL",
+				Value: cr.Spec.OvnLogLevel,
+			},
+			{
+				Name:  "K8S_NODE",
+				Value: cmName,
+			},
+			{
+				Name:  "CHASSIS_NAME_PATTERN",
+				Value: common.GetChassisNamePattern(cr.Spec.ChassisNamePattern),
+			},
+			{
+				Name:  "INTEGRATION_BRIDGE",
+				Value: common.GetIntegrationBridge(cr.Spec.IntegrationBridge),
+			},
+			{
+				Name:  "TUNNEL_CHECK_INTERVAL",
+				Value: strconv.Itoa(int(GetTunnelCheckInterval(cr))),
+			},
+			{
+				Name: "HOSTNAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "spec.nodeName",
+					},
+				},
+			},
+		},
+		VolumeMounts: []corev1.VolumeMount{},
+	}
+	// add report env vars
+	containerSpec.Env = append(containerSpec.Env, common.GetReportEnvVars(common.ReportConfigMapName(cr.Name))...)
+	// add common VolumeMounts
+	for _, volMount := range common.GetVolumeMounts() {
+		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
+	}
+	// add ovncontroller specific VolumeMounts
+	for _, volMount := range GetVolumeMounts(cmName) {
+		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
+	}
+
+	daemonSet.Spec.Template.Spec.Containers = append(daemonSet.Spec.Template.Spec.Containers, containerSpec)
+
+	// Volume config
+	// add common Volumes
+	for _, volConfig := range common.GetVolumes(cmName) {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+	// add ovncontroller Volumes
+	for _, volConfig := range GetVolumes(cmName) {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+
+	return &daemonSet
+}
@@ -0,0 +1,70 @@
+package ovncontroller
+
+import (
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Default thresholds of the probes
+var (
+	defaultReadinessThresholds = neutronv1.ProbeThresholds{PeriodSeconds: 10, TimeoutSeconds: 5, FailureThreshold: 3}
+	defaultLivenessThresholds  = neutronv1.ProbeThresholds{PeriodSeconds: 30, TimeoutSeconds: 10, FailureThreshold: 5}
+	defaultStartupThresholds   = neutronv1.ProbeThresholds{PeriodSeconds: 10, TimeoutSeconds: 10, FailureThreshold: 60}
+)
+
+// GetReadinessProbe - ovn-controller is connected to the SB DB and the
+// integration bridge exists
+func GetReadinessProbe(cr *neutronv1.OVNController) *corev1.Probe {
+	var thresholds *neutronv1.ProbeThresholds
+	if cr.Spec.Probes != nil {
+		thresholds = cr.Spec.Probes.Readiness
+	}
+	return getProbe("readiness", thresholds, defaultReadinessThresholds)
+}
+
+// GetLivenessProbe - ovn-controller answers, the local ovsdb is not checked as
+// it is down while the OVS pod restarts
+func GetLivenessProbe(cr *neutronv1.OVNController) *corev1.Probe {
+	var thresholds *neutronv1.ProbeThresholds
+	if cr.Spec.Probes != nil {
+		thresholds = cr.Spec.Probes.Liveness
+	}
+	return getProbe("liveness", thresholds, defaultLivenessThresholds)
+}
+
+// GetStartupProbe - ovn-controller answers, with time for the initial flow programming
+func GetStartupProbe(cr *neutronv1.OVNController) *corev1.Probe {
+	var thresholds *neutronv1.ProbeThresholds
+	if cr.Spec.Probes != nil {
+		thresholds = cr.Spec.Probes.Startup
+	}
+	return getProbe("startup", thresholds, defaultStartupThresholds)
+}
+
+// getProbe - probe running probe.sh, with the thresholds of the spec or the defaults
+func getProbe(probe string, thresholds *neutronv1.ProbeThresholds, defaults neutronv1.ProbeThresholds) *corev1.Probe {
+	if thresholds != nil {
+		if thresholds.PeriodSeconds != 0 {
+			defaults.PeriodSeconds = thresholds.PeriodSeconds
+		}
+		if thresholds.TimeoutSeconds != 0 {
+			defaults.TimeoutSeconds = thresholds.TimeoutSeconds
+		}
+		if thresholds.FailureThreshold != 0 {
+			defaults.FailureThreshold = thresholds.FailureThreshold
+		}
+	}
+
+	return &corev1.Probe{
+		Handler: corev1.Handler{
+			Exec: &corev1.ExecAction{
+				Command: []string{
+					"/usr/local/sbin/probe.sh", probe,
+				},
+			},
+		},
+		PeriodSeconds:    defaults.PeriodSeconds,
+		TimeoutSeconds:   defaults.TimeoutSeconds,
+		FailureThreshold: defaults.FailureThreshold,
+	}
+}
@@ -0,0 +1,83 @@
+package ovncontroller
+
+import (
+	"reflect"
+	"testing"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+)
+
+func TestGetProbe(t *testing.T) {
+	defaults := neutronv1.ProbeThresholds{PeriodSeconds: 30, TimeoutSeconds: 10, FailureThreshold: 5}
+	tests := []struct {
+		name       string
+		thresholds *neutronv1.ProbeThresholds
+		want       neutronv1.ProbeThresholds
+	}{
+		{
+			name: "defaults",
+			want: defaults,
+		},
+		{
+			name:       "empty",
+			thresholds: &neutronv1.ProbeThresholds{},
+			want:       defaults,
+		},
+		{
+			name:       "period only",
+			thresholds: &neutronv1.ProbeThresholds{PeriodSeconds: 15},
+			want:       neutronv1.ProbeThresholds{PeriodSeconds: 15, TimeoutSeconds: 10, FailureThreshold: 5},
+		},
+		{
+			name:       "timeout and failures",
+			thresholds: &neutronv1.ProbeThresholds{TimeoutSeconds: 3, FailureThreshold: 10},
+			want:       neutronv1.ProbeThresholds{PeriodSeconds: 30, TimeoutSeconds: 3, FailureThreshold: 10},
+		},
+		{
+			name:       "all",
+			thresholds: &neutronv1.ProbeThresholds{PeriodSeconds: 1, TimeoutSeconds: 2, FailureThreshold: 3},
+			want:       neutronv1.ProbeThresholds{PeriodSeconds: 1, TimeoutSeconds: 2, FailureThreshold: 3},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			probe := getProbe("liveness", tt.thresholds, defaults)
+			got := neutronv1.ProbeThresholds{
+				PeriodSeconds:    probe.PeriodSeconds,
+				TimeoutSeconds:   probe.TimeoutSeconds,
+				FailureThreshold: probe.FailureThreshold,
+			}
+			if got != tt.want {
+				t.Errorf("thresholds = %+v, want %+v", got, tt.want)
+			}
+			if want := []string{"/usr/local/sbin/probe.sh", "liveness"}; !reflect.DeepEqual(probe.Exec.Command, want) {
+				t.Errorf("command = %v, want %v", probe.Exec.Command, want)
+			}
+		})
+	}
+}
+
+func TestGetProbesOfSpec(t *testing.T) {
+	cr := &neutronv1.OVNController{}
+	if got := GetReadinessProbe(cr); got.PeriodSeconds != defaultReadinessThresholds.PeriodSeconds ||
+		got.FailureThreshold != defaultReadinessThresholds.FailureThreshold {
+		t.Errorf("readiness without probes in the spec = %+v, want the defaults", got)
+	}
+
+	// a partial override of one probe leaves the others at their defaults
+	cr.Spec.Probes = &neutronv1.OVNControllerProbes{
+		Liveness: &neutronv1.ProbeThresholds{FailureThreshold: 20},
+	}
+	liveness := GetLivenessProbe(cr)
+	if liveness.FailureThreshold != 20 || liveness.PeriodSeconds != defaultLivenessThresholds.PeriodSeconds ||
+		liveness.TimeoutSeconds != defaultLivenessThresholds.TimeoutSeconds {
+		t.Errorf("liveness = %+v, want failure threshold 20 and the default period and timeout", liveness)
+	}
+	startup := GetStartupProbe(cr)
+	if startup.FailureThreshold != defaultStartupThresholds.FailureThreshold {
+		t.Errorf("startup failure threshold = %d, want the default %d", startup.FailureThreshold, defaultStartupThresholds.FailureThreshold)
+	}
+	if startup.Exec.Command[1] != "startup" {
+		t.Errorf("startup probe runs %v", startup.Exec.Command)
+	}
+}
@@ -0,0 +1,131 @@
+package ovncontroller
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// defaultTunnelCheckInterval - seconds between the probes if not set in the spec
+const defaultTunnelCheckInterval int32 = 60
+
+// GetTunnelCheckInterval - returns the seconds between the probes, 0 if the
+// tunnel check is disabled
+func GetTunnelCheckInterval(cr *neutronv1.OVNController) int32 {
+	if cr.Spec.TunnelCheck == nil {
+		return 0
+	}
+	if cr.Spec.TunnelCheck.IntervalSeconds == 0 {
+		return defaultTunnelCheckInterval
+	}
+	return cr.Spec.TunnelCheck.IntervalSeconds
+}
+
+// TunnelPeersConfigMap - config map with the encap IP of each node, keyed by
+// node name. Empty if the tunnel check is disabled.
+func TunnelPeersConfigMap(cr *neutronv1.OVNController, cmName string) *corev1.ConfigMap {
+	peers := map[string]string{}
+	if GetTunnelCheckInterval(cr) > 0 {
+		for _, node := range cr.Status.Nodes {
+			if node.EncapIP != "" {
+				peers[node.Node] = node.EncapIP
+			}
+		}
+	}
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Data: peers,
+	}
+
+	return cm
+}
+
+// GetTunnelStatus - builds the reachability matrix from the tunnel-peers,
+// tunnel-unreachable and tunnel-bfd-down reports of the nodes, and splits the
+// nodes into partitions if they do not all reach each other. A node with BFD
+// down to a peer does not reach it, even if the peer answers the pings.
+func GetTunnelStatus(nodes []neutronv1.NodeStatus, reports map[string]map[string]string) *neutronv1.TunnelStatus {
+	status := &neutronv1.TunnelStatus{}
+	unreachable := map[string]map[string]bool{}
+	for _, node := range nodes {
+		report := reports[node.Node]
+		peers, ok := report["tunnel-peers"]
+		if !ok {
+			continue
+		}
+		count, _ := strconv.Atoi(peers)
+		row := neutronv1.TunnelReachability{
+			Node:        node.Node,
+			Peers:       int32(count),
+			Unreachable: reportedNodes(report["tunnel-unreachable"]),
+			BFDDown:     reportedNodes(report["tunnel-bfd-down"]),
+		}
+		row.Reachable = row.Peers - int32(len(row.Unreachable))
+		unreachable[node.Node] = map[string]bool{}
+		for _, peer := range append(row.Unreachable, row.BFDDown...) {
+			unreachable[node.Node][peer] = true
+		}
+		status.Matrix = append(status.Matrix, row)
+	}
+	sort.Slice(status.Matrix, func(i, j int) bool {
+		return status.Matrix[i].Node < status.Matrix[j].Node
+	})
+
+	partitions := getTunnelPartitions(status.Matrix, unreachable)
+	if len(partitions) > 1 {
+		status.Partitions = partitions
+	}
+	return status
+}
+
+// reportedNodes splits a report listing node names, nil if it lists none so
+// the row compares equal to the one read back from the status
+func reportedNodes(report string) []string {
+	if nodes := strings.Fields(report); len(nodes) > 0 {
+		return nodes
+	}
+	return nil
+}
+
+// getTunnelPartitions groups the nodes of the matrix, two nodes are in the
+// same group if neither of them failed to reach the other, directly or
+// through other nodes of the group
+func getTunnelPartitions(matrix []neutronv1.TunnelReachability, unreachable map[string]map[string]bool) []neutronv1.TunnelPartition {
+	partitions := []neutronv1.TunnelPartition{}
+	assigned := map[string]bool{}
+	for _, row := range matrix {
+		if assigned[row.Node] {
+			continue
+		}
+		partition := neutronv1.TunnelPartition{}
+		queue := []string{row.Node}
+		assigned[row.Node] = true
+		for len(queue) > 0 {
+			node := queue[0]
+			queue = queue[1:]
+			partition.Nodes = append(partition.Nodes, node)
+			for _, peer := range matrix {
+				if assigned[peer.Node] || unreachable[node][peer.Node] || unreachable[peer.Node][node] {
+					continue
+				}
+				assigned[peer.Node] = true
+				queue = append(queue, peer.Node)
+			}
+		}
+		sort.Strings(partition.Nodes)
+		partitions = append(partitions, partition)
+	}
+	return partitions
+}
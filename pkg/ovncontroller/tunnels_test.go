@@ -0,0 +1,79 @@
+package ovncontroller
+
+import (
+	"reflect"
+	"testing"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+)
+
+func TestGetTunnelStatus(t *testing.T) {
+	nodes := []neutronv1.NodeStatus{{Node: "worker-2"}, {Node: "worker-0"}, {Node: "worker-1"}}
+	tests := []struct {
+		name       string
+		reports    map[string]map[string]string
+		matrix     []neutronv1.TunnelReachability
+		partitions []neutronv1.TunnelPartition
+	}{
+		{
+			name: "no reports",
+		},
+		{
+			name: "all reachable",
+			reports: map[string]map[string]string{
+				"worker-0": {"tunnel-peers": "2", "tunnel-unreachable": "", "tunnel-bfd-down": ""},
+				"worker-1": {"tunnel-peers": "2"},
+			},
+			matrix: []neutronv1.TunnelReachability{
+				{Node: "worker-0", Peers: 2, Reachable: 2},
+				{Node: "worker-1", Peers: 2, Reachable: 2},
+			},
+		},
+		{
+			name: "ping fails",
+			reports: map[string]map[string]string{
+				"worker-0": {"tunnel-peers": "2", "tunnel-unreachable": "worker-2"},
+				"worker-1": {"tunnel-peers": "2", "tunnel-unreachable": "worker-2"},
+				"worker-2": {"tunnel-peers": "2", "tunnel-unreachable": "worker-0 worker-1"},
+			},
+			matrix: []neutronv1.TunnelReachability{
+				{Node: "worker-0", Peers: 2, Reachable: 1, Unreachable: []string{"worker-2"}},
+				{Node: "worker-1", Peers: 2, Reachable: 1, Unreachable: []string{"worker-2"}},
+				{Node: "worker-2", Peers: 2, Unreachable: []string{"worker-0", "worker-1"}},
+			},
+			partitions: []neutronv1.TunnelPartition{
+				{Nodes: []string{"worker-0", "worker-1"}},
+				{Nodes: []string{"worker-2"}},
+			},
+		},
+		{
+			// the pings pass but the Geneve UDP port is blocked
+			name: "bfd down",
+			reports: map[string]map[string]string{
+				"worker-0": {"tunnel-peers": "2", "tunnel-bfd-down": "worker-2"},
+				"worker-1": {"tunnel-peers": "2", "tunnel-bfd-down": "worker-2"},
+				"worker-2": {"tunnel-peers": "2"},
+			},
+			matrix: []neutronv1.TunnelReachability{
+				{Node: "worker-0", Peers: 2, Reachable: 2, BFDDown: []string{"worker-2"}},
+				{Node: "worker-1", Peers: 2, Reachable: 2, BFDDown: []string{"worker-2"}},
+				{Node: "worker-2", Peers: 2, Reachable: 2},
+			},
+			partitions: []neutronv1.TunnelPartition{
+				{Nodes: []string{"worker-0", "worker-1"}},
+				{Nodes: []string{"worker-2"}},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			status := GetTunnelStatus(nodes, tt.reports)
+			if !reflect.DeepEqual(status.Matrix, tt.matrix) {
+				t.Errorf("matrix = %+v, want %+v", status.Matrix, tt.matrix)
+			}
+			if !reflect.DeepEqual(status.Partitions, tt.partitions) {
+				t.Errorf("partitions = %+v, want %+v", status.Partitions, tt.partitions)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 // GetVolumes - Volumes used by pod
 func GetVolumes(cmName string) []corev1.Volume {
 	var scriptsVolumeDefaultMode int32 = 0755
+	var optional = true
 	return []corev1.Volume{
 		{
 			Name: "host-run-netns",
@@ -67,6 +68,18 @@ func GetVolumes(cmName string) []corev1.Volume {
 				},
 			},
 		},
+		{
+			Name: cmName + "-tunnel-peers",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName + "-tunnel-peers",
+					},
+					// not needed by the cleanup jobs
+					Optional: &optional,
+				},
+			},
+		},
 	}
 
 }
@@ -99,6 +112,11 @@ func GetVolumeMounts(cmName string) []corev1.VolumeMount {
 			ReadOnly:  true,
 			MountPath: "/usr/local/sbin/",
 		},
+		{
+			Name:      cmName + "-tunnel-peers",
+			ReadOnly:  true,
+			MountPath: "/etc/ovn-controller/tunnel-peers",
+		},
 	}
 
 }
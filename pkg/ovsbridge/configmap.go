@@ -0,0 +1,121 @@
+package ovsbridge
+
+import (
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RoleLabel - label of the bridges config map with the role name
+const RoleLabel string = "neutron.openstack.org/ovs-bridges-role"
+
+// ConfigMapName - name of the config map holding the bridges of a role
+func ConfigMapName(roleName string) string {
+	return "ovs-bridges-" + roleName
+}
+
+// ConfigMap - config map holding the bridges of a role, keyed by OVSBridge name.
+// It is shared by all OVSBridges of the role and mounted by the OVSNodeOsp pods.
+func ConfigMap(namespace string, roleName string) *corev1.ConfigMap {
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      ConfigMapName(roleName),
+			Namespace: namespace,
+			Labels:    map[string]string{RoleLabel: roleName},
+		},
+		Data: map[string]string{},
+	}
+
+	return cm
+}
+
+// Validate - checks the ports of the bridge
+func Validate(cr *neutronv1.OVSBridge) error {
+	names := map[string]bool{}
+	for _, port := range cr.Spec.Ports {
+		if names[port.Name] {
+			return fmt.Errorf("port %s defined more than once", port.Name)
+		}
+		names[port.Name] = true
+		if port.Type == "patch" && port.Peer == "" {
+			return fmt.Errorf("port %s: patch ports need a peer", port.Name)
+		}
+		if port.Type != "patch" && port.Peer != "" {
+			return fmt.Errorf("port %s: only patch ports can have a peer", port.Name)
+		}
+		if port.Type != "internal" && len(port.Addresses) > 0 {
+			return fmt.Errorf("port %s: only internal ports can have addresses", port.Name)
+		}
+		for _, address := range port.Addresses {
+			if _, _, err := net.ParseCIDR(address); err != nil {
+				return fmt.Errorf("port %s: invalid address %s", port.Name, address)
+			}
+		}
+	}
+	return nil
+}
+
+// GetDuplicates - returns the other OVSBridges of the role declaring the same
+// bridge, and whether the instance keeps the bridge. The oldest of them keeps
+// it, the others are not rendered so that the bridge of a node does not flip
+// between their specs.
+func GetDuplicates(cr *neutronv1.OVSBridge, instances []neutronv1.OVSBridge) ([]string, bool) {
+	duplicates := []string{}
+	keeps := true
+	for i := range instances {
+		other := &instances[i]
+		if other.Name == cr.Name || other.Spec.RoleName != cr.Spec.RoleName || other.Spec.BridgeName != cr.Spec.BridgeName {
+			continue
+		}
+		duplicates = append(duplicates, other.Name)
+		if isOlder(other, cr) {
+			keeps = false
+		}
+	}
+	sort.Strings(duplicates)
+	return duplicates, keeps
+}
+
+// isOlder - checks if a got created before b, the name breaks ties
+func isOlder(a *neutronv1.OVSBridge, b *neutronv1.OVSBridge) bool {
+	if !a.CreationTimestamp.Equal(&b.CreationTimestamp) {
+		return a.CreationTimestamp.Before(&b.CreationTimestamp)
+	}
+	return a.Name < b.Name
+}
+
+// Render - renders the bridge for ovsnode.sh, a "bridge <name> <fail mode>"
+// line followed by a "port <name> <type> <vlan tag> <peer> <addresses>" line
+// per port, with - for unset values
+func Render(cr *neutronv1.OVSBridge) string {
+	lines := fmt.Sprintf("bridge %s %s\n", cr.Spec.BridgeName, orDash(cr.Spec.FailMode))
+	for _, port := range cr.Spec.Ports {
+		portType := port.Type
+		if portType == "" {
+			portType = "system"
+		}
+		tag := "-"
+		if port.VLANTag != nil {
+			tag = fmt.Sprintf("%d", *port.VLANTag)
+		}
+		lines += fmt.Sprintf("port %s %s %s %s %s\n", port.Name, portType, tag, orDash(port.Peer), orDash(strings.Join(port.Addresses, ",")))
+	}
+	return lines
+}
+
+func orDash(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
@@ -0,0 +1,55 @@
+package ovsbridge
+
+import (
+	"reflect"
+	"testing"
+	"time"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func testBridge(name string, role string, bridge string, created time.Time) neutronv1.OVSBridge {
+	return neutronv1.OVSBridge{
+		ObjectMeta: metav1.ObjectMeta{Name: name, CreationTimestamp: metav1.NewTime(created)},
+		Spec:       neutronv1.OVSBridgeSpec{RoleName: role, BridgeName: bridge},
+	}
+}
+
+func TestGetDuplicates(t *testing.T) {
+	now := time.Now().Truncate(time.Second)
+	instances := []neutronv1.OVSBridge{
+		testBridge("ex-new", "worker-osp", "br-ex", now),
+		testBridge("ex-old", "worker-osp", "br-ex", now.Add(-time.Hour)),
+		testBridge("ex-other-role", "worker-dpdk", "br-ex", now.Add(-2*time.Hour)),
+		testBridge("tenant", "worker-osp", "br-tenant", now.Add(-2*time.Hour)),
+		testBridge("tenant-b", "worker-osp", "br-tenant", now.Add(-2*time.Hour)),
+		testBridge("tenant-a", "worker-osp", "br-tenant", now.Add(-2*time.Hour)),
+	}
+	tests := []struct {
+		name       string
+		duplicates []string
+		keeps      bool
+	}{
+		{name: "ex-new", duplicates: []string{"ex-old"}, keeps: false},
+		{name: "ex-old", duplicates: []string{"ex-new"}, keeps: true},
+		{name: "ex-other-role", duplicates: []string{}, keeps: true},
+		// same creation time, the name breaks the tie
+		{name: "tenant", duplicates: []string{"tenant-a", "tenant-b"}, keeps: true},
+		{name: "tenant-a", duplicates: []string{"tenant", "tenant-b"}, keeps: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var cr *neutronv1.OVSBridge
+			for i := range instances {
+				if instances[i].Name == tt.name {
+					cr = &instances[i]
+				}
+			}
+			duplicates, keeps := GetDuplicates(cr, instances)
+			if !reflect.DeepEqual(duplicates, tt.duplicates) || keeps != tt.keeps {
+				t.Errorf("GetDuplicates() = %v, %v, want %v, %v", duplicates, keeps, tt.duplicates, tt.keeps)
+			}
+		})
+	}
+}
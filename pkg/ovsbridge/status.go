@@ -0,0 +1,39 @@
+package ovsbridge
+
+import (
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+)
+
+// ReportPrefix - prefix of the bridge reports written by ovsnode.sh, followed
+// by the OVSBridge name. The report is in-sync or drift: <description>
+const ReportPrefix string = "ovsbridge-"
+
+// GetNodeStatus - returns the state of the bridge reported by each node
+func GetNodeStatus(cr *neutronv1.OVSBridge, reports map[string]map[string]string) []neutronv1.OVSBridgeNodeStatus {
+	nodes := []neutronv1.OVSBridgeNodeStatus{}
+	for node, report := range reports {
+		state, ok := report[ReportPrefix+cr.Name]
+		if !ok {
+			continue
+		}
+		nodeStatus := neutronv1.OVSBridgeNodeStatus{
+			Node:   node,
+			InSync: state == "in-sync",
+		}
+		if !nodeStatus.InSync {
+			nodeStatus.Drift = strings.TrimSpace(strings.TrimPrefix(state, "drift:"))
+		}
+		nodes = append(nodes, nodeStatus)
+	}
+
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i].Node < nodes[j].Node
+	})
+	if len(nodes) == 0 {
+		return nil
+	}
+	return nodes
+}
@@ -0,0 +1,244 @@
+// Package ovsdb is a minimal client of the OVSDB management protocol
+// (RFC 7047), enough to read the tables of the OVN databases.
+package ovsdb
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+)
+
+// Row - a row of a table, keyed by column name. The values are in the OVSDB
+// JSON notation, use the Get helpers to read them.
+type Row map[string]interface{}
+
+// Client - connection to an OVSDB server
+type Client struct {
+	conn     net.Conn
+	encoder  *json.Encoder
+	decoder  *json.Decoder
+	deadline time.Time
+	id       int
+}
+
+// message - JSON-RPC request, response or notification
+type message struct {
+	ID     interface{}     `json:"id"`
+	Method string          `json:"method,omitempty"`
+	Params json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage `json:"result,omitempty"`
+	Error  interface{}     `json:"error,omitempty"`
+}
+
+// UnsupportedRemoteError - none of the remotes uses a supported method, like
+// ssl remotes which need a client certificate
+type UnsupportedRemoteError struct {
+	Remotes string
+}
+
+func (e *UnsupportedRemoteError) Error() string {
+	return fmt.Sprintf("unsupported OVSDB remotes %s, only tcp and unix remotes are supported", e.Remotes)
+}
+
+// errUnsupportedRemote - the remote uses an OVSDB method other than tcp and unix
+var errUnsupportedRemote = errors.New("unsupported OVSDB remote")
+
+// Dial connects to the first reachable of the comma separated remotes, like
+// tcp:172.17.1.10:6642,tcp:172.17.1.11:6642. Only tcp and unix remotes are
+// supported, an UnsupportedRemoteError is returned if there is no other.
+// timeout is the total time of the session: connecting to the remotes, each
+// of them gets a share of the remaining time, and all requests.
+func Dial(remotes string, timeout time.Duration) (*Client, error) {
+	deadline := time.Now().Add(timeout)
+	var addresses [][2]string
+	var errs []string
+	unsupported := 0
+	for _, remote := range strings.Split(remotes, ",") {
+		remote = strings.TrimSpace(remote)
+		if remote == "" {
+			continue
+		}
+		network, address, err := parseRemote(remote)
+		if err != nil {
+			if errors.Is(err, errUnsupportedRemote) {
+				unsupported++
+			}
+			errs = append(errs, err.Error())
+			continue
+		}
+		addresses = append(addresses, [2]string{network, address})
+	}
+	if len(addresses) == 0 {
+		if len(errs) == 0 {
+			return nil, fmt.Errorf("no OVSDB remote")
+		}
+		if unsupported == len(errs) {
+			return nil, &UnsupportedRemoteError{Remotes: remotes}
+		}
+		return nil, fmt.Errorf("no valid OVSDB remote in %s: %s", remotes, strings.Join(errs, "; "))
+	}
+
+	for i, address := range addresses {
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			errs = append(errs, fmt.Sprintf("no time left for %s:%s", address[0], address[1]))
+			break
+		}
+		dialer := net.Dialer{Timeout: remaining / time.Duration(len(addresses)-i)}
+		conn, err := dialer.Dial(address[0], address[1])
+		if err != nil {
+			errs = append(errs, err.Error())
+			continue
+		}
+		return &Client{
+			conn:     conn,
+			encoder:  json.NewEncoder(conn),
+			decoder:  json.NewDecoder(conn),
+			deadline: deadline,
+		}, nil
+	}
+	return nil, fmt.Errorf("unable to connect to %s: %s", remotes, strings.Join(errs, "; "))
+}
+
+// parseRemote returns the network and address of an OVSDB remote
+func parseRemote(remote string) (string, string, error) {
+	parts := strings.SplitN(remote, ":", 2)
+	if len(parts) != 2 {
+		return "", "", fmt.Errorf("invalid OVSDB remote %s", remote)
+	}
+	switch parts[0] {
+	case "tcp":
+		host, port, err := net.SplitHostPort(parts[1])
+		if err != nil {
+			return "", "", fmt.Errorf("invalid OVSDB remote %s: %v", remote, err)
+		}
+		return "tcp", net.JoinHostPort(host, port), nil
+	case "unix":
+		return "unix", parts[1], nil
+	case "ssl", "ptcp", "pssl", "punix":
+		return "", "", fmt.Errorf("%w %s", errUnsupportedRemote, remote)
+	}
+	return "", "", fmt.Errorf("invalid OVSDB remote %s", remote)
+}
+
+// Close closes the connection
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Select returns the rows of the table, with the given columns only
+func (c *Client) Select(database string, table string, columns []string) ([]Row, error) {
+	operation := map[string]interface{}{
+		"op":      "select",
+		"table":   table,
+		"where":   []interface{}{},
+		"columns": columns,
+	}
+	result, err := c.call("transact", []interface{}{database, operation})
+	if err != nil {
+		return nil, err
+	}
+
+	var results []struct {
+		Rows    []Row  `json:"rows"`
+		Error   string `json:"error"`
+		Details string `json:"details"`
+	}
+	if err := json.Unmarshal(result, &results); err != nil {
+		return nil, fmt.Errorf("invalid transact result: %v", err)
+	}
+	if len(results) != 1 {
+		return nil, fmt.Errorf("invalid transact result: %d operation results", len(results))
+	}
+	if results[0].Error != "" {
+		return nil, fmt.Errorf("select from %s failed: %s %s", table, results[0].Error, results[0].Details)
+	}
+	return results[0].Rows, nil
+}
+
+// call sends the request and waits for its response, answering the echo
+// requests of the server meanwhile
+func (c *Client) call(method string, params interface{}) (json.RawMessage, error) {
+	c.id++
+	id := c.id
+	if err := c.conn.SetDeadline(c.deadline); err != nil {
+		return nil, err
+	}
+	if err := c.encoder.Encode(map[string]interface{}{"id": id, "method": method, "params": params}); err != nil {
+		return nil, err
+	}
+
+	for {
+		var msg message
+		if err := c.decoder.Decode(&msg); err != nil {
+			return nil, err
+		}
+		if msg.Method == "echo" {
+			if err := c.encoder.Encode(map[string]interface{}{"id": msg.ID, "result": msg.Params, "error": nil}); err != nil {
+				return nil, err
+			}
+			continue
+		}
+		// ids are decoded as float64
+		if responseID, ok := msg.ID.(float64); !ok || int(responseID) != id {
+			continue
+		}
+		if msg.Error != nil {
+			return nil, fmt.Errorf("%s failed: %v", method, msg.Error)
+		}
+		return msg.Result, nil
+	}
+}
+
+// GetString returns the string value of the column, empty if not set
+func GetString(row Row, column string) string {
+	switch value := row[column].(type) {
+	case string:
+		return value
+	case []interface{}:
+		// an optional value is an empty set, or a set with one atom
+		if atoms := getSetAtoms(value); len(atoms) == 1 {
+			if s, ok := atoms[0].(string); ok {
+				return s
+			}
+		}
+	}
+	return ""
+}
+
+// GetMap returns the string map value of the column, like external_ids
+func GetMap(row Row, column string) map[string]string {
+	result := map[string]string{}
+	value, ok := row[column].([]interface{})
+	if !ok || len(value) != 2 || value[0] != "map" {
+		return result
+	}
+	pairs, ok := value[1].([]interface{})
+	if !ok {
+		return result
+	}
+	for _, pair := range pairs {
+		kv, ok := pair.([]interface{})
+		if !ok || len(kv) != 2 {
+			continue
+		}
+		key, keyOk := kv[0].(string)
+		val, valOk := kv[1].(string)
+		if keyOk && valOk {
+			result[key] = val
+		}
+	}
+	return result
+}
+
+// getSetAtoms returns the atoms of a ["set", [...]] value
+func getSetAtoms(value []interface{}) []interface{} {
+	if len(value) != 2 || value[0] != "set" {
+		return nil
+	}
+	atoms, _ := value[1].([]interface{})
+	return atoms
+}
@@ -0,0 +1,171 @@
+package ovsdb_test
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb/ovsdbtest"
+)
+
+// row decodes a row in the OVSDB JSON notation
+func row(t *testing.T, value string) ovsdb.Row {
+	t.Helper()
+	row := ovsdb.Row{}
+	if err := json.Unmarshal([]byte(value), &row); err != nil {
+		t.Fatal(err)
+	}
+	return row
+}
+
+func TestSelect(t *testing.T) {
+	server, err := ovsdbtest.NewServer("OVN_Southbound", map[string][]ovsdb.Row{
+		"Chassis": {
+			row(t, `{"name": "chassis-0", "hostname": "compute-0", "other_config": ["map", []]}`),
+			row(t, `{"name": "chassis-1", "hostname": "compute-1", "other_config": ["map", []]}`),
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	client, err := ovsdb.Dial(server.Remote(), 5*time.Second)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+
+	rows, err := client.Select("OVN_Southbound", "Chassis", []string{"name", "hostname"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []ovsdb.Row{
+		{"name": "chassis-0", "hostname": "compute-0"},
+		{"name": "chassis-1", "hostname": "compute-1"},
+	}
+	if !reflect.DeepEqual(rows, want) {
+		t.Errorf("got %v, want %v", rows, want)
+	}
+	// a second request on the same connection
+	if _, err := client.Select("OVN_Southbound", "Chassis", []string{"name"}); err != nil {
+		t.Fatal(err)
+	}
+	if server.EchoReplies() == 0 {
+		t.Error("echo requests of the server not answered")
+	}
+
+	if _, err := client.Select("OVN_Southbound", "Unknown", []string{"name"}); err == nil ||
+		!strings.Contains(err.Error(), "unknown table") {
+		t.Errorf("expected the error of the operation, got %v", err)
+	}
+}
+
+func TestSelectDeadline(t *testing.T) {
+	server, err := ovsdbtest.NewServer("OVN_Southbound", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+	server.Stall = true
+
+	start := time.Now()
+	client, err := ovsdb.Dial(server.Remote(), 200*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer client.Close()
+	if _, err := client.Select("OVN_Southbound", "Chassis", []string{"name"}); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	if _, err := client.Select("OVN_Southbound", "Encap", []string{"ip"}); err == nil {
+		t.Fatal("expected a timeout")
+	}
+	// the requests share the deadline of the session
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("session took %s, more than its timeout", elapsed)
+	}
+}
+
+func TestDial(t *testing.T) {
+	server, err := ovsdbtest.NewServer("OVN_Southbound", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		remotes     string
+		err         bool
+		unsupported bool
+	}{
+		{name: "tcp", remotes: server.Remote()},
+		{name: "second remote", remotes: "tcp:127.0.0.1:1, " + server.Remote()},
+		{name: "ssl and tcp", remotes: "ssl:127.0.0.1:6642," + server.Remote()},
+		{name: "ssl", remotes: "ssl:192.168.122.10:6642,ssl:192.168.122.11:6642", err: true, unsupported: true},
+		{name: "no remote", remotes: " , ", err: true},
+		{name: "invalid", remotes: "192.168.122.10:6642", err: true},
+		{name: "invalid address", remotes: "tcp:192.168.122.10", err: true},
+		{name: "unreachable", remotes: "tcp:127.0.0.1:1", err: true},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			client, err := ovsdb.Dial(test.remotes, time.Second)
+			if !test.err {
+				if err != nil {
+					t.Fatalf("unexpected error: %v", err)
+				}
+				client.Close()
+				return
+			}
+			if err == nil {
+				client.Close()
+				t.Fatal("expected an error")
+			}
+			if _, ok := err.(*ovsdb.UnsupportedRemoteError); ok != test.unsupported {
+				t.Errorf("UnsupportedRemoteError: got %t, want %t (%v)", ok, test.unsupported, err)
+			}
+		})
+	}
+}
+
+func TestGetString(t *testing.T) {
+	r := row(t, `{"name": "chassis-0", "hostname": ["set", ["compute-0"]], "unset": ["set", []], "uuid": ["uuid", "4d1c0d7e"]}`)
+	tests := map[string]string{
+		"name":     "chassis-0",
+		"hostname": "compute-0",
+		"unset":    "",
+		"uuid":     "",
+		"missing":  "",
+	}
+	for column, want := range tests {
+		if got := ovsdb.GetString(r, column); got != want {
+			t.Errorf("%s: got %q, want %q", column, got, want)
+		}
+	}
+}
+
+func TestGetMap(t *testing.T) {
+	r := row(t, `{
+		"external_ids": ["map", [["ovn-encap-ip", "172.19.0.10"], ["ovn-encap-type", "geneve"]]],
+		"empty": ["map", []],
+		"invalid": ["map", [["key"], ["number", 1]]],
+		"set": ["set", []]
+	}`)
+	tests := map[string]map[string]string{
+		"external_ids": {"ovn-encap-ip": "172.19.0.10", "ovn-encap-type": "geneve"},
+		"empty":        {},
+		"invalid":      {},
+		"set":          {},
+		"missing":      {},
+	}
+	for column, want := range tests {
+		if got := ovsdb.GetMap(r, column); !reflect.DeepEqual(got, want) {
+			t.Errorf("%s: got %v, want %v", column, got, want)
+		}
+	}
+}
@@ -0,0 +1,157 @@
+// Package ovsdbtest provides an OVSDB server stand-in for tests. It answers
+// the select operations of transact requests from in-memory tables, and sends
+// an echo request before each response like a server checking its clients.
+package ovsdbtest
+
+import (
+	"encoding/json"
+	"net"
+	"sync"
+
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsdb"
+)
+
+// Server - OVSDB server stand-in listening on a local TCP port
+type Server struct {
+	listener net.Listener
+	// Tables by database and table name, read for the select operations
+	Tables map[string]map[string][]ovsdb.Row
+	// Stall makes the server read the requests without answering them
+	Stall bool
+
+	mu          sync.Mutex
+	conns       []net.Conn
+	echoReplies int
+}
+
+type message struct {
+	ID     interface{}       `json:"id"`
+	Method string            `json:"method,omitempty"`
+	Params []json.RawMessage `json:"params,omitempty"`
+	Result json.RawMessage   `json:"result,omitempty"`
+}
+
+type operation struct {
+	Op      string   `json:"op"`
+	Table   string   `json:"table"`
+	Columns []string `json:"columns"`
+}
+
+// NewServer starts a server with the tables of the database
+func NewServer(database string, tables map[string][]ovsdb.Row) (*Server, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, err
+	}
+	s := &Server{
+		listener: listener,
+		Tables:   map[string]map[string][]ovsdb.Row{database: tables},
+	}
+	go s.serve()
+	return s, nil
+}
+
+// Remote returns the OVSDB remote of the server, like tcp:127.0.0.1:41234
+func (s *Server) Remote() string {
+	return "tcp:" + s.listener.Addr().String()
+}
+
+// EchoReplies returns how many echo requests the clients answered
+func (s *Server) EchoReplies() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.echoReplies
+}
+
+// Close stops the server and closes the connections
+func (s *Server) Close() {
+	s.listener.Close()
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, conn := range s.conns {
+		conn.Close()
+	}
+}
+
+func (s *Server) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		s.mu.Lock()
+		s.conns = append(s.conns, conn)
+		s.mu.Unlock()
+		go s.handle(conn)
+	}
+}
+
+func (s *Server) handle(conn net.Conn) {
+	defer conn.Close()
+	encoder := json.NewEncoder(conn)
+	decoder := json.NewDecoder(conn)
+	for {
+		var request message
+		if err := decoder.Decode(&request); err != nil {
+			return
+		}
+		if request.Method == "" && request.ID == "echo" {
+			s.mu.Lock()
+			s.echoReplies++
+			s.mu.Unlock()
+			continue
+		}
+		if s.Stall {
+			continue
+		}
+		if err := encoder.Encode(map[string]interface{}{"id": "echo", "method": "echo", "params": []interface{}{}}); err != nil {
+			return
+		}
+
+		response := map[string]interface{}{"id": request.ID, "result": nil, "error": nil}
+		if request.Method == "transact" {
+			response["result"] = s.transact(request.Params)
+		} else {
+			response["error"] = "unknown method"
+		}
+		if err := encoder.Encode(response); err != nil {
+			return
+		}
+	}
+}
+
+// transact returns the results of the select operations
+func (s *Server) transact(params []json.RawMessage) []interface{} {
+	results := []interface{}{}
+	if len(params) == 0 {
+		return results
+	}
+	var database string
+	if err := json.Unmarshal(params[0], &database); err != nil {
+		return results
+	}
+	for _, param := range params[1:] {
+		var op operation
+		if err := json.Unmarshal(param, &op); err != nil || op.Op != "select" {
+			results = append(results, map[string]interface{}{"error": "not supported", "details": "only select is supported"})
+			continue
+		}
+		rows, ok := s.Tables[database][op.Table]
+		if !ok {
+			results = append(results, map[string]interface{}{"error": "unknown table", "details": op.Table})
+			continue
+		}
+		selected := []ovsdb.Row{}
+		for _, row := range rows {
+			columns := ovsdb.Row{}
+			for _, column := range op.Columns {
+				if value, ok := row[column]; ok {
+					columns[column] = value
+				}
+			}
+			selected = append(selected, columns)
+		}
+		results = append(results, map[string]interface{}{"rows": selected})
+	}
+	return results
+}
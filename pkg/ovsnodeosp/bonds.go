@@ -0,0 +1,112 @@
+package ovsnodeosp
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+)
+
+// BondReportPrefix - prefix of the bond reports written by ovsnode.sh
+const BondReportPrefix string = "bond-"
+
+// GetMappedBridges - returns the bridges of the bridge mappings, in order
+func GetMappedBridges(cr *neutronv1.OVSNodeOsp) []string {
+	bridges := []string{}
+	for _, mapping := range strings.Split(cr.Spec.BridgeMappings, ",") {
+		parts := strings.SplitN(strings.TrimSpace(mapping), ":", 2)
+		if len(parts) == 2 && parts[1] != "" {
+			bridges = append(bridges, parts[1])
+		}
+	}
+	return bridges
+}
+
+// ValidateBonds - checks the bonds of the spec. Bonds are uplinks of the
+// provider bridges, so their bridge has to be in the bridge mappings, and a
+// bond with the NIC as member has to be on the gateway bridge.
+func ValidateBonds(cr *neutronv1.OVSNodeOsp) error {
+	bridges := GetMappedBridges(cr)
+	names := map[string]bool{}
+	for _, bond := range cr.Spec.Bonds {
+		if names[bond.Name] {
+			return fmt.Errorf("bond %s defined more than once", bond.Name)
+		}
+		names[bond.Name] = true
+		mapped := false
+		for _, bridge := range bridges {
+			mapped = mapped || bridge == bond.Bridge
+		}
+		if !mapped {
+			return fmt.Errorf("bond %s: bridge %s is not in the bridge mappings %q", bond.Name, bond.Bridge, cr.Spec.BridgeMappings)
+		}
+		for _, iface := range bond.Interfaces {
+			if iface == cr.Spec.Nic && bond.Bridge != bridges[0] {
+				return fmt.Errorf("bond %s: the NIC %s can only be bonded on the gateway bridge %s", bond.Name, iface, bridges[0])
+			}
+		}
+		if bond.Mode == "balance-tcp" && (bond.LACP == "" || bond.LACP == "off") {
+			return fmt.Errorf("bond %s: balance-tcp requires lacp active or passive", bond.Name)
+		}
+		if len(bond.Interfaces) < 2 {
+			return fmt.Errorf("bond %s: needs at least two interfaces", bond.Name)
+		}
+	}
+	return nil
+}
+
+// renderBonds - renders the bonds as lines of <bridge> <bond> <mode> <lacp> <iface>,<iface>
+func renderBonds(cr *neutronv1.OVSNodeOsp) string {
+	lines := ""
+	for _, bond := range cr.Spec.Bonds {
+		lacp := bond.LACP
+		if lacp == "" {
+			lacp = "off"
+		}
+		lines += fmt.Sprintf("%s %s %s %s %s\n", bond.Bridge, bond.Name, bond.Mode, lacp, strings.Join(bond.Interfaces, ","))
+	}
+	return lines
+}
+
+// GetBondStatus - parses the bond reports of the nodes, a report has the
+// member link states as <iface>:<state>,<iface>:<state>. Reports of bonds
+// which are no longer in the spec are ignored.
+func GetBondStatus(cr *neutronv1.OVSNodeOsp, reports map[string]map[string]string) []neutronv1.OVSBondStatus {
+	names := map[string]bool{}
+	for _, bond := range cr.Spec.Bonds {
+		names[bond.Name] = true
+	}
+
+	bonds := []neutronv1.OVSBondStatus{}
+	for node, report := range reports {
+		for key, value := range report {
+			if !strings.HasPrefix(key, BondReportPrefix) || !names[strings.TrimPrefix(key, BondReportPrefix)] {
+				continue
+			}
+			bond := neutronv1.OVSBondStatus{
+				Node:    node,
+				Name:    strings.TrimPrefix(key, BondReportPrefix),
+				Members: map[string]string{},
+			}
+			for _, member := range strings.Split(value, ",") {
+				parts := strings.SplitN(member, ":", 2)
+				if len(parts) == 2 {
+					bond.Members[parts[0]] = parts[1]
+				}
+			}
+			bonds = append(bonds, bond)
+		}
+	}
+
+	sort.Slice(bonds, func(i, j int) bool {
+		if bonds[i].Node != bonds[j].Node {
+			return bonds[i].Node < bonds[j].Node
+		}
+		return bonds[i].Name < bonds[j].Name
+	})
+	if len(bonds) == 0 {
+		return nil
+	}
+	return bonds
+}
@@ -0,0 +1,56 @@
+package ovsnodeosp
+
+import (
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// CleanupJob - job undoing the node setup of ovsnode.sh and removing the chassis
+func CleanupJob(cr *neutronv1.OVSNodeOsp, cmName string, nodeName string) *batchv1.Job {
+	var optional = true
+
+	env := []corev1.EnvVar{
+		{
+			Name:  "NIC",
+			Value: cr.Spec.Nic,
+		},
+		{
+			Name:  "BRIDGE_MAPPINGS",
+			Value: cr.Spec.BridgeMappings,
+		},
+		{
+			Name:  "INTEGRATION_BRIDGE",
+			Value: common.GetIntegrationBridge(cr.Spec.IntegrationBridge),
+		},
+		{
+			Name:  "CHASSIS_NAME_PATTERN",
+			Value: common.GetChassisNamePattern(cr.Spec.ChassisNamePattern),
+		},
+		{
+			Name:  "NODE_NAME",
+			Value: nodeName,
+		},
+		{
+			Name: "OVN_SB_REMOTE",
+			ValueFrom: &corev1.EnvVarSource{
+				ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: "ovn-connection",
+					},
+					Key: "SBConnection",
+					// the chassis is left in place if the SB DB is gone
+					Optional: &optional,
+				},
+			},
+		},
+	}
+
+	volumeMounts := append(common.GetVolumeMounts(), GetVolumeMounts(cmName)...)
+	volumes := append(common.GetVolumes(cmName), GetVolumes(cmName)...)
+
+	return common.CleanupJob(common.GetCleanupJobName(cr.Name, nodeName), cr.Namespace, nodeName,
+		cr.Spec.OvsNodeOspImage, cr.Spec.ServiceAccount, env, volumes, volumeMounts)
+}
@@ -23,6 +23,8 @@ func ScriptsConfigMap(cr *neutronv1.OVSNodeOsp, cmName string) *corev1.ConfigMap
 		},
 		Data: map[string]string{
 			"ovsnode.sh": util.ExecuteTemplateFile(strings.ToLower(cr.Kind)+"/ovsnode.sh", nil),
+			"report.sh":  util.ExecuteTemplateFile("common/report.sh", nil),
+			"cleanup.sh": util.ExecuteTemplateFile(strings.ToLower(cr.Kind)+"/cleanup.sh", nil),
 		},
 	}
 
@@ -49,3 +51,27 @@ func TemplatesConfigMap(cr *neutronv1.OVSNodeOsp, cmName string) *corev1.ConfigM
 
 	return cm
 }
+
+// OVSConfigConfigMap - additional external_ids, other_config and the bonds of
+// the nodes. ovsnode.sh re-applies them periodically, so the ConfigMap is not
+// part of the config hash and changes do not restart ovs-vswitchd.
+func OVSConfigConfigMap(cr *neutronv1.OVSNodeOsp, cmName string) *corev1.ConfigMap {
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Data: map[string]string{
+			"external_ids": renderOVSConfig(GetExternalIDs(cr)),
+			"other_config": renderOVSConfig(GetOtherConfig(cr)),
+			"bonds":        renderBonds(cr),
+		},
+	}
+
+	return cm
+}
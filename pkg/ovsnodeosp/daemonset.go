@@ -0,0 +1,182 @@
+package ovsnodeosp
+
+import (
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/common"
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// DaemonSet - the ovs-node-osp DaemonSet running ovsnode.sh on the compute nodes of the role
+func DaemonSet(cr *neutronv1.OVSNodeOsp, cmName string, hostAliases []corev1.HostAlias) *appsv1.DaemonSet {
+	var trueVar = true
+
+	daemonSet := appsv1.DaemonSet{
+		TypeMeta: metav1.TypeMeta{
+			Kind:       "DaemonSet",
+			APIVersion: "apps/v1",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"daemonset": cr.Name + "-daemonset"},
+			},
+			UpdateStrategy:  common.GetDaemonsetUpdateStrategy(cr.Spec.UpgradeStrategy),
+			MinReadySeconds: common.GetMinReadySeconds(cr.Spec.UpgradeStrategy),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"daemonset": cr.Name + "-daemonset"},
+				},
+				Spec: corev1.PodSpec{
+					NodeSelector:       common.GetComputeWorkerNodeSelector(cr.Spec.RoleName),
+					HostNetwork:        true,
+					HostPID:            true,
+					DNSPolicy:          "ClusterFirstWithHostNet",
+					HostAliases:        hostAliases,
+					Containers:         []corev1.Container{},
+					Tolerations:        []corev1.Toleration{},
+					ServiceAccountName: cr.Spec.ServiceAccount,
+					PriorityClassName:  "system-node-critical",
+					Affinity:           GetDPDKAffinity(cr, cr.Status.NodesWithoutHugepages),
+				},
+			},
+		},
+	}
+
+	// add compute worker nodes tolerations
+	for _, toleration := range common.GetComputeWorkerTolerations(cr.Spec.RoleName) {
+		daemonSet.Spec.Template.Spec.Tolerations = append(daemonSet.Spec.Template.Spec.Tolerations, toleration)
+	}
+
+	containerSpec := corev1.Container{
+		Name:  "ovs-node-osp",
+		Image: cr.Spec.OvsNodeOspImage,
+		Command: []string{
+			"bash", "-c", "/usr/local/sbin/ovsnode.sh",
+		},
+		SecurityContext: &corev1.SecurityContext{
+			Privileged: &trueVar,
+		},
+		ReadinessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				Exec: &corev1.ExecAction{
+					Command: []string{
+						"/usr/share/openvswitch/scripts/ovs-ctl", "status",
+					},
+				},
+			},
+			InitialDelaySeconds: 15,
+			PeriodSeconds:       5,
+		},
+		LivenessProbe: &corev1.Probe{
+			Handler: corev1.Handler{
+				Exec: &corev1.ExecAction{
+					Command: []string{
+						"/usr/share/openvswitch/scripts/ovs-ctl", "status",
+					},
+				},
+			},
+			InitialDelaySeconds: 15,
+			PeriodSeconds:       5,
+		},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "OVS_LOG_LEVEL",
+				Value: cr.Spec.OvsLogLevel,
+			},
+			{
+				Name:  "NIC",
+				Value: cr.Spec.Nic,
+			},
+			{
+				Name:  "BRIDGE_MAPPINGS",
+				Value: cr.Spec.BridgeMappings,
+			},
+			{
+				Name:  "INTEGRATION_BRIDGE",
+				Value: common.GetIntegrationBridge(cr.Spec.IntegrationBridge),
+			},
+			{
+				Name:  "CHASSIS_NAME_PATTERN",
+				Value: common.GetChassisNamePattern(cr.Spec.ChassisNamePattern),
+			},
+			{
+				Name:  "SYSTEM_ID_SOURCE",
+				Value: GetSystemIDSource(cr),
+			},
+			{
+				Name: "OVN_SB_REMOTE",
+				ValueFrom: &corev1.EnvVarSource{
+					ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: "ovn-connection",
+						},
+						Key: "SBConnection",
+					},
+				},
+			},
+			{
+				Name:  "K8S_NODE",
+				Value: cmName,
+			},
+			{
+				// the per node entries of the ConfigMaps are keyed by node name
+				Name: "NODE_NAME",
+				ValueFrom: &corev1.EnvVarSource{
+					FieldRef: &corev1.ObjectFieldSelector{
+						FieldPath: "spec.nodeName",
+					},
+				},
+			},
+		},
+		Resources:    GetDPDKResources(cr),
+		VolumeMounts: []corev1.VolumeMount{},
+	}
+	// add DPDK, hardware offload and report env vars
+	containerSpec.Env = append(containerSpec.Env, GetDPDKEnvVars(cr)...)
+	containerSpec.Env = append(containerSpec.Env, GetHWOffloadEnvVars(cr)...)
+	containerSpec.Env = append(containerSpec.Env, common.GetReportEnvVars(common.ReportConfigMapName(cr.Name))...)
+	// add common VolumeMounts
+	for _, volMount := range common.GetVolumeMounts() {
+		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
+	}
+	// add ovsnode specific VolumeMounts
+	for _, volMount := range GetVolumeMounts(cmName) {
+		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
+	}
+
+	// add OVSBridges VolumeMounts
+	for _, volMount := range GetOVSBridgesVolumeMounts() {
+		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
+	}
+	// add DPDK VolumeMounts
+	for _, volMount := range GetDPDKVolumeMounts(cr) {
+		containerSpec.VolumeMounts = append(containerSpec.VolumeMounts, volMount)
+	}
+
+	daemonSet.Spec.Template.Spec.Containers = append(daemonSet.Spec.Template.Spec.Containers, containerSpec)
+
+	// Volume config
+	// add common Volumes
+	for _, volConfig := range common.GetVolumes(cmName) {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+	// add ovs Volumes
+	for _, volConfig := range GetVolumes(cmName) {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+	// add OVSBridges Volumes
+	for _, volConfig := range GetOVSBridgesVolumes(cr.Spec.RoleName) {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+	// add DPDK Volumes
+	for _, volConfig := range GetDPDKVolumes(cr) {
+		daemonSet.Spec.Template.Spec.Volumes = append(daemonSet.Spec.Template.Spec.Volumes, volConfig)
+	}
+
+	return &daemonSet
+}
@@ -0,0 +1,204 @@
+package ovsnodeosp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+var (
+	dpdkSocketMemRegexp  = regexp.MustCompile(`^[0-9]+(,[0-9]+)*$`)
+	dpdkCPUMaskRegexp    = regexp.MustCompile(`^(0x)?[0-9a-fA-F]+$`)
+	dpdkPCIAddressRegexp = regexp.MustCompile(`^[0-9a-fA-F]{4}:[0-9a-fA-F]{2}:[0-9a-fA-F]{2}\.[0-7]$`)
+)
+
+// validateDPDK - checks the socket memory, the CPU masks and the PCI addresses
+// of the DPDK ports, which ovsnode.sh passes as they are to ovs-vsctl
+func validateDPDK(cr *neutronv1.OVSNodeOsp) error {
+	dpdk := cr.Spec.DPDK
+	if dpdk == nil {
+		return nil
+	}
+	if dpdk.SocketMem != "" && !dpdkSocketMemRegexp.MatchString(dpdk.SocketMem) {
+		return fmt.Errorf("dpdk: invalid socketMem %q, expected MB per NUMA node, e.g. 1024,1024", dpdk.SocketMem)
+	}
+	if dpdk.LcoreMask != "" && !dpdkCPUMaskRegexp.MatchString(dpdk.LcoreMask) {
+		return fmt.Errorf("dpdk: invalid lcoreMask %q, expected a hex CPU mask", dpdk.LcoreMask)
+	}
+	if dpdk.PMDCPUMask != "" && !dpdkCPUMaskRegexp.MatchString(dpdk.PMDCPUMask) {
+		return fmt.Errorf("dpdk: invalid pmdCPUMask %q, expected a hex CPU mask", dpdk.PMDCPUMask)
+	}
+	names := map[string]bool{}
+	for _, port := range dpdk.Ports {
+		if !dpdkPCIAddressRegexp.MatchString(port.PCIAddress) {
+			return fmt.Errorf("dpdk: port %s has an invalid PCI address %q", port.Name, port.PCIAddress)
+		}
+		if names[port.Name] {
+			return fmt.Errorf("dpdk: port %s defined more than once", port.Name)
+		}
+		names[port.Name] = true
+	}
+	return nil
+}
+
+// GetDPDKEnvVars - env vars used by ovsnode.sh to configure DPDK
+func GetDPDKEnvVars(cr *neutronv1.OVSNodeOsp) []corev1.EnvVar {
+	if cr.Spec.DPDK == nil {
+		return []corev1.EnvVar{
+			{
+				Name:  "DPDK",
+				Value: "false",
+			},
+		}
+	}
+
+	// ports are passed as space separated <bridge>/<name>=<pci address>
+	ports := []string{}
+	for _, port := range cr.Spec.DPDK.Ports {
+		ports = append(ports, fmt.Sprintf("%s/%s=%s", port.Bridge, port.Name, port.PCIAddress))
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "DPDK",
+			Value: "true",
+		},
+		{
+			Name:  "DPDK_SOCKET_MEM",
+			Value: cr.Spec.DPDK.SocketMem,
+		},
+		{
+			Name:  "DPDK_LCORE_MASK",
+			Value: cr.Spec.DPDK.LcoreMask,
+		},
+		{
+			Name:  "PMD_CPU_MASK",
+			Value: cr.Spec.DPDK.PMDCPUMask,
+		},
+		{
+			Name:  "VHOST_SOCK_DIR",
+			Value: cr.Spec.DPDK.VhostSockDir,
+		},
+		{
+			Name:  "DPDK_PORTS",
+			Value: strings.Join(ports, " "),
+		},
+	}
+}
+
+// GetDPDKResources - hugepages and memory requested by the ovs pod
+func GetDPDKResources(cr *neutronv1.OVSNodeOsp) corev1.ResourceRequirements {
+	if cr.Spec.DPDK == nil {
+		return corev1.ResourceRequirements{}
+	}
+
+	memory := resource.MustParse("1Gi")
+	if cr.Spec.DPDK.Memory != nil {
+		memory = *cr.Spec.DPDK.Memory
+	}
+	resources := corev1.ResourceList{
+		getHugepagesResourceName(cr): cr.Spec.DPDK.Hugepages,
+		corev1.ResourceMemory:        memory,
+	}
+
+	// hugepages requests have to match the limits
+	return corev1.ResourceRequirements{
+		Requests: resources,
+		Limits:   resources,
+	}
+}
+
+// GetDPDKVolumes - hugepages and vfio volumes
+func GetDPDKVolumes(cr *neutronv1.OVSNodeOsp) []corev1.Volume {
+	if cr.Spec.DPDK == nil {
+		return []corev1.Volume{}
+	}
+
+	return []corev1.Volume{
+		{
+			Name: "hugepages",
+			VolumeSource: corev1.VolumeSource{
+				EmptyDir: &corev1.EmptyDirVolumeSource{
+					Medium: corev1.StorageMediumHugePages,
+				},
+			},
+		},
+		{
+			Name: "dev-vfio",
+			VolumeSource: corev1.VolumeSource{
+				HostPath: &corev1.HostPathVolumeSource{
+					Path: "/dev/vfio",
+				},
+			},
+		},
+	}
+}
+
+// GetDPDKVolumeMounts - hugepages and vfio VolumeMounts
+func GetDPDKVolumeMounts(cr *neutronv1.OVSNodeOsp) []corev1.VolumeMount {
+	if cr.Spec.DPDK == nil {
+		return []corev1.VolumeMount{}
+	}
+
+	return []corev1.VolumeMount{
+		{
+			Name:      "hugepages",
+			MountPath: "/dev/hugepages",
+		},
+		{
+			Name:      "dev-vfio",
+			MountPath: "/dev/vfio",
+		},
+	}
+}
+
+// HugepagesAvailable - checks that the node has enough allocatable hugepages for DPDK
+func HugepagesAvailable(cr *neutronv1.OVSNodeOsp, node *corev1.Node) bool {
+	if cr.Spec.DPDK == nil {
+		return true
+	}
+
+	allocatable, ok := node.Status.Allocatable[getHugepagesResourceName(cr)]
+	if !ok {
+		return false
+	}
+	return allocatable.Cmp(cr.Spec.DPDK.Hugepages) >= 0
+}
+
+func getHugepagesResourceName(cr *neutronv1.OVSNodeOsp) corev1.ResourceName {
+	return corev1.ResourceName(corev1.ResourceHugePagesPrefix + cr.Spec.DPDK.HugepageSize)
+}
+
+// GetDPDKAffinity - keeps the pods off the nodes without enough hugepages, so
+// they do not hold back the rollout to the other nodes. The nodes are
+// excluded by name, nothing gets written to the nodes.
+func GetDPDKAffinity(cr *neutronv1.OVSNodeOsp, nodesWithoutHugepages []string) *corev1.Affinity {
+	if cr.Spec.DPDK == nil || len(nodesWithoutHugepages) == 0 {
+		return nil
+	}
+
+	nodes := append([]string(nil), nodesWithoutHugepages...)
+	sort.Strings(nodes)
+	return &corev1.Affinity{
+		NodeAffinity: &corev1.NodeAffinity{
+			RequiredDuringSchedulingIgnoredDuringExecution: &corev1.NodeSelector{
+				NodeSelectorTerms: []corev1.NodeSelectorTerm{
+					{
+						MatchFields: []corev1.NodeSelectorRequirement{
+							{
+								Key:      "metadata.name",
+								Operator: corev1.NodeSelectorOpNotIn,
+								Values:   nodes,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,106 @@
+package ovsnodeosp
+
+import (
+	"reflect"
+	"testing"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func testDPDK() *neutronv1.OVSDPDKSpec {
+	return &neutronv1.OVSDPDKSpec{
+		SocketMem:    "1024,1024",
+		PMDCPUMask:   "0x30",
+		LcoreMask:    "3",
+		HugepageSize: "1Gi",
+		Hugepages:    resource.MustParse("4Gi"),
+		Ports: []neutronv1.OVSDPDKPort{
+			{Name: "dpdk0", Bridge: "br-dpdk", PCIAddress: "0000:03:00.0"},
+		},
+	}
+}
+
+func TestValidateOVSConfigDPDK(t *testing.T) {
+	tests := []struct {
+		name    string
+		modify  func(dpdk *neutronv1.OVSDPDKSpec)
+		wantErr bool
+	}{
+		{
+			name:   "valid",
+			modify: func(dpdk *neutronv1.OVSDPDKSpec) {},
+		},
+		{
+			name: "unset masks and socket memory",
+			modify: func(dpdk *neutronv1.OVSDPDKSpec) {
+				dpdk.SocketMem, dpdk.PMDCPUMask, dpdk.LcoreMask = "", "", ""
+			},
+		},
+		{
+			name:    "socket memory with units",
+			modify:  func(dpdk *neutronv1.OVSDPDKSpec) { dpdk.SocketMem = "1G,1G" },
+			wantErr: true,
+		},
+		{
+			name:    "socket memory with spaces",
+			modify:  func(dpdk *neutronv1.OVSDPDKSpec) { dpdk.SocketMem = "1024, 1024" },
+			wantErr: true,
+		},
+		{
+			name:    "pmd mask not hex",
+			modify:  func(dpdk *neutronv1.OVSDPDKSpec) { dpdk.PMDCPUMask = "0-3" },
+			wantErr: true,
+		},
+		{
+			name:    "lcore mask not hex",
+			modify:  func(dpdk *neutronv1.OVSDPDKSpec) { dpdk.LcoreMask = "0xg" },
+			wantErr: true,
+		},
+		{
+			name:    "short PCI address",
+			modify:  func(dpdk *neutronv1.OVSDPDKSpec) { dpdk.Ports[0].PCIAddress = "03:00.0" },
+			wantErr: true,
+		},
+		{
+			name: "duplicate port",
+			modify: func(dpdk *neutronv1.OVSDPDKSpec) {
+				dpdk.Ports = append(dpdk.Ports, neutronv1.OVSDPDKPort{Name: "dpdk0", Bridge: "br-dpdk", PCIAddress: "0000:03:00.1"})
+			},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cr := &neutronv1.OVSNodeOsp{}
+			cr.Spec.DPDK = testDPDK()
+			tt.modify(cr.Spec.DPDK)
+			err := ValidateOVSConfig(cr)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateOVSConfig() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestGetDPDKAffinity(t *testing.T) {
+	cr := &neutronv1.OVSNodeOsp{}
+	if affinity := GetDPDKAffinity(cr, []string{"worker-0"}); affinity != nil {
+		t.Errorf("affinity without DPDK: %v", affinity)
+	}
+
+	cr.Spec.DPDK = testDPDK()
+	if affinity := GetDPDKAffinity(cr, nil); affinity != nil {
+		t.Errorf("affinity with hugepages on all nodes: %v", affinity)
+	}
+
+	affinity := GetDPDKAffinity(cr, []string{"worker-1", "worker-0"})
+	want := []corev1.NodeSelectorRequirement{
+		{Key: "metadata.name", Operator: corev1.NodeSelectorOpNotIn, Values: []string{"worker-0", "worker-1"}},
+	}
+	terms := affinity.NodeAffinity.RequiredDuringSchedulingIgnoredDuringExecution.NodeSelectorTerms
+	if len(terms) != 1 || !reflect.DeepEqual(terms[0].MatchFields, want) {
+		t.Errorf("GetDPDKAffinity() = %v, want one term with %v", terms, want)
+	}
+}
@@ -0,0 +1,59 @@
+package ovsnodeosp
+
+import (
+	"fmt"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+// IsGatewayNode - checks if the node is selected as gateway chassis
+func IsGatewayNode(cr *neutronv1.OVSNodeOsp, node *corev1.Node) (bool, error) {
+	if !cr.Spec.Gateway {
+		return false, nil
+	}
+	if cr.Spec.GatewayNodeSelector == nil {
+		return true, nil
+	}
+	selector, err := metav1.LabelSelectorAsSelector(cr.Spec.GatewayNodeSelector)
+	if err != nil {
+		return false, fmt.Errorf("invalid gatewayNodeSelector: %v", err)
+	}
+	return selector.Matches(labels.Set(node.Labels)), nil
+}
+
+// GetCMSOptions - returns the ovn-cms-options of a node
+func GetCMSOptions(cr *neutronv1.OVSNodeOsp, gateway bool) string {
+	options := []string{}
+	if gateway {
+		options = append(options, "enable-chassis-as-gw")
+		if cr.Spec.GatewayPriority != nil {
+			options = append(options, fmt.Sprintf("gateway-priority=%d", *cr.Spec.GatewayPriority))
+		}
+	}
+	if len(cr.Spec.AvailabilityZones) > 0 {
+		options = append(options, "availability-zones="+strings.Join(cr.Spec.AvailabilityZones, ":"))
+	}
+	return strings.Join(options, ",")
+}
+
+// GatewaysConfigMap - config map with the ovn-cms-options of each node, keyed by node name
+func GatewaysConfigMap(cr *neutronv1.OVSNodeOsp, cmName string, cmsOptions map[string]string) *corev1.ConfigMap {
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Data: cmsOptions,
+	}
+
+	return cm
+}
@@ -0,0 +1,29 @@
+package ovsnodeosp
+
+import (
+	"fmt"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// GetHWOffloadEnvVars - env vars used by ovsnode.sh to configure hardware offload
+func GetHWOffloadEnvVars(cr *neutronv1.OVSNodeOsp) []corev1.EnvVar {
+	enabled := cr.Spec.HWOffload != nil
+	pfs := []string{}
+	if enabled {
+		pfs = cr.Spec.HWOffload.PhysicalFunctions
+	}
+
+	return []corev1.EnvVar{
+		{
+			Name:  "HW_OFFLOAD",
+			Value: fmt.Sprintf("%t", enabled),
+		},
+		{
+			Name:  "HW_OFFLOAD_PFS",
+			Value: strings.Join(pfs, " "),
+		},
+	}
+}
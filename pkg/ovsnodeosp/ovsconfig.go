@@ -0,0 +1,133 @@
+package ovsnodeosp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+)
+
+var ovsConfigKeyRegexp = regexp.MustCompile(`^[a-zA-Z0-9][a-zA-Z0-9_.-]*$`)
+
+// external_ids set by ovsnode.sh, they can not be overridden
+var reservedExternalIDs = []string{
+	"system-id",
+	"hostname",
+	"ovn-bridge",
+	"ovn-bridge-datapath-type",
+	"ovn-bridge-mappings",
+	"ovn-cms-options",
+	"ovn-encap-ip",
+	"ovn-encap-type",
+	"ovn-remote",
+}
+
+// other_config set by ovsnode.sh, they can not be overridden
+var reservedOtherConfig = []string{
+	"dpdk-init",
+	"dpdk-lcore-mask",
+	"dpdk-socket-mem",
+	"hw-offload",
+	"pmd-cpu-mask",
+	"vhost-sock-dir",
+}
+
+// GetExternalIDs - returns the additional external_ids of the nodes, without chassis suffix
+func GetExternalIDs(cr *neutronv1.OVSNodeOsp) map[string]string {
+	externalIDs := map[string]string{}
+	for key, value := range cr.Spec.ExternalIDs {
+		externalIDs[key] = value
+	}
+	if cr.Spec.OpenflowProbeInterval != nil {
+		externalIDs["ovn-openflow-probe-interval"] = fmt.Sprintf("%d", *cr.Spec.OpenflowProbeInterval)
+	}
+	if cr.Spec.RemoteProbeInterval != nil {
+		externalIDs["ovn-remote-probe-interval"] = fmt.Sprintf("%d", *cr.Spec.RemoteProbeInterval)
+	}
+	if cr.Spec.MonitorAll != nil {
+		externalIDs["ovn-monitor-all"] = fmt.Sprintf("%t", *cr.Spec.MonitorAll)
+	}
+	if cr.Spec.EncapCsum != nil {
+		externalIDs["ovn-encap-csum"] = fmt.Sprintf("%t", *cr.Spec.EncapCsum)
+	}
+	return externalIDs
+}
+
+// GetOtherConfig - returns the additional other_config of the nodes
+func GetOtherConfig(cr *neutronv1.OVSNodeOsp) map[string]string {
+	otherConfig := map[string]string{}
+	for key, value := range cr.Spec.OtherConfig {
+		otherConfig[key] = value
+	}
+	if cr.Spec.MaxIdle != nil {
+		otherConfig["max-idle"] = fmt.Sprintf("%d", *cr.Spec.MaxIdle)
+	}
+	if cr.Spec.FlowLimit != nil {
+		otherConfig["flow-limit"] = fmt.Sprintf("%d", *cr.Spec.FlowLimit)
+	}
+	if cr.Spec.RevalidatorThreads != nil {
+		otherConfig["n-revalidator-threads"] = fmt.Sprintf("%d", *cr.Spec.RevalidatorThreads)
+	}
+	return otherConfig
+}
+
+// ValidateOVSConfig - checks the externalIDs, otherConfig and DPDK settings
+// of the spec
+func ValidateOVSConfig(cr *neutronv1.OVSNodeOsp) error {
+	typedExternalIDs := map[string]bool{
+		"ovn-openflow-probe-interval": cr.Spec.OpenflowProbeInterval != nil,
+		"ovn-remote-probe-interval":   cr.Spec.RemoteProbeInterval != nil,
+		"ovn-monitor-all":             cr.Spec.MonitorAll != nil,
+		"ovn-encap-csum":              cr.Spec.EncapCsum != nil,
+	}
+	if err := validateOVSConfigKeys("externalIDs", cr.Spec.ExternalIDs, reservedExternalIDs, typedExternalIDs); err != nil {
+		return err
+	}
+
+	typedOtherConfig := map[string]bool{
+		"max-idle":              cr.Spec.MaxIdle != nil,
+		"flow-limit":            cr.Spec.FlowLimit != nil,
+		"n-revalidator-threads": cr.Spec.RevalidatorThreads != nil,
+	}
+	if err := validateOVSConfigKeys("otherConfig", cr.Spec.OtherConfig, reservedOtherConfig, typedOtherConfig); err != nil {
+		return err
+	}
+	return validateDPDK(cr)
+}
+
+func validateOVSConfigKeys(field string, config map[string]string, reserved []string, typed map[string]bool) error {
+	for key, value := range config {
+		if !ovsConfigKeyRegexp.MatchString(key) {
+			return fmt.Errorf("%s: invalid key %q", field, key)
+		}
+		if strings.ContainsAny(value, "\"\n") {
+			return fmt.Errorf("%s: invalid value for key %s", field, key)
+		}
+		for _, reservedKey := range reserved {
+			if key == reservedKey {
+				return fmt.Errorf("%s: key %s is managed by the operator", field, key)
+			}
+		}
+		if typed[key] {
+			return fmt.Errorf("%s: key %s is already set by a spec field", field, key)
+		}
+	}
+	return nil
+}
+
+// renderOVSConfig - renders the config as sorted key=value lines
+func renderOVSConfig(config map[string]string) string {
+	keys := []string{}
+	for key := range config {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	lines := ""
+	for _, key := range keys {
+		lines += key + "=" + config[key] + "\n"
+	}
+	return lines
+}
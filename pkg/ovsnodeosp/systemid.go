@@ -0,0 +1,62 @@
+package ovsnodeosp
+
+import (
+	"fmt"
+	"strings"
+
+	neutronv1 "github.com/openstack-k8s-operators/neutron-operator/api/v1beta1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// SystemID sources supported by OVSNodeOspSpec.SystemIDSource
+const (
+	SystemIDSourceRandom    string = "random"
+	SystemIDSourceMachineID string = "machine-id"
+	SystemIDSourceNodeUID   string = "node-uid"
+)
+
+// GetSystemIDSource - returns the configured system-id source, defaulting to random
+func GetSystemIDSource(cr *neutronv1.OVSNodeOsp) string {
+	if cr.Spec.SystemIDSource == "" {
+		return SystemIDSourceRandom
+	}
+	return cr.Spec.SystemIDSource
+}
+
+// SystemID - derives the OVS system-id of a node from the given source
+func SystemID(node *corev1.Node, source string) (string, error) {
+	switch source {
+	case SystemIDSourceMachineID:
+		// machine-id is 32 lower case hex characters, format it as an UUID
+		machineID := strings.ToLower(strings.TrimSpace(node.Status.NodeInfo.MachineID))
+		if len(machineID) != 32 {
+			return "", fmt.Errorf("node %s has no valid machine-id: %q", node.Name, machineID)
+		}
+		return fmt.Sprintf("%s-%s-%s-%s-%s", machineID[0:8], machineID[8:12], machineID[12:16], machineID[16:20], machineID[20:32]), nil
+	case SystemIDSourceNodeUID:
+		if node.UID == "" {
+			return "", fmt.Errorf("node %s has no uid", node.Name)
+		}
+		return string(node.UID), nil
+	}
+	return "", fmt.Errorf("unsupported system-id source %q", source)
+}
+
+// SystemIDsConfigMap - config map with the system-id of each node, keyed by node name
+func SystemIDsConfigMap(cr *neutronv1.OVSNodeOsp, cmName string, systemIDs map[string]string) *corev1.ConfigMap {
+
+	cm := &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: "v1",
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      cmName,
+			Namespace: cr.Namespace,
+		},
+		Data: systemIDs,
+	}
+
+	return cm
+}
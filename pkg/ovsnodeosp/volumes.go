@@ -1,12 +1,15 @@
 package ovsnodeosp
 
 import (
+	"github.com/openstack-k8s-operators/neutron-operator/pkg/ovsbridge"
 	corev1 "k8s.io/api/core/v1"
 )
 
 // GetVolumes - Volumes used by pod
 func GetVolumes(cmName string) []corev1.Volume {
 	var scriptsVolumeDefaultMode int32 = 0755
+	var configVolumeDefaultMode int32 = 0644
+	var optional = true
 	return []corev1.Volume{
 		{
 			Name: "host-modules",
@@ -59,8 +62,75 @@ func GetVolumes(cmName string) []corev1.Volume {
 				},
 			},
 		},
+		{
+			Name: cmName + "-ovs-config",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					DefaultMode: &configVolumeDefaultMode,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName + "-ovs-config",
+					},
+				},
+			},
+		},
+		{
+			Name: cmName + "-gateways",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					DefaultMode: &configVolumeDefaultMode,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName + "-gateways",
+					},
+				},
+			},
+		},
+		{
+			Name: cmName + "-system-ids",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					DefaultMode: &configVolumeDefaultMode,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: cmName + "-system-ids",
+					},
+					// only created when the system-id is not random
+					Optional: &optional,
+				},
+			},
+		},
+	}
+
+}
+
+// GetOVSBridgesVolumes - Volume of the OVSBridges of the role
+func GetOVSBridgesVolumes(roleName string) []corev1.Volume {
+	var configVolumeDefaultMode int32 = 0644
+	var optional = true
+	return []corev1.Volume{
+		{
+			Name: "ovs-bridges",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					DefaultMode: &configVolumeDefaultMode,
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: ovsbridge.ConfigMapName(roleName),
+					},
+					// only exists when there are OVSBridges for the role
+					Optional: &optional,
+				},
+			},
+		},
 	}
+}
 
+// GetOVSBridgesVolumeMounts - VolumeMounts of the OVSBridges of the role
+func GetOVSBridgesVolumeMounts() []corev1.VolumeMount {
+	return []corev1.VolumeMount{
+		{
+			Name:      "ovs-bridges",
+			ReadOnly:  true,
+			MountPath: "/etc/ovs-node-osp/bridges",
+		},
+	}
 }
 
 // GetVolumeMounts -  VolumeMounts
@@ -93,6 +163,21 @@ func GetVolumeMounts(cmName string) []corev1.VolumeMount {
 			ReadOnly:  true,
 			MountPath: "/usr/local/sbin/",
 		},
+		{
+			Name:      cmName + "-ovs-config",
+			ReadOnly:  true,
+			MountPath: "/etc/ovs-node-osp/ovs-config",
+		},
+		{
+			Name:      cmName + "-gateways",
+			ReadOnly:  true,
+			MountPath: "/etc/ovs-node-osp/gateways",
+		},
+		{
+			Name:      cmName + "-system-ids",
+			ReadOnly:  true,
+			MountPath: "/etc/ovs-node-osp/system-ids",
+		},
 	}
 
 }
@@ -2,51 +2,140 @@ package util
 
 import (
 	"fmt"
-	"regexp"
+	"net"
 	"strings"
 
 	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// Markers of the blocks added by the Ansible blockinfile module
+const (
+	ansibleBlockBegin string = "BEGIN ANSIBLE MANAGED BLOCK"
+	ansibleBlockEnd   string = "END ANSIBLE MANAGED BLOCK"
 )
 
 // CreateOspHostsEntries creates hostAliases from added /etc/hosts file of the OSP environment.
-// All entries inside the ANSIBLE MANAGED BLOCK gets added. Important is that
-// the /etc/hosts file in the config map has opening and closing tag.
-func CreateOspHostsEntries(commonConfigMap *corev1.ConfigMap) ([]corev1.HostAlias, error) {
+// If the file has ANSIBLE MANAGED BLOCKs, only the entries inside the blocks get
+// added, otherwise all entries of the file. Comments are ignored, the hostnames
+// of IPv4 and IPv6 addresses listed more than once get merged. Invalid lines are
+// skipped and returned with their line number, for the caller to report them.
+func CreateOspHostsEntries(commonConfigMap *corev1.ConfigMap) ([]corev1.HostAlias, []string, error) {
+	hostsFile, isset := commonConfigMap.Data["hosts"]
+	if !isset {
+		return nil, nil, fmt.Errorf("No hosts file in %s config map", commonConfigMap.Name)
+	}
+	return ParseHosts(hostsFile)
+}
+
+// ParseHosts parses the entries of a hosts file into hostAliases. Invalid
+// lines are skipped and returned as "line <number>: <error>", only unbalanced
+// managed block markers fail the whole file.
+func ParseHosts(hostsFile string) ([]corev1.HostAlias, []string, error) {
+	lines := strings.Split(hostsFile, "\n")
+	managed, err := getManagedLines(lines)
+	if err != nil {
+		return nil, nil, err
+	}
+
 	hostAliases := []corev1.HostAlias{}
+	aliasIndex := map[string]int{}
+	invalidLines := []string{}
+	for i, line := range lines {
+		if managed != nil && !managed[i] {
+			continue
+		}
+		if comment := strings.Index(line, "#"); comment >= 0 {
+			line = line[:comment]
+		}
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
 
-	hostsFile, isset := commonConfigMap.Data["hosts"]
+		ip, hostnames, err := parseHostsLine(fields)
+		if err != nil {
+			invalidLines = append(invalidLines, fmt.Sprintf("line %d: %v", i+1, err))
+			continue
+		}
 
-	if isset {
-		re := regexp.MustCompile(`(?s).*BEGIN ANSIBLE MANAGED BLOCK\n(.*)# END ANSIBLE MANAGED BLOCK.*`)
-		hostsEntries := re.FindStringSubmatch(hostsFile)
-
-		if len(hostsEntries) >= 1 {
-			for _, hostRecord := range strings.Split(hostsEntries[1], "\n") {
-				if len(hostRecord) > 0 {
-					var ip string
-					var names []string
-
-					for i, r := range strings.Fields(hostRecord) {
-						if i == 0 {
-							ip = r
-						} else {
-							names = append(names, r)
-						}
-					}
-
-					hostAlias := corev1.HostAlias{
-						IP:        ip,
-						Hostnames: names,
-					}
-					hostAliases = append(hostAliases, hostAlias)
-				}
+		index, ok := aliasIndex[ip]
+		if !ok {
+			aliasIndex[ip] = len(hostAliases)
+			hostAliases = append(hostAliases, corev1.HostAlias{IP: ip})
+			index = len(hostAliases) - 1
+		}
+		for _, hostname := range hostnames {
+			if !containsString(hostAliases[index].Hostnames, hostname) {
+				hostAliases[index].Hostnames = append(hostAliases[index].Hostnames, hostname)
 			}
-		} else {
-			return nil, fmt.Errorf("Ansible tags not found in hosts file of common-config map")
 		}
-	} else {
-		return nil, fmt.Errorf("No hosts file in common-config map")
 	}
+	return hostAliases, invalidLines, nil
+}
+
+// getManagedLines returns which lines are inside of an Ansible managed
+// block, nil if the file has no managed blocks
+func getManagedLines(lines []string) ([]bool, error) {
+	var managed []bool
+	begin := -1
+	for i, line := range lines {
+		trimmed := strings.TrimSpace(line)
+		if !strings.HasPrefix(trimmed, "#") {
+			if begin >= 0 {
+				managed[i] = true
+			}
+			continue
+		}
 
-	return hostAliases, nil
+		if strings.Contains(trimmed, ansibleBlockBegin) {
+			if begin >= 0 {
+				return nil, fmt.Errorf("invalid hosts file: line %d: managed block started on line %d is not closed", i+1, begin+1)
+			}
+			if managed == nil {
+				managed = make([]bool, len(lines))
+			}
+			begin = i
+		} else if strings.Contains(trimmed, ansibleBlockEnd) {
+			if begin < 0 {
+				return nil, fmt.Errorf("invalid hosts file: line %d: end of a managed block which was not started", i+1)
+			}
+			begin = -1
+		}
+	}
+	if begin >= 0 {
+		return nil, fmt.Errorf("invalid hosts file: line %d: managed block is not closed", begin+1)
+	}
+	return managed, nil
+}
+
+// parseHostsLine returns the normalized IP and hostnames of a hosts entry
+func parseHostsLine(fields []string) (string, []string, error) {
+	ip := net.ParseIP(fields[0])
+	if ip == nil {
+		return "", nil, fmt.Errorf("invalid IP address %q", fields[0])
+	}
+	if len(fields) < 2 {
+		return "", nil, fmt.Errorf("no hostname for %s", fields[0])
+	}
+
+	hostnames := []string{}
+	for _, hostname := range fields[1:] {
+		// hostnames are case insensitive, the pod spec only takes lower case ones
+		hostname = strings.ToLower(hostname)
+		if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+			return "", nil, fmt.Errorf("invalid hostname %q", hostname)
+		}
+		hostnames = append(hostnames, hostname)
+	}
+	return ip.String(), hostnames, nil
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
 }
@@ -0,0 +1,58 @@
+//go:build go1.18
+// +build go1.18
+
+package util
+
+import (
+	"net"
+	"strings"
+	"testing"
+
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+func FuzzParseHosts(f *testing.F) {
+	f.Add("127.0.0.1 localhost\n::1 localhost6\n")
+	f.Add("# BEGIN ANSIBLE MANAGED BLOCK\n192.168.24.10 controller-0 # comment\n# END ANSIBLE MANAGED BLOCK\n")
+	f.Add("192.168.24.10 a\n192.168.24.10 A b_c\nfe80::1%eth0 d\n")
+	f.Add("# END ANSIBLE MANAGED BLOCK\n# BEGIN ANSIBLE MANAGED BLOCK\n")
+
+	f.Fuzz(func(t *testing.T, hosts string) {
+		aliases, invalid, err := ParseHosts(hosts)
+		if err != nil {
+			if aliases != nil || invalid != nil {
+				t.Fatalf("entries returned with error %v", err)
+			}
+			return
+		}
+
+		lines := strings.Count(hosts, "\n") + 1
+		if len(invalid) > lines {
+			t.Fatalf("%d invalid lines reported for %d lines", len(invalid), lines)
+		}
+		ips := map[string]bool{}
+		for _, alias := range aliases {
+			ip := net.ParseIP(alias.IP)
+			if ip == nil || ip.String() != alias.IP {
+				t.Fatalf("IP %q is not normalized", alias.IP)
+			}
+			if ips[alias.IP] {
+				t.Fatalf("IP %s listed twice", alias.IP)
+			}
+			ips[alias.IP] = true
+			if len(alias.Hostnames) == 0 {
+				t.Fatalf("no hostnames for %s", alias.IP)
+			}
+			hostnames := map[string]bool{}
+			for _, hostname := range alias.Hostnames {
+				if errs := validation.IsDNS1123Subdomain(hostname); len(errs) > 0 {
+					t.Fatalf("invalid hostname %q: %v", hostname, errs)
+				}
+				if hostnames[hostname] {
+					t.Fatalf("hostname %s listed twice for %s", hostname, alias.IP)
+				}
+				hostnames[hostname] = true
+			}
+		}
+	})
+}
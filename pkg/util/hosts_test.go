@@ -0,0 +1,163 @@
+package util
+
+import (
+	"reflect"
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestParseHosts(t *testing.T) {
+	tests := []struct {
+		name    string
+		hosts   string
+		aliases []corev1.HostAlias
+		invalid []string
+		err     bool
+	}{
+		{
+			name:    "empty",
+			hosts:   "",
+			aliases: []corev1.HostAlias{},
+		},
+		{
+			name: "plain file",
+			hosts: "127.0.0.1 localhost localhost.localdomain\n" +
+				"192.168.24.10 controller-0.localdomain controller-0\n" +
+				"\n" +
+				"192.168.24.11\tcompute-0.localdomain   compute-0\n",
+			aliases: []corev1.HostAlias{
+				{IP: "127.0.0.1", Hostnames: []string{"localhost", "localhost.localdomain"}},
+				{IP: "192.168.24.10", Hostnames: []string{"controller-0.localdomain", "controller-0"}},
+				{IP: "192.168.24.11", Hostnames: []string{"compute-0.localdomain", "compute-0"}},
+			},
+		},
+		{
+			name: "managed blocks",
+			hosts: "127.0.0.1 localhost\n" +
+				"# BEGIN ANSIBLE MANAGED BLOCK\n" +
+				"192.168.24.10 controller-0\n" +
+				"# END ANSIBLE MANAGED BLOCK\n" +
+				"10.0.0.1 unmanaged\n" +
+				"  # BEGIN ANSIBLE MANAGED BLOCK overcloud\n" +
+				"192.168.24.11 compute-0\n" +
+				"# END ANSIBLE MANAGED BLOCK overcloud\n",
+			aliases: []corev1.HostAlias{
+				{IP: "192.168.24.10", Hostnames: []string{"controller-0"}},
+				{IP: "192.168.24.11", Hostnames: []string{"compute-0"}},
+			},
+		},
+		{
+			name: "invalid lines outside of managed blocks",
+			hosts: "not an entry\n" +
+				"# BEGIN ANSIBLE MANAGED BLOCK\n" +
+				"192.168.24.10 controller-0\n" +
+				"# END ANSIBLE MANAGED BLOCK\n",
+			aliases: []corev1.HostAlias{{IP: "192.168.24.10", Hostnames: []string{"controller-0"}}},
+		},
+		{
+			name:  "block not closed",
+			hosts: "# BEGIN ANSIBLE MANAGED BLOCK\n192.168.24.10 controller-0\n",
+			err:   true,
+		},
+		{
+			name:  "block not started",
+			hosts: "192.168.24.10 controller-0\n# END ANSIBLE MANAGED BLOCK\n",
+			err:   true,
+		},
+		{
+			name: "nested blocks",
+			hosts: "# BEGIN ANSIBLE MANAGED BLOCK\n" +
+				"# BEGIN ANSIBLE MANAGED BLOCK\n" +
+				"# END ANSIBLE MANAGED BLOCK\n" +
+				"# END ANSIBLE MANAGED BLOCK\n",
+			err: true,
+		},
+		{
+			name: "comments",
+			hosts: "# a comment\n" +
+				"192.168.24.10 controller-0 # controller-1\n" +
+				"   # 192.168.24.11 compute-0\n" +
+				"192.168.24.12 compute-1#inline\n",
+			aliases: []corev1.HostAlias{
+				{IP: "192.168.24.10", Hostnames: []string{"controller-0"}},
+				{IP: "192.168.24.12", Hostnames: []string{"compute-1"}},
+			},
+		},
+		{
+			name: "IPv6",
+			hosts: "::1 localhost6\n" +
+				"fd00:fd00:fd00:2000::10 controller-0.internalapi\n" +
+				"FD00:FD00:FD00:2000:0:0:0:10 controller-0.internalapi.localdomain\n",
+			aliases: []corev1.HostAlias{
+				{IP: "::1", Hostnames: []string{"localhost6"}},
+				{IP: "fd00:fd00:fd00:2000::10", Hostnames: []string{"controller-0.internalapi", "controller-0.internalapi.localdomain"}},
+			},
+		},
+		{
+			name: "merged duplicate IPs",
+			hosts: "192.168.24.10 controller-0\n" +
+				"192.168.24.11 compute-0\n" +
+				"192.168.24.10 Controller-0 controller-0.localdomain\n",
+			aliases: []corev1.HostAlias{
+				{IP: "192.168.24.10", Hostnames: []string{"controller-0", "controller-0.localdomain"}},
+				{IP: "192.168.24.11", Hostnames: []string{"compute-0"}},
+			},
+		},
+		{
+			name: "invalid lines",
+			hosts: "192.168.24.10 controller-0\n" +
+				"192.168.24.300 compute-0\n" +
+				"192.168.24.12\n" +
+				"192.168.24.13 compute_2\n" +
+				"fe80::1%eth0 link-local\n" +
+				"192.168.24.14 compute-3\n",
+			aliases: []corev1.HostAlias{
+				{IP: "192.168.24.10", Hostnames: []string{"controller-0"}},
+				{IP: "192.168.24.14", Hostnames: []string{"compute-3"}},
+			},
+			invalid: []string{
+				`line 2: invalid IP address "192.168.24.300"`,
+				"line 3: no hostname for 192.168.24.12",
+				`line 4: invalid hostname "compute_2"`,
+				`line 5: invalid IP address "fe80::1%eth0"`,
+			},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			aliases, invalid, err := ParseHosts(test.hosts)
+			if test.err {
+				if err == nil {
+					t.Fatal("expected an error")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if !reflect.DeepEqual(aliases, test.aliases) {
+				t.Errorf("aliases: got %+v, want %+v", aliases, test.aliases)
+			}
+			if len(invalid) != len(test.invalid) || (len(invalid) > 0 && !reflect.DeepEqual(invalid, test.invalid)) {
+				t.Errorf("invalid lines: got %q, want %q", invalid, test.invalid)
+			}
+		})
+	}
+}
+
+func TestCreateOspHostsEntries(t *testing.T) {
+	configMap := &corev1.ConfigMap{Data: map[string]string{"hosts": "192.168.24.10 controller-0\n"}}
+	aliases, _, err := CreateOspHostsEntries(configMap)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(aliases) != 1 {
+		t.Errorf("got %+v", aliases)
+	}
+
+	if _, _, err := CreateOspHostsEntries(&corev1.ConfigMap{}); err == nil {
+		t.Error("expected an error for a ConfigMap without hosts file")
+	}
+}
@@ -0,0 +1,39 @@
+#!/bin/bash
+# Publishes node information to the operator in the report ConfigMap of the
+# instance, as <pod name>_<key> entries. Needs POD_NAME, POD_NAMESPACE,
+# REPORT_CONFIGMAP and a service account allowed to patch that ConfigMap.
+
+# json_escape <value> - escapes the value for a JSON string
+function json_escape {
+    local VALUE="$1"
+    VALUE="${VALUE//\\/\\\\}"
+    VALUE="${VALUE//\"/\\\"}"
+    VALUE="${VALUE//$'\n'/\\n}"
+    VALUE="${VALUE//$'\r'/\\r}"
+    VALUE="${VALUE//$'\t'/\\t}"
+    # drop the remaining control characters
+    printf '%s' "${VALUE}" | tr -d '\000-\037'
+}
+
+# report_patch <key> <json value> - sets the key of this pod, null removes it.
+# Failures are logged, the caller keeps running.
+function report_patch {
+    local SA_DIR=/var/run/secrets/kubernetes.io/serviceaccount
+    if ! curl -sS -f -o /dev/null --cacert ${SA_DIR}/ca.crt \
+        -H "Authorization: Bearer $(cat ${SA_DIR}/token)" \
+        -H "Content-Type: application/merge-patch+json" \
+        -X PATCH "https://${KUBERNETES_SERVICE_HOST}:${KUBERNETES_SERVICE_PORT}/api/v1/namespaces/${POD_NAMESPACE}/configmaps/${REPORT_CONFIGMAP}" \
+        -d "{\"data\":{\"${POD_NAME}_$1\":$2}}"; then
+        echo "ERROR: failed to report $1 to ConfigMap ${REPORT_CONFIGMAP}" >&2
+    fi
+}
+
+# report <key> <value>
+function report {
+    report_patch "$1" "\"$(json_escape "$2")\""
+}
+
+# report_remove <key>
+function report_remove {
+    report_patch "$1" null
+}
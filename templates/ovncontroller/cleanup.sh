@@ -0,0 +1,25 @@
+#!/bin/bash
+# Removes the chassis of the node from the SB DB, run by the cleanup job of
+# the node when the OVNController gets deleted.
+set -e
+if [[ -f "/env/${K8S_NODE}" ]]; then
+  set -o allexport
+  source "/env/${K8S_NODE}"
+  set +o allexport
+fi
+
+# chassis name used as suffix of the ovn external-ids, e.g. ${NODE_NAME}-osp,
+# the job might not run on the node so it gets the node name passed
+CHASSIS_NAME=${CHASSIS_NAME_PATTERN//\{hostname\}/${NODE_NAME}}
+
+if [[ -z "${OVN_SB_REMOTE}" ]]; then
+    echo "No SB DB connection, leaving chassis ${CHASSIS_NAME} in place"
+    exit 0
+fi
+ovn-sbctl --db=${OVN_SB_REMOTE} --if-exists chassis-del ${CHASSIS_NAME}
+
+# ovn-controller does not remove the ports it added to the integration bridge,
+# ovsdb-server might be gone already though
+if ${CLEANUP_HOST:-true} && [[ -S /var/run/openvswitch/db.sock ]]; then
+    ovs-vsctl --timeout=10 --if-exists del-br ${INTEGRATION_BRIDGE} || true
+fi
@@ -5,6 +5,7 @@ if [[ -f "/env/${K8S_NODE}" ]]; then
   source "/env/${K8S_NODE}"
   set +o allexport
 fi
+source /usr/local/sbin/report.sh
 # Determine the ovn rundir.
 if [[ -f /usr/bin/ovn-appctl ]] ; then
     # ovn-appctl is present. Use new ovn run dir path.
@@ -14,6 +15,74 @@ else
     OVNCTL_DIR=openvswitch
 fi
 
-exec ovn-controller -n ${HOSTNAME}-osp unix:/var/run/openvswitch/db.sock -vfile:off \
+# chassis name used as suffix of the ovn external-ids, e.g. ${HOSTNAME}-osp
+CHASSIS_NAME=${CHASSIS_NAME_PATTERN//\{hostname\}/${HOSTNAME}}
+
+# tunnel_bfd_state - prints the BFD state of the geneve interface to the
+# remote IP, nothing if there is no such interface or BFD is not enabled on it
+function tunnel_bfd_state {
+    local REMOTE_IP=$1
+    for IFACE in $(ovs-vsctl --bare --columns=name find Interface type=geneve); do
+        if [[ "$(ovs-vsctl --if-exists get Interface "${IFACE}" options:remote_ip | tr -d '"')" == "${REMOTE_IP}" ]]; then
+            ovs-vsctl --if-exists get Interface "${IFACE}" bfd_status:state | tr -d '"'
+            return
+        fi
+    done
+}
+
+# check_tunnels - pings the encap IPs of the other nodes of the role from the
+# local encap IP and reads the BFD state of the geneve tunnels to them, reports
+# the unreachable nodes and the nodes with BFD down when the result changes.
+# The ping does not see a block of the Geneve UDP port 6081, BFD does but
+# ovn-controller only enables it on the tunnels to the gateway chassis.
+function check_tunnels {
+    local PEERS=0
+    local UNREACHABLE=()
+    local BFD_DOWN=()
+    for PEER_FILE in /etc/ovn-controller/tunnel-peers/*; do
+        [[ -f "${PEER_FILE}" ]] || continue
+        local PEER=$(basename "${PEER_FILE}")
+        [[ "${PEER}" == "${HOSTNAME}" ]] && continue
+        local PEER_IP=$(cat "${PEER_FILE}")
+        PEERS=$((PEERS + 1))
+        if ! ping -q -c 3 -i 0.2 -W 1 -I "${ENCAP_IP}" "${PEER_IP}" > /dev/null 2>&1; then
+            UNREACHABLE+=("${PEER}")
+        fi
+        if [[ "$(tunnel_bfd_state "${PEER_IP}")" == "down" ]]; then
+            BFD_DOWN+=("${PEER}")
+        fi
+    done
+    local RESULT="${PEERS} ${UNREACHABLE[*]} / ${BFD_DOWN[*]}"
+    if [[ "${RESULT}" != "${TUNNEL_RESULT-unset}" ]]; then
+        report tunnel-peers "${PEERS}"
+        report tunnel-unreachable "${UNREACHABLE[*]}"
+        report tunnel-bfd-down "${BFD_DOWN[*]}"
+        TUNNEL_RESULT=${RESULT}
+    fi
+}
+
+# node information shown in the status of the OVNController, the encap ip
+# is set by the OVSNodeOsp pod which might not be done yet
+report chassis "${CHASSIS_NAME}"
+report ovn-version "$(ovn-controller --version | awk 'NR==1{print $NF}')"
+(
+    for i in $(seq 1 30); do
+        ENCAP_IP=$(ovs-vsctl --if-exists get open . external_ids:ovn-encap-ip-${CHASSIS_NAME} | tr -d '"')
+        [[ -n "${ENCAP_IP}" ]] && break
+        sleep 10
+    done
+    report system-id "$(ovs-vsctl --if-exists get open . external_ids:system-id | tr -d '"')"
+    report encap-ip "${ENCAP_IP}"
+    report ovs-version "$(ovs-vsctl --if-exists get open . ovs_version | tr -d '"')"
+
+    if [[ "${TUNNEL_CHECK_INTERVAL:-0}" -gt 0 && -n "${ENCAP_IP}" ]]; then
+        while true; do
+            check_tunnels || echo "Failed to check the tunnels"
+            sleep ${TUNNEL_CHECK_INTERVAL}
+        done
+    fi
+) &
+
+exec ovn-controller -n ${CHASSIS_NAME} unix:/var/run/openvswitch/db.sock -vfile:off \
   --no-chdir --pidfile=/var/run/${OVNCTL_DIR}/ovn-controller.pid \
   -vconsole:"${OVN_LOG_LEVEL}"
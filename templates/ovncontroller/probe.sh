@@ -0,0 +1,33 @@
+#!/bin/bash
+# Probes of the ovn-controller container.
+#   probe.sh readiness - ovn-controller is connected to the SB DB and the
+#                        integration bridge exists
+#   probe.sh liveness  - ovn-controller answers. A SB DB outage does not fail
+#                        it, restarting ovn-controller would not help and drop
+#                        the flows. Neither does the local ovsdb, which is
+#                        unreachable while the OVS pod restarts.
+#   probe.sh startup   - same as liveness, with a longer threshold for the
+#                        initial flow programming
+if [[ -f /usr/bin/ovn-appctl ]] ; then
+    APPCTL=ovn-appctl
+else
+    APPCTL=ovs-appctl
+fi
+
+STATUS=$(${APPCTL} -t ovn-controller connection-status 2>&1)
+if [[ $? -ne 0 ]]; then
+    echo "ovn-controller does not answer: ${STATUS}"
+    exit 1
+fi
+[[ "$1" != "readiness" ]] && exit 0
+
+# ovs-vsctl blocks without a timeout while the local ovsdb is down
+if ! ovs-vsctl --timeout=3 br-exists "${INTEGRATION_BRIDGE}"; then
+    echo "integration bridge ${INTEGRATION_BRIDGE} does not exist or the local ovsdb does not answer"
+    exit 1
+fi
+if [[ "${STATUS}" != "connected" ]]; then
+    echo "ovn-controller is ${STATUS} to the SB DB"
+    exit 1
+fi
+exit 0
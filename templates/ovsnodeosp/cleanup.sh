@@ -0,0 +1,74 @@
+#!/bin/bash
+# Undoes the node setup of ovsnode.sh, run by the cleanup job of the node
+# when the OVSNodeOsp gets deleted.
+set -e
+if [[ -f "/env/${K8S_NODE}" ]]; then
+  set -o allexport
+  source "/env/${K8S_NODE}"
+  set +o allexport
+fi
+
+# ovsdb-server went away together with the ovs pod, run it for the cleanup
+STOP_OVS=false
+if ! /usr/share/openvswitch/scripts/ovs-ctl status >/dev/null 2>&1; then
+    /usr/share/openvswitch/scripts/ovs-ctl start --no-ovs-vswitchd --ovs-user=openvswitch:openvswitch
+    STOP_OVS=true
+fi
+
+# chassis name used as suffix of the ovn external-ids, e.g. ${NODE_NAME}-osp,
+# has to match the one of ovsnode.sh which uses the node name as well
+CHASSIS_NAME=${CHASSIS_NAME_PATTERN//\{hostname\}/${NODE_NAME}}
+
+# move the address back from the provider bridge to the NIC, the NIC might be
+# a member of one of the bonds
+OVN_OSP_BRIDGE=`echo ${BRIDGE_MAPPINGS} | cut -d":" -f2`
+if [[ -n "${OVN_OSP_BRIDGE}" ]] && ovs-vsctl br-exists ${OVN_OSP_BRIDGE}; then
+    ADDRESSES=`ip -4 -o addr show "${OVN_OSP_BRIDGE}" | awk 'BEGIN{FS="inet "}{print $2}' | cut -d" " -f1`
+    for BOND in $(ovs-vsctl --bare --columns=name find port external_ids:ovs-node-osp-managed=bond); do
+        ovs-vsctl --if-exists del-port ${BOND}
+    done
+    ovs-vsctl --if-exists del-port ${OVN_OSP_BRIDGE} ${NIC}
+    ovs-vsctl --if-exists del-br ${OVN_OSP_BRIDGE}
+    for ADDRESS in ${ADDRESSES}; do
+        ip addr add ${ADDRESS} dev ${NIC} || true
+    done
+    ip link set ${NIC} up
+fi
+# bonds of the other provider bridges
+for BOND in $(ovs-vsctl --bare --columns=name find port external_ids:ovs-node-osp-managed=bond); do
+    ovs-vsctl --if-exists del-port ${BOND}
+done
+# bridges created for OVSBridges, and their ports on other bridges
+for BRIDGE in $(ovs-vsctl list-br); do
+    if [[ -n "$(ovs-vsctl --if-exists get bridge ${BRIDGE} external_ids:ovs-node-osp-ovsbridge)" ]]; then
+        ovs-vsctl --if-exists del-br ${BRIDGE}
+    fi
+done
+for PORT in $(ovs-vsctl --bare --columns=name list port); do
+    if [[ -n "$(ovs-vsctl --if-exists get port ${PORT} external_ids:ovs-node-osp-ovsbridge)" ]]; then
+        ovs-vsctl --if-exists del-port ${PORT}
+    fi
+done
+ovs-vsctl --if-exists del-br ${INTEGRATION_BRIDGE}
+
+# external_ids of the chassis, including the ones applied from the spec
+for KEY in $(ovs-vsctl --bare get open . external_ids | tr -d '{}' | tr ',' '\n' | cut -d= -f1 | tr -d ' "'); do
+    if [[ "${KEY}" == *"-${CHASSIS_NAME}" ]]; then
+        ovs-vsctl --if-exists remove open . external_ids ${KEY}
+    fi
+done
+# other_config applied from the spec, DPDK and hardware offload
+for KEY in $(ovs-vsctl --if-exists get open . external_ids:ovs-node-osp-managed-other-config | tr -d '"') \
+    dpdk-init dpdk-socket-mem dpdk-lcore-mask pmd-cpu-mask vhost-sock-dir hw-offload; do
+    ovs-vsctl --if-exists remove open . other_config ${KEY}
+done
+ovs-vsctl --if-exists remove open . external_ids ovs-node-osp-managed-other-config
+
+# the chassis is not removed from the SB DB by ovn-controller on its own
+if [[ -n "${OVN_SB_REMOTE}" ]] && command -v ovn-sbctl >/dev/null; then
+    ovn-sbctl --db=${OVN_SB_REMOTE} --if-exists chassis-del ${CHASSIS_NAME}
+fi
+
+if ${STOP_OVS}; then
+    /usr/share/openvswitch/scripts/ovs-ctl stop
+fi
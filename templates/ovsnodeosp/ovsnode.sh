@@ -5,6 +5,7 @@ if [[ -f "/env/${K8S_NODE}" ]]; then
   source "/env/${K8S_NODE}"
   set +o allexport
 fi
+source /usr/local/sbin/report.sh
 chown -R openvswitch:openvswitch /run/openvswitch
 chown -R openvswitch:openvswitch /etc/openvswitch
 function quit {
@@ -14,37 +15,471 @@ function quit {
     exit 0
 }
 trap quit SIGTERM
-/usr/share/openvswitch/scripts/ovs-ctl start --ovs-user=openvswitch:openvswitch --system-id=random
+
+# apply_config <column> <file> <key suffix>
+# Sets the key=value lines of the file in the Open_vSwitch column and removes
+# the keys applied by a previous run which are no longer in the file. Keys
+# which already have the value are left alone, so it is safe to re-run.
+function apply_config {
+    local COLUMN=$1
+    local FILE=$2
+    local SUFFIX=$3
+    local MANAGED_KEY=ovs-node-osp-managed-${COLUMN//_/-}${SUFFIX}
+    local PREVIOUS=$(ovs-vsctl --if-exists get open . external_ids:${MANAGED_KEY} | tr -d '"')
+    local CURRENT=""
+    local KEY VALUE
+    while IFS='=' read -r KEY VALUE; do
+        [[ -z "${KEY}" ]] && continue
+        if [[ "$(ovs-vsctl --if-exists get open . ${COLUMN}:${KEY}${SUFFIX} | tr -d '"')" != "${VALUE}" ]]; then
+            echo "Setting ${COLUMN}:${KEY}${SUFFIX}=${VALUE}"
+            ovs-vsctl set open . ${COLUMN}:${KEY}${SUFFIX}="\"${VALUE}\""
+        fi
+        CURRENT="${CURRENT} ${KEY}${SUFFIX}"
+    done < ${FILE}
+    for KEY in ${PREVIOUS}; do
+        if [[ " ${CURRENT} " != *" ${KEY} "* ]]; then
+            echo "Removing ${COLUMN}:${KEY}, it is no longer in the spec"
+            ovs-vsctl --if-exists remove open . ${COLUMN} ${KEY}
+        fi
+    done
+    if [[ "${PREVIOUS}" != "${CURRENT# }" ]]; then
+        ovs-vsctl set open . external_ids:${MANAGED_KEY}="\"${CURRENT# }\""
+    fi
+}
+
+# apply_ovs_config - applies the additional external_ids and other_config of
+# the spec, the ConfigMap is updated in place without restarting the pod
+function apply_ovs_config {
+    apply_config external_ids /etc/ovs-node-osp/ovs-config/external_ids -${CHASSIS_NAME}
+    apply_config other_config /etc/ovs-node-osp/ovs-config/other_config
+}
+
+# Use a system-id derived from the node identity, unless it is random. The
+# per node entries are keyed by the node name, which can differ from the
+# kernel hostname.
+SYSTEM_ID=random
+if [[ "${SYSTEM_ID_SOURCE:-random}" != "random" ]]; then
+    SYSTEM_ID_FILE=/etc/ovs-node-osp/system-ids/${NODE_NAME}
+    # the config map entry for a new node can take a while to show up
+    for i in $(seq 1 60); do
+        [[ -s ${SYSTEM_ID_FILE} ]] && break
+        sleep 2
+    done
+    if [[ ! -s ${SYSTEM_ID_FILE} ]]; then
+        echo "No system-id found for ${NODE_NAME} in ${SYSTEM_ID_FILE}"
+        exit 1
+    fi
+    SYSTEM_ID=$(cat ${SYSTEM_ID_FILE})
+    if [[ -s /etc/openvswitch/system-id.conf ]]; then
+        PREVIOUS_SYSTEM_ID=$(cat /etc/openvswitch/system-id.conf)
+        if [[ "${PREVIOUS_SYSTEM_ID}" != "${SYSTEM_ID}" ]]; then
+            # the operator records it in the status and raises an event
+            echo "WARNING: system-id of ${NODE_NAME} changed from ${PREVIOUS_SYSTEM_ID} to ${SYSTEM_ID}"
+            report previous-system-id "${PREVIOUS_SYSTEM_ID}"
+        fi
+    fi
+    # persist it, ovs-ctl only does that for random ids
+    echo "${SYSTEM_ID}" > /etc/openvswitch/system-id.conf
+fi
+/usr/share/openvswitch/scripts/ovs-ctl start --no-ovs-vswitchd --ovs-user=openvswitch:openvswitch --system-id=${SYSTEM_ID}
+
+# set_other_config <key> <value> - sets the other_config key, removes it if
+# the value is empty
+function set_other_config {
+    if [[ -n "$2" ]]; then
+        ovs-vsctl set open . other_config:$1="$2"
+    else
+        ovs-vsctl --if-exists remove open . other_config $1
+    fi
+}
+
+# DPDK has to be configured before ovs-vswitchd starts, disabling it removes
+# all of the DPDK settings
+DPDK_INIT=true
+if ! ${DPDK}; then
+    DPDK_INIT=""
+    DPDK_SOCKET_MEM=""
+    DPDK_LCORE_MASK=""
+    PMD_CPU_MASK=""
+    VHOST_SOCK_DIR=""
+fi
+set_other_config dpdk-init "${DPDK_INIT}"
+set_other_config dpdk-socket-mem "${DPDK_SOCKET_MEM}"
+set_other_config dpdk-lcore-mask "${DPDK_LCORE_MASK}"
+set_other_config pmd-cpu-mask "${PMD_CPU_MASK}"
+set_other_config vhost-sock-dir "${VHOST_SOCK_DIR}"
+if ${HW_OFFLOAD}; then
+    ovs-vsctl set open . other_config:hw-offload=true
+else
+    ovs-vsctl --if-exists remove open . other_config hw-offload
+fi
+/usr/share/openvswitch/scripts/ovs-ctl start --no-ovsdb-server --ovs-user=openvswitch:openvswitch
 ovs-appctl vlog/set "file:${OVS_LOG_LEVEL}"
 /usr/share/openvswitch/scripts/ovs-ctl --protocol=udp --dport=6081 enable-protocol
 
 sleep 5
-export OVN_NODE_IP_MASK=`ip -4 -o addr show "${NIC}" | awk 'BEGIN{FS="inet "}{print $2}' | cut -d" " -f1`
-export OVN_NODE_IP=`ip -4 -o addr show "${NIC}" | awk 'BEGIN{FS="inet "}{print $2}' | cut -d" " -f1 | cut -d"/" -f1`
+# once the NIC, or the bond it is a member of, got moved to the provider
+# bridge its address is on the bridge
+NIC_ADDRESS_DEV=$(ovs-vsctl iface-to-br "${NIC}" 2>/dev/null || echo "${NIC}")
+export OVN_NODE_IP_MASK=`ip -4 -o addr show "${NIC_ADDRESS_DEV}" | awk 'BEGIN{FS="inet "}{print $2}' | cut -d" " -f1`
+export OVN_NODE_IP=`ip -4 -o addr show "${NIC_ADDRESS_DEV}" | awk 'BEGIN{FS="inet "}{print $2}' | cut -d" " -f1 | cut -d"/" -f1`
 export OVN_NODE_MAC=`ip -o link show "${NIC}" | awk 'BEGIN{FS="link/ether "}{print $2}' | cut -d " " -f1`
 
-ovs-vsctl set open . external-ids:ovn-bridge-${HOSTNAME}-osp=br-int-osp
-ovs-vsctl set open . external-ids:ovn-remote-${HOSTNAME}-osp=${OVN_SB_REMOTE}
-ovs-vsctl set open . external-ids:ovn-encap-type-${HOSTNAME}-osp=geneve
-ovs-vsctl set open . external-ids:ovn-encap-ip-${HOSTNAME}-osp="${OVN_NODE_IP}"
-ovs-vsctl set open . external_ids:hostname-${HOSTNAME}-osp="${HOSTNAME}"
+# chassis name used as suffix of the ovn external-ids, e.g. ${NODE_NAME}-osp,
+# has to match the one of ovn-controller which uses the node name as well
+CHASSIS_NAME=${CHASSIS_NAME_PATTERN//\{hostname\}/${NODE_NAME}}
+
+ovs-vsctl set open . external-ids:ovn-bridge-${CHASSIS_NAME}=${INTEGRATION_BRIDGE}
+ovs-vsctl set open . external-ids:ovn-remote-${CHASSIS_NAME}=${OVN_SB_REMOTE}
+ovs-vsctl set open . external-ids:ovn-encap-type-${CHASSIS_NAME}=geneve
+ovs-vsctl set open . external-ids:ovn-encap-ip-${CHASSIS_NAME}="${OVN_NODE_IP}"
+ovs-vsctl set open . external_ids:hostname-${CHASSIS_NAME}="${NODE_NAME}"
+
+# node information shown in the status of the OVSNodeOsp
+report chassis "${CHASSIS_NAME}"
+report system-id "$(ovs-vsctl get open . external_ids:system-id | tr -d '"')"
+report encap-ip "${OVN_NODE_IP}"
+report ovs-version "$(ovs-vsctl get open . ovs_version | tr -d '"')"
+
+# additional external_ids and other_config from the spec, re-applied below
+apply_ovs_config
+
+if ${DPDK}; then
+    # ovn-controller has to create the integration bridge in userspace too
+    ovs-vsctl set open . external-ids:ovn-bridge-datapath-type-${CHASSIS_NAME}=netdev
+
+    # DPDK ports are passed as <bridge>/<name>=<pci address>
+    for DPDK_PORT in ${DPDK_PORTS}; do
+        DPDK_BRIDGE=${DPDK_PORT%%/*}
+        DPDK_PORT_NAME=${DPDK_PORT#*/}
+        DPDK_PORT_NAME=${DPDK_PORT_NAME%%=*}
+        DPDK_PCI_ADDRESS=${DPDK_PORT#*=}
+        ovs-vsctl --may-exist add-br ${DPDK_BRIDGE} -- set bridge ${DPDK_BRIDGE} datapath_type=netdev
+        ovs-vsctl --may-exist add-port ${DPDK_BRIDGE} ${DPDK_PORT_NAME} -- \
+            set Interface ${DPDK_PORT_NAME} type=dpdk options:dpdk-devargs=${DPDK_PCI_ADDRESS}
+    done
+else
+    ovs-vsctl --if-exists remove open . external_ids ovn-bridge-datapath-type-${CHASSIS_NAME}
+    # DPDK ports do not work without dpdk-init
+    for DPDK_PORT_NAME in $(ovs-vsctl --bare --columns=name find interface type=dpdk); do
+        ovs-vsctl --if-exists del-port ${DPDK_PORT_NAME}
+    done
+fi
+
+if ${HW_OFFLOAD}; then
+    # switch the PFs to switchdev and plug their VF representors
+    HW_OFFLOAD_STATUS=enabled
+    ovs-vsctl --may-exist add-br ${INTEGRATION_BRIDGE}
+    for PF in ${HW_OFFLOAD_PFS}; do
+        if [[ ! -e /sys/class/net/${PF}/device ]]; then
+            HW_OFFLOAD_STATUS="unsupported: ${PF} not found"
+            continue
+        fi
+        PF_PCI=$(basename $(readlink -f /sys/class/net/${PF}/device))
+        if ! devlink dev eswitch show pci/${PF_PCI} | grep -q "mode switchdev"; then
+            if ! devlink dev eswitch set pci/${PF_PCI} mode switchdev; then
+                HW_OFFLOAD_STATUS="unsupported: ${PF} can not be switched to switchdev"
+                continue
+            fi
+        fi
+        ethtool -K ${PF} hw-tc-offload on || true
+        PF_SWITCH_ID=$(cat /sys/class/net/${PF}/phys_switch_id 2>/dev/null || true)
+        if [[ -z "${PF_SWITCH_ID}" ]]; then
+            HW_OFFLOAD_STATUS="unsupported: ${PF} has no switch id"
+            continue
+        fi
+        for REP_DIR in /sys/class/net/*; do
+            REP=$(basename ${REP_DIR})
+            [[ "$(cat ${REP_DIR}/phys_switch_id 2>/dev/null)" == "${PF_SWITCH_ID}" ]] || continue
+            [[ "$(cat ${REP_DIR}/phys_port_name 2>/dev/null)" =~ ^pf[0-9]+vf[0-9]+$ ]] || continue
+            ovs-vsctl --may-exist add-port ${INTEGRATION_BRIDGE} ${REP}
+            ip link set ${REP} up
+        done
+    done
+    report hw-offload "${HW_OFFLOAD_STATUS}"
+else
+    report hw-offload disabled
+fi
 
-if ${GATEWAY}; then
-    # mark it as gateway
-    ovs-vsctl set open . external_ids:ovn-cms-options-${HOSTNAME}-osp=enable-chassis-as-gw
-    ovs-vsctl set open . external-ids:ovn-bridge-mappings-${HOSTNAME}-osp=${BRIDGE_MAPPINGS}
+# setup_gateway_bridge - moves the NIC and its address to the provider bridge
+function setup_gateway_bridge {
+    ovs-vsctl set open . external-ids:ovn-bridge-mappings-${CHASSIS_NAME}=${BRIDGE_MAPPINGS}
 
     # enable br-ex
     export OVN_OSP_BRIDGE=`echo ${BRIDGE_MAPPINGS} | cut -d":" -f2`
+    if ip -4 -o addr show dev ${OVN_OSP_BRIDGE} 2>/dev/null | grep -q " ${OVN_NODE_IP_MASK} "; then
+        # already done by a previous run
+        return
+    fi
     ovs-vsctl --may-exist add-br ${OVN_OSP_BRIDGE}
     ip link set address ${OVN_NODE_MAC} dev ${OVN_OSP_BRIDGE}
-    ovs-vsctl --may-exist add-port ${OVN_OSP_BRIDGE} ${NIC}
+    # a NIC which is a member of a bond of the bridge is attached already
+    if [[ "$(ovs-vsctl iface-to-br ${NIC} 2>/dev/null || true)" != "${OVN_OSP_BRIDGE}" ]]; then
+        ovs-vsctl --may-exist add-port ${OVN_OSP_BRIDGE} ${NIC}
+    fi
     ip link set ${OVN_OSP_BRIDGE} down
     ip addr add ${OVN_NODE_IP_MASK} dev ${OVN_OSP_BRIDGE}
     ip link set ${OVN_OSP_BRIDGE} up
     ip link set ${NIC} down
     ip link set ${NIC} up
-fi
+}
+
+# teardown_gateway_bridge - undoes setup_gateway_bridge when the node is no
+# longer a gateway, moves the NIC and its address back from the provider bridge
+function teardown_gateway_bridge {
+    local OVN_OSP_BRIDGE ADDRESSES ADDRESS
+    OVN_OSP_BRIDGE=$(ovs-vsctl --if-exists get open . external_ids:ovn-bridge-mappings-${CHASSIS_NAME} | tr -d '"' | cut -d":" -f2)
+    if [[ -z "${OVN_OSP_BRIDGE}" ]]; then
+        # never set up, or already undone by a previous run
+        return
+    fi
+    ovs-vsctl --if-exists remove open . external_ids ovn-bridge-mappings-${CHASSIS_NAME}
+    if [[ "$(ovs-vsctl iface-to-br ${NIC} 2>/dev/null || true)" == "${OVN_OSP_BRIDGE}" ]]; then
+        ADDRESSES=`ip -4 -o addr show "${OVN_OSP_BRIDGE}" | awk 'BEGIN{FS="inet "}{print $2}' | cut -d" " -f1`
+        ovs-vsctl --if-exists del-port ${OVN_OSP_BRIDGE} ${NIC}
+        for ADDRESS in ${ADDRESSES}; do
+            ip addr del ${ADDRESS} dev ${OVN_OSP_BRIDGE} || true
+            ip addr add ${ADDRESS} dev ${NIC} || true
+        done
+        ip link set ${NIC} up
+    fi
+    # the bridge stays while other ports use it
+    if [[ -z "$(ovs-vsctl --if-exists list-ports ${OVN_OSP_BRIDGE})" ]]; then
+        ovs-vsctl --if-exists del-br ${OVN_OSP_BRIDGE}
+    fi
+}
+
+# apply_bonds - creates the bonds of the provider bridges, keeps their
+# members and mode in sync and reports the member link states. The bonds
+# carry the ovs-node-osp-managed=bond marker, marked bonds which are no
+# longer in the spec get removed together with their reports.
+function apply_bonds {
+    local BRIDGE BOND MODE LACP MEMBERS MEMBER CURRENT_MEMBERS STATES
+    local CURRENT=""
+    while read -r BRIDGE BOND MODE LACP MEMBERS; do
+        [[ -z "${BOND}" ]] && continue
+        CURRENT="${CURRENT} ${BOND}"
+        ovs-vsctl --may-exist add-br ${BRIDGE}
+        if ! ovs-vsctl list-ports ${BRIDGE} | grep -qx "${BOND}"; then
+            ovs-vsctl --if-exists del-port ${BOND} -- add-bond ${BRIDGE} ${BOND} ${MEMBERS//,/ }
+        fi
+        ovs-vsctl set port ${BOND} bond_mode=${MODE} lacp=${LACP} external_ids:ovs-node-osp-managed=bond
+        # add missing and remove stale members
+        CURRENT_MEMBERS=""
+        for MEMBER in $(ovs-vsctl --bare get port ${BOND} interfaces | tr -d '[],'); do
+            CURRENT_MEMBERS="${CURRENT_MEMBERS},$(ovs-vsctl --bare get interface ${MEMBER} name)"
+        done
+        for MEMBER in ${MEMBERS//,/ }; do
+            if [[ "${CURRENT_MEMBERS}," != *",${MEMBER},"* ]]; then
+                ovs-vsctl -- --id=@iface create interface name=${MEMBER} \
+                    -- add port ${BOND} interfaces @iface
+            fi
+        done
+        for MEMBER in ${CURRENT_MEMBERS//,/ }; do
+            if [[ ",${MEMBERS}," != *",${MEMBER},"* ]]; then
+                ovs-vsctl -- --id=@iface get interface ${MEMBER} \
+                    -- remove port ${BOND} interfaces @iface
+            fi
+        done
+        # ovs-appctl prints "member <iface>: enabled" or "slave <iface>: enabled"
+        STATES=$(ovs-appctl bond/show ${BOND} | awk '/^(member|slave) /{gsub(":", "", $2); print $2 ":" $3}' | paste -sd, -)
+        if [[ "${STATES}" != "${BOND_STATES[${BOND}]}" ]]; then
+            report bond-${BOND} "${STATES}"
+            BOND_STATES[${BOND}]=${STATES}
+        fi
+    done < /etc/ovs-node-osp/ovs-config/bonds
+
+    for BOND in $(ovs-vsctl --bare --columns=name find port external_ids:ovs-node-osp-managed=bond); do
+        if [[ " ${CURRENT} " != *" ${BOND} "* ]]; then
+            echo "Removing bond ${BOND}, it is no longer in the spec"
+            ovs-vsctl --if-exists del-port ${BOND}
+            report_remove bond-${BOND}
+            unset BOND_STATES[${BOND}]
+        fi
+    done
+}
+
+# is_foreign_port <port> - checks if the port was added by ovn-controller or
+# for the gateway and bonds of the OVSNodeOsp, these are not drift
+function is_foreign_port {
+    [[ "$1" == "${NIC}" ]] && return 0
+    ovs-vsctl --bare get port $1 external_ids | grep -qE '(^|[ {,])"?(ovn-|ovs-node-osp-managed)'
+}
+
+# remove_ovs_bridge <bridge> - removes the ports the OVSBridge added to the
+# bridge, and the bridge once no other port uses it
+function remove_ovs_bridge {
+    local BRIDGE=$1 PORT
+    for PORT in $(ovs-vsctl list-ports ${BRIDGE}); do
+        if [[ -n "$(ovs-vsctl --if-exists get port ${PORT} external_ids:ovs-node-osp-ovsbridge)" ]]; then
+            ovs-vsctl --if-exists del-port ${BRIDGE} ${PORT}
+        fi
+    done
+    if [[ -z "$(ovs-vsctl list-ports ${BRIDGE})" ]]; then
+        ovs-vsctl --if-exists del-br ${BRIDGE}
+    else
+        ovs-vsctl --if-exists remove bridge ${BRIDGE} external_ids ovs-node-osp-ovsbridge
+    fi
+}
+
+# apply_ovs_bridges - creates the bridges of the OVSBridges of the role and
+# reports, per OVSBridge, whether the bridge was changed out of band. Bridges
+# and ports carry the ovs-node-osp-ovsbridge=<OVSBridge> marker, marked ones
+# which are no longer declared get removed. Other ports on a bridge are
+# reported as drift but kept.
+function apply_ovs_bridges {
+    local FILE NAME CHECKSUM DRIFT KIND BRIDGE FAIL_MODE PORT TYPE TAG PEER ADDRESSES ADDRESS CURRENT STATE PORTS OWNER
+    local BRIDGES=""
+    for FILE in /etc/ovs-node-osp/bridges/*; do
+        [[ -f "${FILE}" ]] || continue
+        NAME=$(basename ${FILE})
+        CHECKSUM=$(md5sum ${FILE} | cut -d" " -f1)
+        DRIFT=""
+        PORTS=""
+        while read -r KIND PORT TYPE TAG PEER ADDRESSES; do
+            case ${KIND} in
+            bridge)
+                # bridge <name> <fail mode>
+                BRIDGE=${PORT}
+                BRIDGES="${BRIDGES} ${BRIDGE}"
+                FAIL_MODE=${TYPE/#-/}
+                if ! ovs-vsctl br-exists ${BRIDGE}; then
+                    DRIFT="${DRIFT} bridge ${BRIDGE} missing;"
+                    ovs-vsctl --may-exist add-br ${BRIDGE}
+                fi
+                ovs-vsctl set bridge ${BRIDGE} external_ids:ovs-node-osp-ovsbridge=${NAME}
+                CURRENT=$(ovs-vsctl --bare get bridge ${BRIDGE} fail_mode)
+                if [[ "${CURRENT}" != "${FAIL_MODE}" ]]; then
+                    DRIFT="${DRIFT} ${BRIDGE} fail_mode ${CURRENT:-unset};"
+                    if [[ -n "${FAIL_MODE}" ]]; then
+                        ovs-vsctl set-fail-mode ${BRIDGE} ${FAIL_MODE}
+                    else
+                        ovs-vsctl del-fail-mode ${BRIDGE}
+                    fi
+                fi
+                ;;
+            port)
+                # port <name> <type> <vlan tag> <peer> <addresses>
+                PORTS="${PORTS} ${PORT}"
+                if [[ "$(ovs-vsctl port-to-br ${PORT} 2>/dev/null || true)" != "${BRIDGE}" ]]; then
+                    DRIFT="${DRIFT} port ${PORT} missing;"
+                    ovs-vsctl --if-exists del-port ${PORT} -- add-port ${BRIDGE} ${PORT}
+                fi
+                ovs-vsctl set port ${PORT} external_ids:ovs-node-osp-ovsbridge=${NAME}
+                [[ "${TYPE}" == "system" ]] && TYPE=""
+                CURRENT=$(ovs-vsctl --bare get interface ${PORT} type)
+                if [[ "${CURRENT}" != "${TYPE}" ]]; then
+                    DRIFT="${DRIFT} ${PORT} type ${CURRENT:-system};"
+                    ovs-vsctl set interface ${PORT} type=${TYPE:-\"\"}
+                fi
+                if [[ "${PEER}" != "-" ]]; then
+                    CURRENT=$(ovs-vsctl --bare --if-exists get interface ${PORT} options:peer)
+                    if [[ "${CURRENT}" != "${PEER}" ]]; then
+                        DRIFT="${DRIFT} ${PORT} peer ${CURRENT:-unset};"
+                        ovs-vsctl set interface ${PORT} options:peer=${PEER}
+                    fi
+                fi
+                TAG=${TAG/#-/}
+                CURRENT=$(ovs-vsctl --bare get port ${PORT} tag)
+                if [[ "${CURRENT}" != "${TAG}" ]]; then
+                    DRIFT="${DRIFT} ${PORT} tag ${CURRENT:-unset};"
+                    if [[ -n "${TAG}" ]]; then
+                        ovs-vsctl set port ${PORT} tag=${TAG}
+                    else
+                        ovs-vsctl clear port ${PORT} tag
+                    fi
+                fi
+                if [[ "${ADDRESSES}" != "-" ]]; then
+                    ip link set ${PORT} up
+                    for ADDRESS in ${ADDRESSES//,/ }; do
+                        if ! ip -o addr show dev ${PORT} | grep -q " ${ADDRESS} "; then
+                            DRIFT="${DRIFT} ${PORT} address ${ADDRESS} missing;"
+                            ip addr add ${ADDRESS} dev ${PORT}
+                        fi
+                    done
+                fi
+                ;;
+            esac
+        done < ${FILE}
+
+        # ports removed from the OVSBridge get deleted, unknown ones are drift
+        for PORT in $(ovs-vsctl list-ports ${BRIDGE}); do
+            [[ " ${PORTS} " == *" ${PORT} "* ]] && continue
+            OWNER=$(ovs-vsctl --if-exists get port ${PORT} external_ids:ovs-node-osp-ovsbridge | tr -d '"')
+            if [[ "${OWNER}" == "${NAME}" ]]; then
+                ovs-vsctl --if-exists del-port ${BRIDGE} ${PORT}
+            elif ! is_foreign_port ${PORT}; then
+                DRIFT="${DRIFT} port ${PORT} not declared;"
+            fi
+        done
+
+        # differences after a spec change are not drift
+        if [[ "${OVS_BRIDGES_CHECKSUMS[${NAME}]}" != "${CHECKSUM}" ]]; then
+            OVS_BRIDGES_CHECKSUMS[${NAME}]=${CHECKSUM}
+            DRIFT=""
+        fi
+        STATE=in-sync
+        if [[ -n "${DRIFT}" ]]; then
+            echo "Out of band changes of ${NAME}:${DRIFT}"
+            STATE="drift:${DRIFT}"
+        fi
+        if [[ "${STATE}" != "${OVS_BRIDGES_STATES[${NAME}]}" ]]; then
+            report ovsbridge-${NAME} "${STATE}"
+            OVS_BRIDGES_STATES[${NAME}]=${STATE}
+        fi
+    done
+
+    # bridges of deleted OVSBridges, or renamed ones
+    for BRIDGE in $(ovs-vsctl list-br); do
+        [[ " ${BRIDGES} " == *" ${BRIDGE} "* ]] && continue
+        OWNER=$(ovs-vsctl --if-exists get bridge ${BRIDGE} external_ids:ovs-node-osp-ovsbridge | tr -d '"')
+        [[ -z "${OWNER}" ]] && continue
+        echo "Removing bridge ${BRIDGE} of ${OWNER}, it is no longer declared"
+        remove_ovs_bridge ${BRIDGE}
+        if [[ ! -f /etc/ovs-node-osp/bridges/${OWNER} ]]; then
+            report_remove ovsbridge-${OWNER}
+            unset OVS_BRIDGES_CHECKSUMS[${OWNER}] OVS_BRIDGES_STATES[${OWNER}]
+        fi
+    done
+}
+
+# apply_gateway - applies the ovn-cms-options of this node, the operator
+# updates them when the node gets in or out of gateway duty. The entries are
+# keyed by the node name, which can differ from the kernel hostname.
+function apply_gateway {
+    local CMS_OPTIONS=$(cat /etc/ovs-node-osp/gateways/${NODE_NAME} 2>/dev/null || true)
+    if [[ "${CMS_OPTIONS}" == "${APPLIED_CMS_OPTIONS-unset}" ]]; then
+        return
+    fi
+    echo "Applying ovn-cms-options: ${CMS_OPTIONS}"
+    if [[ -n "${CMS_OPTIONS}" ]]; then
+        ovs-vsctl set open . external_ids:ovn-cms-options-${CHASSIS_NAME}="\"${CMS_OPTIONS}\""
+    else
+        ovs-vsctl --if-exists remove open . external_ids ovn-cms-options-${CHASSIS_NAME}
+    fi
+    if [[ ",${CMS_OPTIONS}," == *",enable-chassis-as-gw,"* ]]; then
+        setup_gateway_bridge
+    else
+        teardown_gateway_bridge
+    fi
+    APPLIED_CMS_OPTIONS=${CMS_OPTIONS}
+}
+
+declare -A BOND_STATES
+declare -A OVS_BRIDGES_CHECKSUMS
+declare -A OVS_BRIDGES_STATES
+apply_bonds
+apply_ovs_bridges
+apply_gateway
+(
+    while kill -0 $(cat /var/run/openvswitch/ovs-vswitchd.pid) 2>/dev/null; do
+        sleep 10
+        apply_ovs_config || echo "Failed to apply external_ids and other_config"
+        apply_bonds || echo "Failed to apply bonds"
+        apply_ovs_bridges || echo "Failed to apply bridges"
+        apply_gateway || echo "Failed to apply ovn-cms-options"
+    done
+) &
 
 tail -F --pid=$(cat /var/run/openvswitch/ovs-vswitchd.pid) /var/log/openvswitch/ovs-vswitchd.log &
 tail -F --pid=$(cat /var/run/openvswitch/ovsdb-server.pid) /var/log/openvswitch/ovsdb-server.log &
@@ -213,6 +213,13 @@ Install and configure OpenStack Neutron containers.
 						DisplayName: "OVS Node OSP",
 						Description: "OVSNodeOsp is the Schema for the ovsnodeosps API",
 					},
+					{
+						Name:        "ovsbridges.neutron.openstack.org",
+						Version:     "v1beta1",
+						Kind:        "OVSBridge",
+						DisplayName: "OVS Bridge",
+						Description: "OVSBridge is the Schema for the ovsbridges API",
+					},
 				},
 			},
 		},
@@ -239,6 +246,19 @@ func getOperatorRules() *[]rbacv1.PolicyRule {
 				"*",
 			},
 		},
+		{
+			APIGroups: []string{
+				"",
+			},
+			Resources: []string{
+				"nodes",
+			},
+			Verbs: []string{
+				"get",
+				"list",
+				"watch",
+			},
+		},
 		{
 			APIGroups: []string{
 				"apps",
@@ -268,6 +288,38 @@ func getOperatorRules() *[]rbacv1.PolicyRule {
 				"update",
 			},
 		},
+		{
+			APIGroups: []string{
+				"batch",
+			},
+			Resources: []string{
+				"jobs",
+			},
+			Verbs: []string{
+				"get",
+				"list",
+				"watch",
+				"create",
+				"update",
+				"delete",
+			},
+		},
+		{
+			APIGroups: []string{
+				"rbac.authorization.k8s.io",
+			},
+			Resources: []string{
+				"roles",
+				"rolebindings",
+			},
+			Verbs: []string{
+				"get",
+				"list",
+				"create",
+				"update",
+				"delete",
+			},
+		},
 		{
 			APIGroups: []string{
 				"monitoring.coreos.com",
@@ -303,6 +355,7 @@ func getOperatorRules() *[]rbacv1.PolicyRule {
 				"neutronsriovagents",
 				"ovsnodeosps",
 				"ovncontrollers",
+				"ovsbridges",
 			},
 			Verbs: []string{
 				"*",